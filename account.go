@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"strconv"
+	"time"
 )
 
 // AccountService 账户/资产接口服务
@@ -361,10 +362,10 @@ func (a *AccountService) GetPositionLimit(symbol string) (*PositionLimitInfo, er
 // GetFinancialRecord 获取财务记录
 // symbol: 保证金币种
 // recordType: 记录类型，可选
-// startTime: 开始时间戳，可选
-// endTime: 结束时间戳，可选
+// startTime: 开始时间，零值表示不限，按毫秒发送
+// endTime: 结束时间，零值表示不限，按毫秒发送
 // size: 条数，可选，默认20，最大50
-func (a *AccountService) GetFinancialRecord(symbol string, recordType int, startTime, endTime int64, size int) ([]FinancialRecord, error) {
+func (a *AccountService) GetFinancialRecord(symbol string, recordType int, startTime, endTime time.Time, size int) ([]FinancialRecord, error) {
 	if symbol == "" {
 		return nil, fmt.Errorf("symbol is required")
 	}
@@ -375,11 +376,11 @@ func (a *AccountService) GetFinancialRecord(symbol string, recordType int, start
 	if recordType > 0 {
 		params["type"] = strconv.Itoa(recordType)
 	}
-	if startTime > 0 {
-		params["start_time"] = strconv.FormatInt(startTime, 10)
+	if !startTime.IsZero() {
+		params["start_time"] = unixMilliParam(startTime)
 	}
-	if endTime > 0 {
-		params["end_time"] = strconv.FormatInt(endTime, 10)
+	if !endTime.IsZero() {
+		params["end_time"] = unixMilliParam(endTime)
 	}
 	if size > 0 {
 		params["size"] = strconv.Itoa(size)
@@ -42,33 +42,33 @@ type AccountInfo struct {
 
 // PositionDetail 持仓详情
 type PositionDetail struct {
-	Symbol         string `json:"symbol"`          // 交易对
-	ContractCode   string `json:"contract_code"`   // 合约代码
-	ContractType   string `json:"contract_type"`   // 合约类型
-	Volume         string `json:"volume"`          // 持仓数量
-	Available      string `json:"available"`       // 可平仓数量
-	Frozen         string `json:"frozen"`          // 冻结数量
-	CostOpen       string `json:"cost_open"`       // 开仓均价
-	CostHold       string `json:"cost_hold"`       // 持仓均价
-	ProfitUnreal   string `json:"profit_unreal"`   // 未实现盈亏
-	ProfitRate     string `json:"profit_rate"`     // 收益率
-	Profit         string `json:"profit"`          // 收益
-	MarginPosition string `json:"margin_position"` // 持仓保证金
-	PositionMargin string `json:"position_margin"` // 持仓保证金
-	Direction      string `json:"direction"`       // 仓位方向 buy-买 sell-卖
-	LastPrice      string `json:"last_price"`      // 最新价
-	LeverRate      int    `json:"lever_rate"`      // 杠杆倍数
+	Symbol         string    `json:"symbol"`          // 交易对
+	ContractCode   string    `json:"contract_code"`   // 合约代码
+	ContractType   string    `json:"contract_type"`   // 合约类型
+	Volume         string    `json:"volume"`          // 持仓数量
+	Available      string    `json:"available"`       // 可平仓数量
+	Frozen         string    `json:"frozen"`          // 冻结数量
+	CostOpen       string    `json:"cost_open"`       // 开仓均价
+	CostHold       string    `json:"cost_hold"`       // 持仓均价
+	ProfitUnreal   string    `json:"profit_unreal"`   // 未实现盈亏
+	ProfitRate     string    `json:"profit_rate"`     // 收益率
+	Profit         string    `json:"profit"`          // 收益
+	MarginPosition string    `json:"margin_position"` // 持仓保证金
+	PositionMargin string    `json:"position_margin"` // 持仓保证金
+	Direction      OrderSide `json:"direction"`       // 仓位方向 buy-买 sell-卖
+	LastPrice      string    `json:"last_price"`      // 最新价
+	LeverRate      int       `json:"lever_rate"`      // 杠杆倍数
 }
 
 // TransferRecord 划转记录
 type TransferRecord struct {
-	OrderID    string `json:"order_id"`    // 订单ID
-	Currency   string `json:"currency"`    // 币种
-	Amount     string `json:"amount"`      // 划转数量
-	Type       string `json:"type"`        // 类型 pro-to-futures futures-to-pro
-	Status     string `json:"status"`      // 状态
-	CreateTime int64  `json:"create_time"` // 创建时间
-	UpdateTime int64  `json:"update_time"` // 更新时间
+	OrderID    string    `json:"order_id"`    // 订单ID
+	Currency   string    `json:"currency"`    // 币种
+	Amount     string    `json:"amount"`      // 划转数量
+	Type       string    `json:"type"`        // 类型 pro-to-futures futures-to-pro
+	Status     string    `json:"status"`      // 状态
+	CreateTime Timestamp `json:"create_time"` // 创建时间
+	UpdateTime Timestamp `json:"update_time"` // 更新时间
 }
 
 // FeeRate 费率信息
@@ -91,12 +91,12 @@ type TransferLimit struct {
 
 // FinancialRecord 财务记录
 type FinancialRecord struct {
-	ID           int64  `json:"id"`            // 记录ID
-	Type         int    `json:"type"`          // 记录类型
-	Amount       string `json:"amount"`        // 金额
-	Ts           int64  `json:"ts"`            // 时间戳
-	Symbol       string `json:"symbol"`        // 交易对
-	ContractCode string `json:"contract_code"` // 合约代码
+	ID           int64     `json:"id"`            // 记录ID
+	Type         int       `json:"type"`          // 记录类型
+	Amount       string    `json:"amount"`        // 金额
+	Ts           Timestamp `json:"ts"`            // 时间戳
+	Symbol       string    `json:"symbol"`        // 交易对
+	ContractCode string    `json:"contract_code"` // 合约代码
 }
 
 // LeverageInfo 杠杆信息
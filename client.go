@@ -4,11 +4,12 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
-
+	"sync/atomic"
 	"time"
 )
 
@@ -17,6 +18,7 @@ type Client struct {
 	config     *Config
 	httpClient *http.Client
 	signature  *Signature
+	registry   atomic.Pointer[ContractRegistry]
 
 	// API服务
 	Market    *MarketService
@@ -55,7 +57,7 @@ func NewClientWithConfig(config *Config) *Client {
 	client.Trading = &TradingService{client: client}
 	client.Position = &PositionService{client: client}
 	client.Common = &CommonService{client: client}
-	client.WebSocket = &WebSocketService{client: client}
+	client.WebSocket = NewWebSocketService(client)
 
 	return client
 }
@@ -122,14 +124,14 @@ func (c *Client) doRequest(ctx context.Context, method, path string, params map[
 	// 发送请求
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("send request: %w", err)
+		return nil, fmt.Errorf("send request: %w", &outcomeUnknownError{err: err})
 	}
 	defer resp.Body.Close()
 
 	// 读取响应
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("read response: %w", err)
+		return nil, fmt.Errorf("read response: %w", &outcomeUnknownError{err: err})
 	}
 
 	if c.config.Debug {
@@ -140,20 +142,49 @@ func (c *Client) doRequest(ctx context.Context, method, path string, params map[
 	// 解析响应
 	var response Response
 	if err := json.Unmarshal(respBody, &response); err != nil {
-		return nil, fmt.Errorf("unmarshal response: %w", err)
+		return nil, fmt.Errorf("unmarshal response: %w", &outcomeUnknownError{err: err})
 	}
 
-	// 检查业务错误
+	// 检查业务错误，服务端或网关错误时请求可能已被处理
 	if response.Code != 200 {
-		return nil, &ErrorResponse{
+		apiErr := &ErrorResponse{
 			Code: response.Code,
 			Msg:  response.Msg,
 		}
+		if isServerError(resp.StatusCode) || isServerError(response.Code) {
+			return nil, &outcomeUnknownError{err: apiErr}
+		}
+		return nil, apiErr
 	}
 
 	return &response, nil
 }
 
+// outcomeUnknownError 请求已尝试发出但未得到可解析的响应，服务端可能已经处理了该请求
+type outcomeUnknownError struct {
+	err error
+}
+
+func (e *outcomeUnknownError) Error() string {
+	return e.err.Error()
+}
+
+func (e *outcomeUnknownError) Unwrap() error {
+	return e.err
+}
+
+// isServerError 是否为5xx服务端或网关错误码
+func isServerError(code int) bool {
+	return code >= 500 && code < 600
+}
+
+// IsOutcomeUnknown 判断请求结果是否未知，如超时、连接中断、响应无法解析或服务端返回5xx错误；
+// 此类错误发生时下单请求可能已被交易所接受，不能直接重试
+func IsOutcomeUnknown(err error) bool {
+	var target *outcomeUnknownError
+	return errors.As(err, &target)
+}
+
 // get 发送GET请求
 func (c *Client) get(ctx context.Context, path string, params map[string]string, needAuth bool) (*Response, error) {
 	return c.doRequest(ctx, "GET", path, params, nil, needAuth)
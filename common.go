@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"strconv"
+	"time"
 )
 
 // CommonService 通用接口服务
@@ -78,12 +79,17 @@ func (c *CommonService) GetSystemStatus(symbol string) ([]SystemStatus, error) {
 // GetContractElements 获取合约要素
 // symbol: 交易对，可选
 func (c *CommonService) GetContractElements(symbol string) ([]ContractElement, error) {
+	return c.getContractElements(context.Background(), symbol)
+}
+
+// getContractElements 获取合约要素，请求随ctx取消
+func (c *CommonService) getContractElements(ctx context.Context, symbol string) ([]ContractElement, error) {
 	params := make(map[string]string)
 	if symbol != "" {
 		params["symbol"] = symbol
 	}
 
-	resp, err := c.client.get(context.Background(), "/api/v1/perpetual/public/query-elements", params, false)
+	resp, err := c.client.get(ctx, "/api/v1/perpetual/public/query-elements", params, false)
 	if err != nil {
 		return nil, err
 	}
@@ -423,11 +429,11 @@ func (c *CommonService) MasterSubTransfer(subUID int64, symbol, amount, transfer
 // GetMasterSubTransferRecord 获取母子账户划转记录
 // symbol: 币种
 // transferType: 划转类型，可选
-// startTime: 开始时间，可选
-// endTime: 结束时间，可选
+// startTime: 开始时间，零值表示不限，按毫秒发送
+// endTime: 结束时间，零值表示不限，按毫秒发送
 // from: 查询起始ID，可选
 // size: 查询条数，可选，默认20，最大50
-func (c *CommonService) GetMasterSubTransferRecord(symbol, transferType string, startTime, endTime int64, from, size int) ([]MasterSubTransferRecord, error) {
+func (c *CommonService) GetMasterSubTransferRecord(symbol, transferType string, startTime, endTime time.Time, from, size int) ([]MasterSubTransferRecord, error) {
 	if symbol == "" {
 		return nil, fmt.Errorf("symbol is required")
 	}
@@ -438,11 +444,11 @@ func (c *CommonService) GetMasterSubTransferRecord(symbol, transferType string,
 	if transferType != "" {
 		params["type"] = transferType
 	}
-	if startTime > 0 {
-		params["start_time"] = strconv.FormatInt(startTime, 10)
+	if !startTime.IsZero() {
+		params["start_time"] = unixMilliParam(startTime)
 	}
-	if endTime > 0 {
-		params["end_time"] = strconv.FormatInt(endTime, 10)
+	if !endTime.IsZero() {
+		params["end_time"] = unixMilliParam(endTime)
 	}
 	if from > 0 {
 		params["from"] = strconv.Itoa(from)
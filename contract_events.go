@@ -0,0 +1,147 @@
+package hotcoin
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// ErrContractSuspended 合约当前不可交易
+var ErrContractSuspended = errors.New("contract suspended")
+
+// DefaultContractTradable 默认可交易判断：交易状态为1（正常），且合约要素状态为1（已上线）或未加载合约要素
+func DefaultContractTradable(info ContractInfo) bool {
+	if info.Contract.TradeStatus != 1 {
+		return false
+	}
+	return info.Element == nil || info.Element.ContractStatus == 1
+}
+
+// DiffContracts 比较两个合约快照，返回上线、下线、状态、杠杆和参数变化事件，按合约代码排序
+func DiffContracts(previous, current []ContractInfo) []ContractEvent {
+	oldByCode := make(map[string]*ContractInfo, len(previous))
+	for i := range previous {
+		oldByCode[previous[i].Code] = &previous[i]
+	}
+	newByCode := make(map[string]*ContractInfo, len(current))
+	for i := range current {
+		newByCode[current[i].Code] = &current[i]
+	}
+
+	var events []ContractEvent
+	for code, after := range newByCode {
+		before, ok := oldByCode[code]
+		if !ok {
+			events = append(events, ContractEvent{Type: ContractListed, Code: code, New: after})
+			continue
+		}
+		events = append(events, diffContract(before, after)...)
+	}
+	for code, before := range oldByCode {
+		if _, ok := newByCode[code]; !ok {
+			events = append(events, ContractEvent{Type: ContractDelisted, Code: code, Old: before})
+		}
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		if events[i].Code != events[j].Code {
+			return events[i].Code < events[j].Code
+		}
+		return events[i].Type < events[j].Type
+	})
+	return events
+}
+
+// diffContract 比较同一合约的两个版本
+func diffContract(before, after *ContractInfo) []ContractEvent {
+	var events []ContractEvent
+	event := func(eventType ContractEventType, changes []ContractFieldChange) {
+		if len(changes) > 0 {
+			events = append(events, ContractEvent{Type: eventType, Code: after.Code, Old: before, New: after, Changes: changes})
+		}
+	}
+
+	var status []ContractFieldChange
+	status = appendIntChange(status, "trade_status", before.Contract.TradeStatus, after.Contract.TradeStatus)
+	if before.Element != nil && after.Element != nil {
+		status = appendIntChange(status, "contract_status", before.Element.ContractStatus, after.Element.ContractStatus)
+	}
+	event(ContractStatusChanged, status)
+
+	event(ContractLeverChanged, appendIntChange(nil, "max_lever", before.MaxLever, after.MaxLever))
+
+	var params []ContractFieldChange
+	params = appendDecimalChange(params, "price_tick", before.PriceTick, after.PriceTick)
+	params = appendIntChange(params, "price_digits", before.PriceDigits, after.PriceDigits)
+	params = appendIntChange(params, "amount_digits", before.AmountDigits, after.AmountDigits)
+	params = appendDecimalChange(params, "min_trade_unit", before.MinTradeUnit, after.MinTradeUnit)
+	params = appendDecimalChange(params, "unit_amount", before.UnitAmount, after.UnitAmount)
+	if before.Element != nil && after.Element != nil {
+		params = appendDecimalChange(params, "contract_size", before.ContractSize, after.ContractSize)
+		params = appendStringChange(params, "delivery_time", before.Element.DeliveryTime.String(), after.Element.DeliveryTime.String())
+		params = appendStringChange(params, "settlement_time", before.Element.SettlementTime.String(), after.Element.SettlementTime.String())
+	}
+	event(ContractParamsChanged, params)
+
+	return events
+}
+
+// appendIntChange 整数字段不同时记录变化
+func appendIntChange(changes []ContractFieldChange, field string, from, to int) []ContractFieldChange {
+	if from == to {
+		return changes
+	}
+	return append(changes, ContractFieldChange{Field: field, Old: strconv.Itoa(from), New: strconv.Itoa(to)})
+}
+
+// appendDecimalChange 十进制字段数值不同时记录变化
+func appendDecimalChange(changes []ContractFieldChange, field string, from, to Decimal) []ContractFieldChange {
+	if from.Equal(to) {
+		return changes
+	}
+	return append(changes, ContractFieldChange{Field: field, Old: from.String(), New: to.String()})
+}
+
+// appendStringChange 字符串字段不同时记录变化
+func appendStringChange(changes []ContractFieldChange, field string, from, to string) []ContractFieldChange {
+	if from == to {
+		return changes
+	}
+	return append(changes, ContractFieldChange{Field: field, Old: from, New: to})
+}
+
+// OnEvent 设置合约变更事件回调，首次加载不产生事件，之后每次刷新与上一次快照比较
+func (r *ContractRegistry) OnEvent(handler ContractEventHandler) {
+	r.mutex.Lock()
+	r.onEvent = handler
+	r.mutex.Unlock()
+}
+
+// Tradable 合约是否可交易，未配置判断函数时总是可交易
+func (r *ContractRegistry) Tradable(symbol string) (bool, error) {
+	info, ok := r.Lookup(symbol)
+	if !ok {
+		return false, fmt.Errorf("%w: %q", ErrUnknownSymbol, symbol)
+	}
+	if r.config.Tradable == nil {
+		return true, nil
+	}
+	return r.config.Tradable(info), nil
+}
+
+// checkTradable 启用注册表时校验交易对存在且可交易
+func (c *Client) checkTradable(symbol string) error {
+	registry := c.registry.Load()
+	if registry == nil || !registry.Loaded() {
+		return nil
+	}
+	tradable, err := registry.Tradable(symbol)
+	if err != nil {
+		return err
+	}
+	if !tradable {
+		return fmt.Errorf("%w: %q", ErrContractSuspended, symbol)
+	}
+	return nil
+}
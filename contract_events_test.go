@@ -0,0 +1,86 @@
+package hotcoin
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDiffContracts(t *testing.T) {
+	before := testContracts()
+	before[0].TradeStatus = 1
+	before[1].TradeStatus = 1
+
+	after := testContracts()
+	after[0].TradeStatus = 2
+	after[0].MaxLever = 75
+	after[0].MarketPriceDigit = 2
+	after = append(after[:1], Contract{Code: "solusdt", Base: "sol", Quote: "usdt", TradeStatus: 1})
+
+	registry := NewContractRegistry(nil, nil)
+	var events []ContractEvent
+	registry.OnEvent(func(event ContractEvent) {
+		events = append(events, event)
+	})
+	registry.Load(before, nil)
+	if len(events) != 0 {
+		t.Fatalf("first load should not emit events, got %d", len(events))
+	}
+	registry.Load(after, nil)
+
+	want := []struct {
+		eventType ContractEventType
+		code      string
+	}{
+		{ContractStatusChanged, "btcusdt"},
+		{ContractLeverChanged, "btcusdt"},
+		{ContractParamsChanged, "btcusdt"},
+		{ContractDelisted, "ethusdt"},
+		{ContractListed, "solusdt"},
+	}
+	if len(events) != len(want) {
+		t.Fatalf("got %d events, want %d: %+v", len(events), len(want), events)
+	}
+	for i, w := range want {
+		if events[i].Type != w.eventType || events[i].Code != w.code || events[i].Time.IsZero() {
+			t.Errorf("event %d: got %s %s, want %s %s", i, events[i].Type, events[i].Code, w.eventType, w.code)
+		}
+	}
+
+	status := events[0].Changes
+	if len(status) != 1 || status[0].Field != "trade_status" || status[0].Old != "1" || status[0].New != "2" {
+		t.Errorf("unexpected status changes: %+v", status)
+	}
+	params := events[2].Changes
+	if len(params) != 2 || params[0].Field != "price_tick" || params[0].Old != "0.1" || params[0].New != "0.01" {
+		t.Errorf("unexpected param changes: %+v", params)
+	}
+	if events[3].Old == nil || events[3].New != nil || events[4].New == nil || events[4].Old != nil {
+		t.Error("unexpected listed/delisted snapshots")
+	}
+}
+
+func TestClientRejectsSuspendedContract(t *testing.T) {
+	contracts := testContracts()
+	contracts[0].TradeStatus = 1
+	contracts[1].TradeStatus = 2
+
+	client := NewClient("", "")
+	registry := NewContractRegistry(client, nil)
+	registry.Load(contracts, []ContractElement{{ContractCode: "btcusdt", ContractStatus: 1}})
+	client.SetContractRegistry(registry)
+
+	if ok, err := registry.Tradable("BTC-USDT"); err != nil || !ok {
+		t.Errorf("BTC-USDT tradable = %v, %v", ok, err)
+	}
+	req := &OrderPlaceRequest{Symbol: "ETH-USDT", Direction: OrderSideBuy, Volume: "1", Price: "2000"}
+	if _, err := client.Trading.PlaceOrder(req); !errors.Is(err, ErrContractSuspended) {
+		t.Errorf("expected ErrContractSuspended, got %v", err)
+	}
+
+	// 自定义判断函数
+	registry = NewContractRegistry(client, &ContractRegistryConfig{})
+	registry.Load(contracts, nil)
+	if ok, _ := registry.Tradable("ETH-USDT"); !ok {
+		t.Error("nil Tradable should allow all contracts")
+	}
+}
@@ -0,0 +1,295 @@
+package hotcoin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrUnknownSymbol 交易对不在合约注册表中
+var ErrUnknownSymbol = errors.New("unknown symbol")
+
+// symbolReplacer 去除交易对中的分隔符
+var symbolReplacer = strings.NewReplacer("-", "", "/", "", "_", "", ":", "", " ", "")
+
+// NormalizeSymbol 将任意写法的交易对转换为合约代码格式，如BTC-USDT、BTC/USDT、btc_usdt均转换为btcusdt
+func NormalizeSymbol(symbol string) string {
+	return strings.ToLower(symbolReplacer.Replace(strings.TrimSpace(symbol)))
+}
+
+// ContractRegistry 合约注册表
+// 由GetContracts和GetContractElements构建，缓存合约元数据并定时刷新；
+// 通过Client.SetContractRegistry启用后，各服务在发起请求前拒绝未知交易对
+type ContractRegistry struct {
+	config    ContractRegistryConfig
+	contracts func(ctx context.Context) ([]Contract, error)
+	elements  func(ctx context.Context) ([]ContractElement, error)
+
+	mutex     sync.RWMutex
+	byCode    map[string]*ContractInfo
+	updatedAt time.Time
+	onError   func(err error)
+	onEvent   ContractEventHandler
+
+	startOnce sync.Once
+	stopOnce  sync.Once
+	stopChan  chan struct{}
+}
+
+// NewContractRegistry 创建合约注册表，需调用Refresh或Start加载合约
+func NewContractRegistry(client *Client, config *ContractRegistryConfig) *ContractRegistry {
+	if config == nil {
+		config = DefaultContractRegistryConfig()
+	}
+
+	r := &ContractRegistry{
+		config:   *config,
+		byCode:   make(map[string]*ContractInfo),
+		stopChan: make(chan struct{}),
+	}
+	if client != nil {
+		r.contracts = func(ctx context.Context) ([]Contract, error) {
+			return client.Market.getContracts(ctx, "")
+		}
+		r.elements = func(ctx context.Context) ([]ContractElement, error) {
+			return client.Common.getContractElements(ctx, "")
+		}
+	}
+	return r
+}
+
+// OnError 设置定时刷新失败的回调，刷新失败时保留上一次的合约数据
+func (r *ContractRegistry) OnError(handler func(err error)) {
+	r.mutex.Lock()
+	r.onError = handler
+	r.mutex.Unlock()
+}
+
+// Start 加载合约并按配置的间隔定时刷新
+func (r *ContractRegistry) Start(ctx context.Context) error {
+	if err := r.Refresh(ctx); err != nil {
+		return err
+	}
+	if r.config.RefreshInterval > 0 {
+		r.startOnce.Do(func() {
+			go r.run(ctx)
+		})
+	}
+	return nil
+}
+
+// Stop 停止定时刷新
+func (r *ContractRegistry) Stop() {
+	r.stopOnce.Do(func() {
+		close(r.stopChan)
+	})
+}
+
+// run 定时刷新循环
+func (r *ContractRegistry) run(ctx context.Context) {
+	ticker := time.NewTicker(r.config.RefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-r.stopChan:
+			return
+		case <-ticker.C:
+			if err := r.Refresh(ctx); err != nil {
+				r.mutex.RLock()
+				handler := r.onError
+				r.mutex.RUnlock()
+				if handler != nil {
+					handler(err)
+				}
+			}
+		}
+	}
+}
+
+// Refresh 重新加载合约和合约要素，请求随ctx取消，失败时保留原有数据
+func (r *ContractRegistry) Refresh(ctx context.Context) error {
+	if r.contracts == nil {
+		return fmt.Errorf("client is required")
+	}
+
+	contracts, err := r.contracts(ctx)
+	if err != nil {
+		return fmt.Errorf("load contracts: %w", err)
+	}
+	if len(contracts) == 0 {
+		return fmt.Errorf("load contracts: no contracts returned")
+	}
+
+	var elements []ContractElement
+	if r.config.LoadElements && r.elements != nil {
+		elements, err = r.elements(ctx)
+		if err != nil {
+			return fmt.Errorf("load contract elements: %w", err)
+		}
+	}
+
+	r.Load(contracts, elements)
+	return nil
+}
+
+// Load 使用给定的合约和合约要素替换注册表内容，可用于离线构建注册表，与上一次快照的差异通过OnEvent通知
+func (r *ContractRegistry) Load(contracts []Contract, elements []ContractElement) {
+	elementByCode := make(map[string]*ContractElement, len(elements))
+	for i := range elements {
+		elementByCode[NormalizeSymbol(elements[i].ContractCode)] = &elements[i]
+	}
+
+	byCode := make(map[string]*ContractInfo, len(contracts)*2)
+	for i := range contracts {
+		info := newContractInfo(contracts[i], elementByCode[NormalizeSymbol(contracts[i].Code)])
+		byCode[info.Code] = info
+	}
+	// 基础货币+计价货币作为别名，不覆盖已有的合约代码
+	for _, info := range byCode {
+		alias := NormalizeSymbol(info.Base + info.Quote)
+		if _, ok := byCode[alias]; !ok && alias != "" {
+			byCode[alias] = info
+		}
+	}
+
+	now := time.Now()
+	r.mutex.Lock()
+	var events []ContractEvent
+	if len(r.byCode) > 0 {
+		events = DiffContracts(uniqueContracts(r.byCode), uniqueContracts(byCode))
+	}
+	r.byCode = byCode
+	r.updatedAt = now
+	handler := r.onEvent
+	r.mutex.Unlock()
+
+	if handler == nil {
+		return
+	}
+	for _, event := range events {
+		event.Time = now
+		handler(event)
+	}
+}
+
+// uniqueContracts 去除别名后的合约列表
+func uniqueContracts(byCode map[string]*ContractInfo) []ContractInfo {
+	result := make([]ContractInfo, 0, len(byCode))
+	for key, info := range byCode {
+		if key == info.Code {
+			result = append(result, *info)
+		}
+	}
+	return result
+}
+
+// newContractInfo 由合约信息和合约要素生成元数据，价格步长优先使用合约要素
+func newContractInfo(contract Contract, element *ContractElement) *ContractInfo {
+	info := &ContractInfo{
+		Code:         NormalizeSymbol(contract.Code),
+		Base:         strings.ToUpper(contract.Base),
+		Quote:        strings.ToUpper(contract.Quote),
+		PriceTick:    TickFromDigits(contract.MarketPriceDigit),
+		PriceDigits:  contract.MarketPriceDigit,
+		AmountDigits: contract.MinTradeDigit,
+		MaxLever:     contract.MaxLever,
+		Inverse:      contract.Direction == 1,
+		Contract:     contract,
+	}
+	if info.Base != "" && info.Quote != "" {
+		info.Symbol = info.Base + "-" + info.Quote
+	} else {
+		info.Symbol = strings.ToUpper(info.Code)
+	}
+	if unit, err := contract.MinTradeUnitDecimal(); err == nil {
+		info.MinTradeUnit = unit
+	}
+	if amount, err := contract.UnitAmountDecimal(); err == nil {
+		info.UnitAmount = amount
+	}
+
+	if element != nil {
+		copied := *element
+		info.Element = &copied
+		if tick, err := element.PriceTickDecimal(); err == nil && tick.Sign() > 0 {
+			info.PriceTick = tick
+		}
+		if size, err := element.ContractSizeDecimal(); err == nil {
+			info.ContractSize = size
+		}
+	}
+	return info
+}
+
+// Loaded 是否已加载合约
+func (r *ContractRegistry) Loaded() bool {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return len(r.byCode) > 0
+}
+
+// UpdatedAt 最近一次成功加载的时间
+func (r *ContractRegistry) UpdatedAt() time.Time {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.updatedAt
+}
+
+// Lookup 按任意写法的交易对查找合约元数据
+func (r *ContractRegistry) Lookup(symbol string) (ContractInfo, bool) {
+	r.mutex.RLock()
+	info, ok := r.byCode[NormalizeSymbol(symbol)]
+	r.mutex.RUnlock()
+	if !ok {
+		return ContractInfo{}, false
+	}
+	return *info, true
+}
+
+// Resolve 将任意写法的交易对转换为规范合约代码，未知交易对返回ErrUnknownSymbol
+func (r *ContractRegistry) Resolve(symbol string) (string, error) {
+	r.mutex.RLock()
+	info, ok := r.byCode[NormalizeSymbol(symbol)]
+	r.mutex.RUnlock()
+	if !ok {
+		return "", fmt.Errorf("%w: %q", ErrUnknownSymbol, symbol)
+	}
+	return info.Code, nil
+}
+
+// Contracts 获取所有合约元数据，按合约代码排序
+func (r *ContractRegistry) Contracts() []ContractInfo {
+	r.mutex.RLock()
+	result := uniqueContracts(r.byCode)
+	r.mutex.RUnlock()
+
+	sort.Slice(result, func(i, j int) bool { return result[i].Code < result[j].Code })
+	return result
+}
+
+// SetContractRegistry 启用合约注册表，传入nil关闭交易对校验和规范化
+// 注册表加载前不做校验，交易对按调用方传入的原样发送
+func (c *Client) SetContractRegistry(registry *ContractRegistry) {
+	c.registry.Store(registry)
+}
+
+// ContractRegistry 获取已启用的合约注册表
+func (c *Client) ContractRegistry() *ContractRegistry {
+	return c.registry.Load()
+}
+
+// contractCode 获取请求路径和参数使用的合约代码
+// 启用并加载注册表后转换为规范合约代码并拒绝未知交易对，否则原样返回
+func (c *Client) contractCode(symbol string) (string, error) {
+	if registry := c.registry.Load(); registry != nil && registry.Loaded() {
+		return registry.Resolve(symbol)
+	}
+	return symbol, nil
+}
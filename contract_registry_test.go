@@ -0,0 +1,193 @@
+package hotcoin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func testContracts() []Contract {
+	return []Contract{
+		{Code: "btcusdt", Base: "btc", Quote: "usdt", MarketPriceDigit: 1, MinTradeDigit: 0, MinTradeUnit: 1, UnitAmount: 0.001, MaxLever: 100},
+		{Code: "ETHUSDT", Base: "eth", Quote: "usdt", MarketPriceDigit: 2, MinTradeUnit: 1, UnitAmount: 0.01, MaxLever: 50, Direction: 1},
+	}
+}
+
+func TestNormalizeSymbol(t *testing.T) {
+	for _, symbol := range []string{"BTC-USDT", "btcusdt", "BTC/USDT", " btc_usdt ", "BTC:USDT"} {
+		if got := NormalizeSymbol(symbol); got != "btcusdt" {
+			t.Errorf("NormalizeSymbol(%q) = %q", symbol, got)
+		}
+	}
+}
+
+func TestContractRegistry(t *testing.T) {
+	registry := NewContractRegistry(nil, nil)
+	calls := 0
+	registry.contracts = func(ctx context.Context) ([]Contract, error) {
+		calls++
+		if calls > 1 {
+			return nil, fmt.Errorf("network down")
+		}
+		return testContracts(), nil
+	}
+	registry.elements = func(ctx context.Context) ([]ContractElement, error) {
+		return []ContractElement{{ContractCode: "BTC-USDT", PriceTick: "0.5", ContractSize: "0.001"}}, nil
+	}
+
+	if _, err := registry.Resolve("BTC-USDT"); !errors.Is(err, ErrUnknownSymbol) {
+		t.Fatalf("expected unknown symbol before load, got %v", err)
+	}
+	if err := registry.Refresh(context.Background()); err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+
+	info, ok := registry.Lookup("BTC/USDT")
+	if !ok {
+		t.Fatal("BTC/USDT not found")
+	}
+	if info.Code != "btcusdt" || info.Symbol != "BTC-USDT" || info.PriceTick.String() != "0.5" ||
+		info.ContractSize.String() != "0.001" || info.UnitAmount.String() != "0.001" || info.MaxLever != 100 {
+		t.Errorf("unexpected btc info: %+v", info)
+	}
+	eth, ok := registry.Lookup("eth-usdt")
+	if !ok || eth.PriceTick.String() != "0.01" || !eth.Inverse || eth.Element != nil {
+		t.Errorf("unexpected eth info: %+v", eth)
+	}
+	if code, err := registry.Resolve("Eth_Usdt"); err != nil || code != "ethusdt" {
+		t.Errorf("Resolve = %q, %v", code, err)
+	}
+	if len(registry.Contracts()) != 2 {
+		t.Errorf("got %d contracts, want 2", len(registry.Contracts()))
+	}
+
+	// 刷新失败时保留原有数据
+	if err := registry.Refresh(context.Background()); err == nil {
+		t.Error("expected refresh error")
+	}
+	if _, ok := registry.Lookup("btcusdt"); !ok {
+		t.Error("registry should keep contracts after failed refresh")
+	}
+}
+
+func TestClientRejectsUnknownSymbol(t *testing.T) {
+	client := NewClient("", "")
+	if code, err := client.contractCode("BTC/USDT"); err != nil || code != "BTC/USDT" {
+		t.Errorf("contractCode without registry = %q, %v", code, err)
+	}
+
+	// 注册表加载前交易对原样发送
+	registry := NewContractRegistry(client, nil)
+	client.SetContractRegistry(registry)
+	if code, err := client.contractCode("BTC-USDT"); err != nil || code != "BTC-USDT" {
+		t.Errorf("contractCode before load = %q, %v", code, err)
+	}
+	registry.Load(testContracts(), nil)
+
+	if _, err := client.Market.GetKline("DOGE-USDT", "1min", 10); !errors.Is(err, ErrUnknownSymbol) {
+		t.Errorf("GetKline: expected ErrUnknownSymbol, got %v", err)
+	}
+	req := &OrderPlaceRequest{Symbol: "DOGE-USDT", Direction: OrderSideBuy, Volume: "1", Price: "0.1"}
+	if _, err := client.Trading.PlaceOrder(req); !errors.Is(err, ErrUnknownSymbol) {
+		t.Errorf("PlaceOrder: expected ErrUnknownSymbol, got %v", err)
+	}
+	if err := client.Position.ClosePosition("DOGE-USDT", ""); !errors.Is(err, ErrUnknownSymbol) {
+		t.Errorf("ClosePosition: expected ErrUnknownSymbol, got %v", err)
+	}
+	if code, err := client.contractCode("ETH-USDT"); err != nil || code != "ethusdt" {
+		t.Errorf("contractCode = %q, %v", code, err)
+	}
+}
+
+func TestClientSendsCanonicalSymbol(t *testing.T) {
+	var mutex sync.Mutex
+	sent := make(map[string]string)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		symbol := r.URL.Query().Get("symbol")
+		if r.Method == http.MethodPost {
+			var body struct {
+				Symbol string `json:"symbol"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			symbol = body.Symbol
+		}
+		mutex.Lock()
+		sent[r.URL.Path] = symbol
+		mutex.Unlock()
+		fmt.Fprint(w, `{"code":200,"data":{"status":"ok","data":{"order_id":"1"}}}`)
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.BaseURL = server.URL
+	client := NewClientWithConfig(config)
+
+	// 未启用注册表时交易对原样发送
+	client.Market.GetTicker("BTC-USDT")
+	mutex.Lock()
+	if got := sent["/api/v1/perpetual/public"]; got != "BTC-USDT" {
+		t.Errorf("symbol without registry = %q, want BTC-USDT", got)
+	}
+	mutex.Unlock()
+
+	contracts := testContracts()
+	for i := range contracts {
+		contracts[i].TradeStatus = 1
+	}
+	registry := NewContractRegistry(client, nil)
+	registry.Load(contracts, nil)
+	client.SetContractRegistry(registry)
+
+	client.Market.GetTicker("BTC/USDT")
+	client.Market.GetIndexPrice("Btc_Usdt")
+	req := &OrderPlaceRequest{Symbol: "BTC/USDT", Direction: OrderSideBuy, Offset: OrderOffsetOpen, Volume: "1", Price: "37000.5"}
+	if _, err := client.Trading.PlaceOrder(req); err != nil {
+		t.Fatalf("place order: %v", err)
+	}
+	if req.Symbol != "BTC/USDT" {
+		t.Errorf("request modified: %q", req.Symbol)
+	}
+	client.Position.ClosePosition("BTC-USDT", "")
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	for _, path := range []string{
+		"/api/v1/perpetual/public",
+		"/api/v1/perpetual/public/index-price",
+		"/api/v1/perpetual/orders",
+		"/api/v1/perpetual/positions/close",
+	} {
+		if sent[path] != "btcusdt" {
+			t.Errorf("%s sent symbol %q, want btcusdt", path, sent[path])
+		}
+	}
+}
+
+func TestContractRegistryRefreshCancel(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	config := DefaultConfig()
+	config.BaseURL = server.URL
+	registry := NewContractRegistry(NewClientWithConfig(config), nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := registry.Refresh(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("refresh ignored ctx, took %v", elapsed)
+	}
+}
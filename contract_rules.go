@@ -0,0 +1,242 @@
+package hotcoin
+
+import "fmt"
+
+// 下单前按合约规则校验和取整：价格精度与最小变动价位、数量为最小交易单位的整数倍、
+// 杠杆不超过最大杠杆、合约可交易。开平方向与不启用注册表时一致，可不指定，
+// 双向持仓下买卖方向与开平方向的四种组合都合法，合约规则中没有额外的组合限制
+
+// checkPrice 校验价格精度和最小变动价位
+func (info ContractInfo) checkPrice(name string, price Decimal) error {
+	if price.Normalize().Scale() > int32(info.PriceDigits) {
+		return fmt.Errorf("%s %s exceeds %d decimal places", name, price, info.PriceDigits)
+	}
+	if info.PriceTick.Sign() > 0 && !price.IsMultipleOf(info.PriceTick) {
+		return fmt.Errorf("%s %s is not a multiple of price tick %s", name, price, info.PriceTick)
+	}
+	return nil
+}
+
+// checkVolume 校验数量精度和最小交易单位
+func (info ContractInfo) checkVolume(volume Decimal) error {
+	if volume.Normalize().Scale() > int32(info.AmountDigits) {
+		return fmt.Errorf("volume %s exceeds %d decimal places", volume, info.AmountDigits)
+	}
+	if info.MinTradeUnit.Sign() > 0 {
+		if volume.LessThan(info.MinTradeUnit) {
+			return fmt.Errorf("volume %s is less than min trade unit %s", volume, info.MinTradeUnit)
+		}
+		if !volume.IsMultipleOf(info.MinTradeUnit) {
+			return fmt.Errorf("volume %s is not a multiple of min trade unit %s", volume, info.MinTradeUnit)
+		}
+	}
+	return nil
+}
+
+// checkLever 校验杠杆倍数，0表示使用默认杠杆
+func (info ContractInfo) checkLever(leverRate int) error {
+	if info.MaxLever > 0 && leverRate > info.MaxLever {
+		return fmt.Errorf("lever_rate %d exceeds max lever %d", leverRate, info.MaxLever)
+	}
+	return nil
+}
+
+// ValidateOrder 按合约规则校验下单请求
+// 合约元数据不限制买卖方向与开平方向的组合，组合规则见OrderPlaceRequest.Validate
+func (info ContractInfo) ValidateOrder(req *OrderPlaceRequest) error {
+	if err := req.Validate(); err != nil {
+		return err
+	}
+	if err := info.checkLever(req.LeverRate); err != nil {
+		return err
+	}
+
+	volume, err := req.VolumeDecimal()
+	if err != nil {
+		return err
+	}
+	if err := info.checkVolume(volume); err != nil {
+		return err
+	}
+	if req.Price != "" {
+		price, err := req.PriceDecimal()
+		if err != nil {
+			return err
+		}
+		if err := info.checkPrice("price", price); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ValidatePlanOrder 按合约规则校验计划委托请求
+func (info ContractInfo) ValidatePlanOrder(req *PlanOrderRequest) error {
+	if err := req.Validate(); err != nil {
+		return err
+	}
+	if err := info.checkLever(req.LeverRate); err != nil {
+		return err
+	}
+
+	volume, err := req.VolumeDecimal()
+	if err != nil {
+		return err
+	}
+	if err := info.checkVolume(volume); err != nil {
+		return err
+	}
+	trigger, err := req.TriggerPriceDecimal()
+	if err != nil {
+		return err
+	}
+	if err := info.checkPrice("trigger_price", trigger); err != nil {
+		return err
+	}
+	if req.OrderPrice != "" {
+		price, err := req.OrderPriceDecimal()
+		if err != nil {
+			return err
+		}
+		if err := info.checkPrice("order_price", price); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RoundPrice 将价格按mode取整到最小变动价位
+func (info ContractInfo) RoundPrice(price Decimal, mode RoundingMode) Decimal {
+	if info.PriceTick.Sign() <= 0 {
+		return price.RoundMode(int32(info.PriceDigits), mode)
+	}
+	return price.RoundStep(info.PriceTick, mode).Normalize()
+}
+
+// RoundOrderPrice 按买卖方向取整价格，买单向下、卖单向上，取整后的价格不会比原价格更激进
+func (info ContractInfo) RoundOrderPrice(price Decimal, direction OrderSide) Decimal {
+	if direction == OrderSideSell {
+		return info.RoundPrice(price, RoundCeiling)
+	}
+	return info.RoundPrice(price, RoundFloor)
+}
+
+// RoundVolume 将数量向下取整到最小交易单位，不会超过原数量
+func (info ContractInfo) RoundVolume(volume Decimal) Decimal {
+	if info.MinTradeUnit.Sign() <= 0 {
+		return volume.RoundMode(int32(info.AmountDigits), RoundDown)
+	}
+	return volume.RoundStep(info.MinTradeUnit, RoundDown).Normalize()
+}
+
+// NormalizeOrder 将下单请求的价格和数量取整为合法值，取整后数量为0时返回错误
+func (info ContractInfo) NormalizeOrder(req *OrderPlaceRequest) error {
+	volume, err := req.VolumeDecimal()
+	if err != nil {
+		return err
+	}
+	volume = info.RoundVolume(volume)
+	if volume.Sign() <= 0 {
+		return fmt.Errorf("volume %s is less than min trade unit %s", req.Volume, info.MinTradeUnit)
+	}
+	req.SetVolume(volume)
+
+	if req.Price != "" {
+		price, err := req.PriceDecimal()
+		if err != nil {
+			return err
+		}
+		req.SetPrice(info.RoundOrderPrice(price, req.Direction))
+	}
+	return nil
+}
+
+// NormalizePlanOrder 将计划委托的触发价、委托价和数量取整为合法值，触发价取最近的合法价格
+func (info ContractInfo) NormalizePlanOrder(req *PlanOrderRequest) error {
+	volume, err := req.VolumeDecimal()
+	if err != nil {
+		return err
+	}
+	volume = info.RoundVolume(volume)
+	if volume.Sign() <= 0 {
+		return fmt.Errorf("volume %s is less than min trade unit %s", req.Volume, info.MinTradeUnit)
+	}
+	req.SetVolume(volume)
+
+	trigger, err := req.TriggerPriceDecimal()
+	if err != nil {
+		return err
+	}
+	req.SetTriggerPrice(info.RoundPrice(trigger, RoundHalfUp))
+
+	if req.OrderPrice != "" {
+		price, err := req.OrderPriceDecimal()
+		if err != nil {
+			return err
+		}
+		req.SetOrderPrice(info.RoundOrderPrice(price, req.Direction))
+	}
+	return nil
+}
+
+// contractInfo 查找合约并校验可交易
+func (r *ContractRegistry) contractInfo(symbol string) (ContractInfo, error) {
+	tradable, err := r.Tradable(symbol)
+	if err != nil {
+		return ContractInfo{}, err
+	}
+	if !tradable {
+		return ContractInfo{}, fmt.Errorf("%w: %q", ErrContractSuspended, symbol)
+	}
+	info, _ := r.Lookup(symbol)
+	return info, nil
+}
+
+// ValidateOrder 按注册表中的合约规则校验下单请求，包括合约是否可交易
+func (r *ContractRegistry) ValidateOrder(req *OrderPlaceRequest) error {
+	info, err := r.contractInfo(req.Symbol)
+	if err != nil {
+		return err
+	}
+	return info.ValidateOrder(req)
+}
+
+// ValidatePlanOrder 按注册表中的合约规则校验计划委托请求，包括合约是否可交易
+func (r *ContractRegistry) ValidatePlanOrder(req *PlanOrderRequest) error {
+	info, err := r.contractInfo(req.Symbol)
+	if err != nil {
+		return err
+	}
+	return info.ValidatePlanOrder(req)
+}
+
+// validateOrder 启用注册表时校验合约可交易并按合约规则校验下单请求
+func (c *Client) validateOrder(req *OrderPlaceRequest) error {
+	if err := c.checkTradable(req.Symbol); err != nil {
+		return err
+	}
+	if info, ok := c.contractRules(req.Symbol); ok {
+		return info.ValidateOrder(req)
+	}
+	return nil
+}
+
+// validatePlanOrder 启用注册表时校验合约可交易并按合约规则校验计划委托请求
+func (c *Client) validatePlanOrder(req *PlanOrderRequest) error {
+	if err := c.checkTradable(req.Symbol); err != nil {
+		return err
+	}
+	if info, ok := c.contractRules(req.Symbol); ok {
+		return info.ValidatePlanOrder(req)
+	}
+	return nil
+}
+
+// contractRules 获取注册表中的合约规则，未启用注册表时返回false
+func (c *Client) contractRules(symbol string) (ContractInfo, bool) {
+	registry := c.registry.Load()
+	if registry == nil || !registry.Loaded() {
+		return ContractInfo{}, false
+	}
+	return registry.Lookup(symbol)
+}
@@ -0,0 +1,133 @@
+package hotcoin
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func testContractInfo() ContractInfo {
+	contract := Contract{Code: "btcusdt", Base: "btc", Quote: "usdt", MarketPriceDigit: 1, MinTradeDigit: 0,
+		MinTradeUnit: 1, UnitAmount: 0.001, MaxLever: 100, TradeStatus: 1}
+	return *newContractInfo(contract, &ContractElement{ContractCode: "btcusdt", PriceTick: "0.5", ContractStatus: 1})
+}
+
+func TestContractInfoValidateOrder(t *testing.T) {
+	info := testContractInfo()
+	valid := OrderPlaceRequest{
+		Symbol:         "BTC-USDT",
+		Direction:      OrderSideBuy,
+		Offset:         OrderOffsetOpen,
+		Volume:         "3",
+		Price:          "37000.5",
+		LeverRate:      20,
+		OrderPriceType: OrderPriceTypeLimit,
+	}
+	if err := info.ValidateOrder(&valid); err != nil {
+		t.Fatalf("valid order: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(r *OrderPlaceRequest)
+		want   string
+	}{
+		{"price precision", func(r *OrderPlaceRequest) { r.Price = "37000.25" }, "exceeds 1 decimal places"},
+		{"price tick", func(r *OrderPlaceRequest) { r.Price = "37000.3" }, "not a multiple of price tick 0.5"},
+		{"volume precision", func(r *OrderPlaceRequest) { r.Volume = "1.5" }, "volume 1.5 exceeds 0 decimal places"},
+		{"lever", func(r *OrderPlaceRequest) { r.LeverRate = 125 }, "exceeds max lever 100"},
+	}
+	for _, tt := range tests {
+		req := valid
+		tt.modify(&req)
+		err := info.ValidateOrder(&req)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: got %v, want %q", tt.name, err, tt.want)
+		}
+	}
+
+	// 双向持仓下买卖方向与开平方向的四种组合都合法
+	for _, direction := range []OrderSide{OrderSideBuy, OrderSideSell} {
+		for _, offset := range []OrderOffset{OrderOffsetOpen, OrderOffsetClose} {
+			req := valid
+			req.Direction, req.Offset = direction, offset
+			if err := info.ValidateOrder(&req); err != nil {
+				t.Errorf("%s-%s: %v", direction, offset, err)
+			}
+		}
+	}
+
+	// 开平方向可不指定，与不启用注册表时一致
+	req := valid
+	req.Offset = ""
+	if err := info.ValidateOrder(&req); err != nil {
+		t.Errorf("order without offset: %v", err)
+	}
+
+	info.MinTradeUnit = MustDecimal("5")
+	req = valid
+	if err := info.ValidateOrder(&req); err == nil || !strings.Contains(err.Error(), "less than min trade unit") {
+		t.Errorf("expected min trade unit error, got %v", err)
+	}
+	req.Volume = "12"
+	if err := info.ValidateOrder(&req); err == nil || !strings.Contains(err.Error(), "not a multiple of min trade unit") {
+		t.Errorf("expected multiple error, got %v", err)
+	}
+}
+
+func TestContractInfoNormalizeOrder(t *testing.T) {
+	info := testContractInfo()
+
+	buy := OrderPlaceRequest{Symbol: "btcusdt", Direction: OrderSideBuy, Offset: OrderOffsetOpen, Volume: "2.9", Price: "37000.37"}
+	if err := info.NormalizeOrder(&buy); err != nil {
+		t.Fatal(err)
+	}
+	if buy.Price != "37000" || buy.Volume != "2" {
+		t.Errorf("buy normalized to %s @ %s", buy.Volume, buy.Price)
+	}
+	if err := info.ValidateOrder(&buy); err != nil {
+		t.Errorf("normalized buy order invalid: %v", err)
+	}
+
+	sell := OrderPlaceRequest{Symbol: "btcusdt", Direction: OrderSideSell, Offset: OrderOffsetClose, Volume: "1", Price: "37000.1"}
+	if err := info.NormalizeOrder(&sell); err != nil {
+		t.Fatal(err)
+	}
+	if sell.Price != "37000.5" {
+		t.Errorf("sell price normalized to %s", sell.Price)
+	}
+
+	tiny := OrderPlaceRequest{Symbol: "btcusdt", Direction: OrderSideBuy, Volume: "0.4"}
+	if err := info.NormalizeOrder(&tiny); err == nil {
+		t.Error("expected error for volume below min trade unit")
+	}
+
+	plan := PlanOrderRequest{Symbol: "btcusdt", TriggerPrice: "37000.3", OrderPrice: "37000.9", Volume: "3.7",
+		Direction: OrderSideBuy, Offset: OrderOffsetOpen, OrderPriceType: OrderPriceTypeLimit}
+	if err := info.NormalizePlanOrder(&plan); err != nil {
+		t.Fatal(err)
+	}
+	if plan.TriggerPrice != "37000.5" || plan.OrderPrice != "37000.5" || plan.Volume != "3" {
+		t.Errorf("unexpected plan order: %+v", plan)
+	}
+	if err := info.ValidatePlanOrder(&plan); err != nil {
+		t.Errorf("normalized plan order invalid: %v", err)
+	}
+}
+
+func TestPlaceOrderUsesContractRules(t *testing.T) {
+	client := NewClient("", "")
+	registry := NewContractRegistry(client, nil)
+	info := testContractInfo()
+	registry.Load([]Contract{info.Contract}, []ContractElement{*info.Element})
+	client.SetContractRegistry(registry)
+
+	req := &OrderPlaceRequest{Symbol: "BTC/USDT", Direction: OrderSideBuy, Offset: OrderOffsetOpen, Volume: "1", Price: "37000.3"}
+	if _, err := client.Trading.PlaceOrder(req); err == nil || !strings.Contains(err.Error(), "price tick") {
+		t.Errorf("expected price tick error, got %v", err)
+	}
+	plan := &PlanOrderRequest{Symbol: "ETH-USDT", TriggerPrice: "2000", Volume: "1", Direction: OrderSideBuy, Offset: OrderOffsetOpen, OrderPriceType: OrderPriceTypeMarket}
+	if _, err := client.Trading.PlacePlanOrder(plan); !errors.Is(err, ErrUnknownSymbol) {
+		t.Errorf("expected ErrUnknownSymbol, got %v", err)
+	}
+}
@@ -0,0 +1,491 @@
+package hotcoin
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// DivisionPrecision 除法默认保留的小数位数
+var DivisionPrecision int32 = 18
+
+// maxDecimalExponent 科学计数法指数的绝对值上限，避免不可信输入产生超大数值
+const maxDecimalExponent = 1000
+
+// MarshalJSONWithoutQuotes 为true时Decimal编码为JSON数字，默认编码为字符串以免接收方按浮点数解析
+var MarshalJSONWithoutQuotes = false
+
+// RoundingMode 舍入模式
+type RoundingMode int
+
+const (
+	RoundHalfUp   RoundingMode = 0 // 四舍五入，0.5远离零
+	RoundHalfEven RoundingMode = 1 // 四舍六入五成双（银行家舍入）
+	RoundHalfDown RoundingMode = 2 // 五舍六入，0.5趋向零
+	RoundDown     RoundingMode = 3 // 向零截断
+	RoundUp       RoundingMode = 4 // 远离零
+	RoundFloor    RoundingMode = 5 // 向负无穷
+	RoundCeiling  RoundingMode = 6 // 向正无穷
+)
+
+// String 返回舍入模式名称
+func (m RoundingMode) String() string {
+	switch m {
+	case RoundHalfUp:
+		return "half_up"
+	case RoundHalfEven:
+		return "half_even"
+	case RoundHalfDown:
+		return "half_down"
+	case RoundDown:
+		return "down"
+	case RoundUp:
+		return "up"
+	case RoundFloor:
+		return "floor"
+	case RoundCeiling:
+		return "ceiling"
+	default:
+		return fmt.Sprintf("RoundingMode(%d)", int(m))
+	}
+}
+
+// Decimal 精确十进制数，值为 value × 10^-scale，零值表示0
+// Decimal是不可变的，所有运算返回新值
+type Decimal struct {
+	value *big.Int
+	scale int32
+}
+
+var (
+	bigZero = big.NewInt(0)
+	bigOne  = big.NewInt(1)
+	bigTwo  = big.NewInt(2)
+	bigTen  = big.NewInt(10)
+)
+
+// NewDecimal 创建 value × 10^-scale
+func NewDecimal(value int64, scale int32) Decimal {
+	d := Decimal{value: big.NewInt(value), scale: scale}
+	if scale < 0 {
+		d = d.rescale(0)
+	}
+	return d
+}
+
+// NewDecimalFromInt 由整数创建
+func NewDecimalFromInt(value int64) Decimal {
+	return Decimal{value: big.NewInt(value)}
+}
+
+// NewDecimalFromString 解析十进制字符串，支持符号、小数和科学计数法
+func NewDecimalFromString(s string) (Decimal, error) {
+	original := s
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return Decimal{}, fmt.Errorf("invalid decimal %q", original)
+	}
+
+	var exp int64
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		var err error
+		exp, err = strconv.ParseInt(s[i+1:], 10, 64)
+		if err != nil {
+			return Decimal{}, fmt.Errorf("invalid decimal %q", original)
+		}
+		if exp > maxDecimalExponent || exp < -maxDecimalExponent {
+			return Decimal{}, fmt.Errorf("decimal exponent out of range %q", original)
+		}
+		s = s[:i]
+	}
+
+	digits := s
+	var scale int64
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		digits = s[:i] + s[i+1:]
+		scale = int64(len(s) - i - 1)
+	}
+
+	body := strings.TrimLeft(digits, "+-")
+	if body == "" || len(digits)-len(body) > 1 || strings.ContainsAny(body, "+-") {
+		return Decimal{}, fmt.Errorf("invalid decimal %q", original)
+	}
+
+	value, ok := new(big.Int).SetString(digits, 10)
+	if !ok {
+		return Decimal{}, fmt.Errorf("invalid decimal %q", original)
+	}
+
+	scale -= exp
+	if scale < -1<<31 || scale > 1<<31-1 {
+		return Decimal{}, fmt.Errorf("decimal exponent out of range %q", original)
+	}
+
+	d := Decimal{value: value, scale: int32(scale)}
+	if d.scale < 0 {
+		d = d.rescale(0)
+	}
+	return d, nil
+}
+
+// NewDecimalFromFloat 由浮点数创建，取能还原该浮点数的最短十进制表示，如0.1返回0.1
+func NewDecimalFromFloat(f float64) (Decimal, error) {
+	return NewDecimalFromString(strconv.FormatFloat(f, 'g', -1, 64))
+}
+
+// MustDecimal 解析十进制字符串，失败时panic
+func MustDecimal(s string) Decimal {
+	d, err := NewDecimalFromString(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// int 获取内部整数值，零值Decimal返回0
+func (d Decimal) int() *big.Int {
+	if d.value == nil {
+		return bigZero
+	}
+	return d.value
+}
+
+// rescale 调整到指定小数位数，缩小小数位时截断
+func (d Decimal) rescale(scale int32) Decimal {
+	if d.scale == scale {
+		return Decimal{value: d.int(), scale: scale}
+	}
+
+	value := new(big.Int)
+	if scale > d.scale {
+		factor := new(big.Int).Exp(bigTen, big.NewInt(int64(scale-d.scale)), nil)
+		value.Mul(d.int(), factor)
+	} else {
+		factor := new(big.Int).Exp(bigTen, big.NewInt(int64(d.scale-scale)), nil)
+		value.Quo(d.int(), factor)
+	}
+	return Decimal{value: value, scale: scale}
+}
+
+// align 对齐两个数的小数位数
+func align(a, b Decimal) (Decimal, Decimal) {
+	if a.scale == b.scale {
+		return a, b
+	}
+	if a.scale > b.scale {
+		return a, b.rescale(a.scale)
+	}
+	return a.rescale(b.scale), b
+}
+
+// Add 加法
+func (d Decimal) Add(other Decimal) Decimal {
+	a, b := align(d, other)
+	return Decimal{value: new(big.Int).Add(a.int(), b.int()), scale: a.scale}
+}
+
+// Sub 减法
+func (d Decimal) Sub(other Decimal) Decimal {
+	a, b := align(d, other)
+	return Decimal{value: new(big.Int).Sub(a.int(), b.int()), scale: a.scale}
+}
+
+// Mul 乘法
+func (d Decimal) Mul(other Decimal) Decimal {
+	return Decimal{value: new(big.Int).Mul(d.int(), other.int()), scale: d.scale + other.scale}
+}
+
+// Div 除法，结果保留DivisionPrecision位小数并四舍五入，除数为0时panic
+func (d Decimal) Div(other Decimal) Decimal {
+	return d.DivRound(other, DivisionPrecision)
+}
+
+// DivRound 除法，结果保留places位小数并四舍五入，除数为0时panic
+func (d Decimal) DivRound(other Decimal, places int32) Decimal {
+	return d.DivRoundMode(other, places, RoundHalfUp)
+}
+
+// DivRoundMode 除法，结果按mode舍入到places位小数，除数为0时panic
+func (d Decimal) DivRoundMode(other Decimal, places int32, mode RoundingMode) Decimal {
+	if other.IsZero() {
+		panic("decimal division by zero")
+	}
+
+	shift := int64(places) + int64(other.scale) - int64(d.scale)
+	numerator := new(big.Int).Set(d.int())
+	denominator := new(big.Int).Set(other.int())
+	if shift >= 0 {
+		numerator.Mul(numerator, new(big.Int).Exp(bigTen, big.NewInt(shift), nil))
+	} else {
+		denominator.Mul(denominator, new(big.Int).Exp(bigTen, big.NewInt(-shift), nil))
+	}
+	if denominator.Sign() < 0 {
+		numerator.Neg(numerator)
+		denominator.Neg(denominator)
+	}
+
+	return Decimal{value: roundQuo(numerator, denominator, mode), scale: places}
+}
+
+// Round 四舍五入（远离零）到places位小数
+func (d Decimal) Round(places int32) Decimal {
+	return d.RoundMode(places, RoundHalfUp)
+}
+
+// RoundMode 按mode舍入到places位小数，places可为负数，如-2表示舍入到百位
+func (d Decimal) RoundMode(places int32, mode RoundingMode) Decimal {
+	if d.scale <= places {
+		return d.rescale(places)
+	}
+
+	factor := new(big.Int).Exp(bigTen, big.NewInt(int64(d.scale-places)), nil)
+	value := roundQuo(d.int(), factor, mode)
+	if places < 0 {
+		// 保持非负的小数位数
+		value.Mul(value, new(big.Int).Exp(bigTen, big.NewInt(int64(-places)), nil))
+		places = 0
+	}
+	return Decimal{value: value, scale: places}
+}
+
+// RoundStep 按mode舍入到step的整数倍，step必须为正数
+func (d Decimal) RoundStep(step Decimal, mode RoundingMode) Decimal {
+	if step.Sign() <= 0 {
+		panic("decimal step must be positive")
+	}
+
+	a, b := align(d, step)
+	quotient := roundQuo(a.int(), b.int(), mode)
+	return Decimal{value: quotient.Mul(quotient, b.int()), scale: a.scale}.rescale(step.scale)
+}
+
+// roundQuo 计算n/m并按mode舍入到整数，m必须为正数
+func roundQuo(n, m *big.Int, mode RoundingMode) *big.Int {
+	quotient, remainder := new(big.Int).QuoRem(n, m, new(big.Int))
+	if remainder.Sign() == 0 {
+		return quotient
+	}
+
+	// QuoRem向零截断，remainder与n同号
+	sign := remainder.Sign()
+	half := new(big.Int).Mul(new(big.Int).Abs(remainder), bigTwo).Cmp(m)
+
+	var increment bool
+	switch mode {
+	case RoundHalfUp:
+		increment = half >= 0
+	case RoundHalfEven:
+		increment = half > 0 || (half == 0 && quotient.Bit(0) == 1)
+	case RoundHalfDown:
+		increment = half > 0
+	case RoundDown:
+		increment = false
+	case RoundUp:
+		increment = true
+	case RoundFloor:
+		increment = sign < 0
+	case RoundCeiling:
+		increment = sign > 0
+	}
+
+	if increment {
+		quotient.Add(quotient, big.NewInt(int64(sign)))
+	}
+	return quotient
+}
+
+// Truncate 截断到places位小数
+func (d Decimal) Truncate(places int32) Decimal {
+	if d.scale <= places {
+		return d
+	}
+	return d.rescale(places)
+}
+
+// Neg 取反
+func (d Decimal) Neg() Decimal {
+	return Decimal{value: new(big.Int).Neg(d.int()), scale: d.scale}
+}
+
+// Abs 绝对值
+func (d Decimal) Abs() Decimal {
+	return Decimal{value: new(big.Int).Abs(d.int()), scale: d.scale}
+}
+
+// Cmp 比较，d<other返回-1，相等返回0，d>other返回1
+func (d Decimal) Cmp(other Decimal) int {
+	a, b := align(d, other)
+	return a.int().Cmp(b.int())
+}
+
+// Equal 是否相等
+func (d Decimal) Equal(other Decimal) bool {
+	return d.Cmp(other) == 0
+}
+
+// LessThan 是否小于
+func (d Decimal) LessThan(other Decimal) bool {
+	return d.Cmp(other) < 0
+}
+
+// GreaterThan 是否大于
+func (d Decimal) GreaterThan(other Decimal) bool {
+	return d.Cmp(other) > 0
+}
+
+// Sign 符号，负数返回-1，零返回0，正数返回1
+func (d Decimal) Sign() int {
+	return d.int().Sign()
+}
+
+// IsZero 是否为0
+func (d Decimal) IsZero() bool {
+	return d.Sign() == 0
+}
+
+// Min 较小值
+func (d Decimal) Min(other Decimal) Decimal {
+	if d.Cmp(other) <= 0 {
+		return d
+	}
+	return other
+}
+
+// Max 较大值
+func (d Decimal) Max(other Decimal) Decimal {
+	if d.Cmp(other) >= 0 {
+		return d
+	}
+	return other
+}
+
+// Scale 小数位数
+func (d Decimal) Scale() int32 {
+	return d.scale
+}
+
+// Float64 转换为float64，仅用于展示，可能损失精度
+func (d Decimal) Float64() float64 {
+	f, _ := new(big.Rat).SetFrac(d.int(), new(big.Int).Exp(bigTen, big.NewInt(int64(d.scale)), nil)).Float64()
+	return f
+}
+
+// String 转换为不带指数的十进制字符串
+func (d Decimal) String() string {
+	s := new(big.Int).Abs(d.int()).String()
+	sign := ""
+	if d.int().Sign() < 0 {
+		sign = "-"
+	}
+	if d.scale <= 0 {
+		return sign + s
+	}
+
+	scale := int(d.scale)
+	if len(s) <= scale {
+		s = strings.Repeat("0", scale-len(s)+1) + s
+	}
+	return sign + s[:len(s)-scale] + "." + s[len(s)-scale:]
+}
+
+// StringFixed 保留places位小数（四舍五入）输出
+func (d Decimal) StringFixed(places int32) string {
+	return d.Round(places).String()
+}
+
+// FloorTo 向下取整到step的整数倍，step必须为正数
+func (d Decimal) FloorTo(step Decimal) Decimal {
+	return d.toMultiple(step, false)
+}
+
+// CeilTo 向上取整到step的整数倍，step必须为正数
+func (d Decimal) CeilTo(step Decimal) Decimal {
+	return d.toMultiple(step, true)
+}
+
+// IsMultipleOf 是否为step的整数倍
+func (d Decimal) IsMultipleOf(step Decimal) bool {
+	if step.Sign() <= 0 {
+		return false
+	}
+	a, b := align(d, step)
+	return new(big.Int).Rem(a.int(), b.int()).Sign() == 0
+}
+
+// toMultiple 取整到step的整数倍
+func (d Decimal) toMultiple(step Decimal, up bool) Decimal {
+	if up {
+		return d.RoundStep(step, RoundCeiling)
+	}
+	return d.RoundStep(step, RoundFloor)
+}
+
+// Normalize 去除末尾多余的0，如1.500返回1.5
+func (d Decimal) Normalize() Decimal {
+	if d.IsZero() {
+		return Decimal{value: new(big.Int)}
+	}
+	if d.scale <= 0 {
+		return d
+	}
+
+	value := new(big.Int).Set(d.int())
+	scale := d.scale
+	remainder := new(big.Int)
+	for scale > 0 {
+		quotient, r := new(big.Int).QuoRem(value, bigTen, remainder)
+		if r.Sign() != 0 {
+			break
+		}
+		value = quotient
+		scale--
+	}
+	return Decimal{value: value, scale: scale}
+}
+
+// MarshalJSON 编码为JSON，默认为字符串，MarshalJSONWithoutQuotes为true时为数字
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	if MarshalJSONWithoutQuotes {
+		return []byte(d.String()), nil
+	}
+	return []byte(`"` + d.String() + `"`), nil
+}
+
+// UnmarshalJSON 解析JSON字符串或数字，null保持原值，空字符串解析为0
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if string(data) == "null" {
+		return nil
+	}
+	if len(data) >= 2 && data[0] == '"' && data[len(data)-1] == '"' {
+		data = data[1 : len(data)-1]
+		if len(data) == 0 {
+			*d = Decimal{}
+			return nil
+		}
+	}
+
+	parsed, err := NewDecimalFromString(string(data))
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+// MarshalText 编码为文本
+func (d Decimal) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText 解析文本
+func (d *Decimal) UnmarshalText(text []byte) error {
+	parsed, err := NewDecimalFromString(string(text))
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
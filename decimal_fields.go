@@ -0,0 +1,524 @@
+package hotcoin
+
+import "fmt"
+
+// 现有结构体中价格、数量等字段为字符串或float64，以下方法提供精确的Decimal读写，
+// 下单相关计算应始终通过这些方法进行，避免浮点误差
+
+// decimalField 解析字符串字段，错误中包含字段名
+func decimalField(name, value string) (Decimal, error) {
+	d, err := NewDecimalFromString(value)
+	if err != nil {
+		return Decimal{}, fmt.Errorf("%s: %w", name, err)
+	}
+	return d, nil
+}
+
+// floatField 将float64字段转换为最短十进制表示，错误中包含字段名
+func floatField(name string, value float64) (Decimal, error) {
+	d, err := NewDecimalFromFloat(value)
+	if err != nil {
+		return Decimal{}, fmt.Errorf("%s: %w", name, err)
+	}
+	return d, nil
+}
+
+// VolumeDecimal 委托数量
+func (o *Order) VolumeDecimal() (Decimal, error) {
+	return decimalField("volume", o.Volume)
+}
+
+// PriceDecimal 委托价格
+func (o *Order) PriceDecimal() (Decimal, error) {
+	return decimalField("price", o.Price)
+}
+
+// MarginFrozenDecimal 冻结保证金
+func (o *Order) MarginFrozenDecimal() (Decimal, error) {
+	return decimalField("margin_frozen", o.MarginFrozen)
+}
+
+// ProfitDecimal 收益
+func (o *Order) ProfitDecimal() (Decimal, error) {
+	return decimalField("profit", o.Profit)
+}
+
+// FeeDecimal 手续费
+func (o *Order) FeeDecimal() (Decimal, error) {
+	return decimalField("fee", o.Fee)
+}
+
+// TradeVolumeDecimal 成交数量
+func (o *Order) TradeVolumeDecimal() (Decimal, error) {
+	return decimalField("trade_volume", o.TradeVolume)
+}
+
+// TradeTurnoverDecimal 成交金额
+func (o *Order) TradeTurnoverDecimal() (Decimal, error) {
+	return decimalField("trade_turnover", o.TradeTurnover)
+}
+
+// TradeAvgPriceDecimal 成交均价
+func (o *Order) TradeAvgPriceDecimal() (Decimal, error) {
+	return decimalField("trade_avg_price", o.TradeAvgPrice)
+}
+
+// PriceDecimal 价格
+func (r *OrderPlaceRequest) PriceDecimal() (Decimal, error) {
+	return decimalField("price", r.Price)
+}
+
+// VolumeDecimal 数量
+func (r *OrderPlaceRequest) VolumeDecimal() (Decimal, error) {
+	return decimalField("volume", r.Volume)
+}
+
+// SetPrice 设置价格
+func (r *OrderPlaceRequest) SetPrice(value Decimal) {
+	r.Price = value.String()
+}
+
+// SetVolume 设置数量
+func (r *OrderPlaceRequest) SetVolume(value Decimal) {
+	r.Volume = value.String()
+}
+
+// TradeVolumeDecimal 成交数量
+func (t *TradeDetail) TradeVolumeDecimal() (Decimal, error) {
+	return decimalField("trade_volume", t.TradeVolume)
+}
+
+// TradePriceDecimal 成交价格
+func (t *TradeDetail) TradePriceDecimal() (Decimal, error) {
+	return decimalField("trade_price", t.TradePrice)
+}
+
+// TradeFeeDecimal 成交手续费
+func (t *TradeDetail) TradeFeeDecimal() (Decimal, error) {
+	return decimalField("trade_fee", t.TradeFee)
+}
+
+// TradeTurnoverDecimal 成交金额
+func (t *TradeDetail) TradeTurnoverDecimal() (Decimal, error) {
+	return decimalField("trade_turnover", t.TradeTurnover)
+}
+
+// VolumeDecimal 委托数量
+func (o *PlanOrder) VolumeDecimal() (Decimal, error) {
+	return decimalField("volume", o.Volume)
+}
+
+// OrderPriceDecimal 委托价格
+func (o *PlanOrder) OrderPriceDecimal() (Decimal, error) {
+	return decimalField("order_price", o.OrderPrice)
+}
+
+// TriggerPriceDecimal 触发价格
+func (o *PlanOrder) TriggerPriceDecimal() (Decimal, error) {
+	return decimalField("trigger_price", o.TriggerPrice)
+}
+
+// TriggerPriceDecimal 触发价格
+func (r *PlanOrderRequest) TriggerPriceDecimal() (Decimal, error) {
+	return decimalField("trigger_price", r.TriggerPrice)
+}
+
+// OrderPriceDecimal 委托价格
+func (r *PlanOrderRequest) OrderPriceDecimal() (Decimal, error) {
+	return decimalField("order_price", r.OrderPrice)
+}
+
+// VolumeDecimal 数量
+func (r *PlanOrderRequest) VolumeDecimal() (Decimal, error) {
+	return decimalField("volume", r.Volume)
+}
+
+// SetTriggerPrice 设置触发价格
+func (r *PlanOrderRequest) SetTriggerPrice(value Decimal) {
+	r.TriggerPrice = value.String()
+}
+
+// SetOrderPrice 设置委托价格
+func (r *PlanOrderRequest) SetOrderPrice(value Decimal) {
+	r.OrderPrice = value.String()
+}
+
+// SetVolume 设置数量
+func (r *PlanOrderRequest) SetVolume(value Decimal) {
+	r.Volume = value.String()
+}
+
+// VolumeDecimal 数量
+func (r *StopOrderRequest) VolumeDecimal() (Decimal, error) {
+	return decimalField("volume", r.Volume)
+}
+
+// TpTriggerPriceDecimal 止盈触发价格
+func (r *StopOrderRequest) TpTriggerPriceDecimal() (Decimal, error) {
+	return decimalField("tp_trigger_price", r.TpTriggerPrice)
+}
+
+// TpOrderPriceDecimal 止盈委托价格
+func (r *StopOrderRequest) TpOrderPriceDecimal() (Decimal, error) {
+	return decimalField("tp_order_price", r.TpOrderPrice)
+}
+
+// SlTriggerPriceDecimal 止损触发价格
+func (r *StopOrderRequest) SlTriggerPriceDecimal() (Decimal, error) {
+	return decimalField("sl_trigger_price", r.SlTriggerPrice)
+}
+
+// SlOrderPriceDecimal 止损委托价格
+func (r *StopOrderRequest) SlOrderPriceDecimal() (Decimal, error) {
+	return decimalField("sl_order_price", r.SlOrderPrice)
+}
+
+// SetVolume 设置数量
+func (r *StopOrderRequest) SetVolume(value Decimal) {
+	r.Volume = value.String()
+}
+
+// SetTpTriggerPrice 设置止盈触发价格
+func (r *StopOrderRequest) SetTpTriggerPrice(value Decimal) {
+	r.TpTriggerPrice = value.String()
+}
+
+// SetTpOrderPrice 设置止盈委托价格
+func (r *StopOrderRequest) SetTpOrderPrice(value Decimal) {
+	r.TpOrderPrice = value.String()
+}
+
+// SetSlTriggerPrice 设置止损触发价格
+func (r *StopOrderRequest) SetSlTriggerPrice(value Decimal) {
+	r.SlTriggerPrice = value.String()
+}
+
+// SetSlOrderPrice 设置止损委托价格
+func (r *StopOrderRequest) SetSlOrderPrice(value Decimal) {
+	r.SlOrderPrice = value.String()
+}
+
+// VolumeDecimal 成交数量
+func (m *MatchResult) VolumeDecimal() (Decimal, error) {
+	return decimalField("volume", m.Volume)
+}
+
+// PriceDecimal 成交价格
+func (m *MatchResult) PriceDecimal() (Decimal, error) {
+	return decimalField("price", m.Price)
+}
+
+// VolumeDecimal 持仓数量
+func (p *PositionDetail) VolumeDecimal() (Decimal, error) {
+	return decimalField("volume", p.Volume)
+}
+
+// AvailableDecimal 可平仓数量
+func (p *PositionDetail) AvailableDecimal() (Decimal, error) {
+	return decimalField("available", p.Available)
+}
+
+// FrozenDecimal 冻结数量
+func (p *PositionDetail) FrozenDecimal() (Decimal, error) {
+	return decimalField("frozen", p.Frozen)
+}
+
+// CostOpenDecimal 开仓均价
+func (p *PositionDetail) CostOpenDecimal() (Decimal, error) {
+	return decimalField("cost_open", p.CostOpen)
+}
+
+// CostHoldDecimal 持仓均价
+func (p *PositionDetail) CostHoldDecimal() (Decimal, error) {
+	return decimalField("cost_hold", p.CostHold)
+}
+
+// ProfitUnrealDecimal 未实现盈亏
+func (p *PositionDetail) ProfitUnrealDecimal() (Decimal, error) {
+	return decimalField("profit_unreal", p.ProfitUnreal)
+}
+
+// ProfitRateDecimal 收益率
+func (p *PositionDetail) ProfitRateDecimal() (Decimal, error) {
+	return decimalField("profit_rate", p.ProfitRate)
+}
+
+// ProfitDecimal 收益
+func (p *PositionDetail) ProfitDecimal() (Decimal, error) {
+	return decimalField("profit", p.Profit)
+}
+
+// PositionMarginDecimal 持仓保证金
+func (p *PositionDetail) PositionMarginDecimal() (Decimal, error) {
+	return decimalField("position_margin", p.PositionMargin)
+}
+
+// LastPriceDecimal 最新价
+func (p *PositionDetail) LastPriceDecimal() (Decimal, error) {
+	return decimalField("last_price", p.LastPrice)
+}
+
+// MarginBalanceDecimal 账户权益
+func (a *AccountInfo) MarginBalanceDecimal() (Decimal, error) {
+	return decimalField("margin_balance", a.MarginBalance)
+}
+
+// MarginStaticDecimal 静态权益
+func (a *AccountInfo) MarginStaticDecimal() (Decimal, error) {
+	return decimalField("margin_static", a.MarginStatic)
+}
+
+// MarginPositionDecimal 持仓保证金
+func (a *AccountInfo) MarginPositionDecimal() (Decimal, error) {
+	return decimalField("margin_position", a.MarginPosition)
+}
+
+// MarginFrozenDecimal 冻结保证金
+func (a *AccountInfo) MarginFrozenDecimal() (Decimal, error) {
+	return decimalField("margin_frozen", a.MarginFrozen)
+}
+
+// MarginAvailableDecimal 可用保证金
+func (a *AccountInfo) MarginAvailableDecimal() (Decimal, error) {
+	return decimalField("margin_available", a.MarginAvailable)
+}
+
+// ProfitRealDecimal 已实现盈亏
+func (a *AccountInfo) ProfitRealDecimal() (Decimal, error) {
+	return decimalField("profit_real", a.ProfitReal)
+}
+
+// ProfitUnrealDecimal 未实现盈亏
+func (a *AccountInfo) ProfitUnrealDecimal() (Decimal, error) {
+	return decimalField("profit_unreal", a.ProfitUnreal)
+}
+
+// WithdrawAvailableDecimal 可提取数量
+func (a *AccountInfo) WithdrawAvailableDecimal() (Decimal, error) {
+	return decimalField("withdraw_available", a.WithdrawAvailable)
+}
+
+// RiskRateDecimal 保证金率
+func (a *AccountInfo) RiskRateDecimal() (Decimal, error) {
+	return decimalField("risk_rate", a.RiskRate)
+}
+
+// LiquidationPriceDecimal 预估强平价
+func (a *AccountInfo) LiquidationPriceDecimal() (Decimal, error) {
+	return decimalField("liquidation_price", a.LiquidationPrice)
+}
+
+// MarginBalanceDecimal 账户权益
+func (b *AccountBalance) MarginBalanceDecimal() (Decimal, error) {
+	return decimalField("margin_balance", b.MarginBalance)
+}
+
+// MarginStaticDecimal 静态权益
+func (b *AccountBalance) MarginStaticDecimal() (Decimal, error) {
+	return decimalField("margin_static", b.MarginStatic)
+}
+
+// MarginPositionDecimal 持仓保证金
+func (b *AccountBalance) MarginPositionDecimal() (Decimal, error) {
+	return decimalField("margin_position", b.MarginPosition)
+}
+
+// MarginFrozenDecimal 冻结保证金
+func (b *AccountBalance) MarginFrozenDecimal() (Decimal, error) {
+	return decimalField("margin_frozen", b.MarginFrozen)
+}
+
+// MarginAvailableDecimal 可用保证金
+func (b *AccountBalance) MarginAvailableDecimal() (Decimal, error) {
+	return decimalField("margin_available", b.MarginAvailable)
+}
+
+// ProfitRealDecimal 已实现盈亏
+func (b *AccountBalance) ProfitRealDecimal() (Decimal, error) {
+	return decimalField("profit_real", b.ProfitReal)
+}
+
+// ProfitUnrealDecimal 未实现盈亏
+func (b *AccountBalance) ProfitUnrealDecimal() (Decimal, error) {
+	return decimalField("profit_unreal", b.ProfitUnreal)
+}
+
+// WithdrawAvailableDecimal 可提取数量
+func (b *AccountBalance) WithdrawAvailableDecimal() (Decimal, error) {
+	return decimalField("withdraw_available", b.WithdrawAvailable)
+}
+
+// RiskRateDecimal 保证金率
+func (b *AccountBalance) RiskRateDecimal() (Decimal, error) {
+	return decimalField("risk_rate", b.RiskRate)
+}
+
+// LiquidationPriceDecimal 强平价格
+func (b *AccountBalance) LiquidationPriceDecimal() (Decimal, error) {
+	return decimalField("liquidation_price", b.LiquidationPrice)
+}
+
+// OpenMakerDecimal 开仓maker费率
+func (f *FeeRate) OpenMakerDecimal() (Decimal, error) {
+	return decimalField("open_maker", f.OpenMaker)
+}
+
+// OpenTakerDecimal 开仓taker费率
+func (f *FeeRate) OpenTakerDecimal() (Decimal, error) {
+	return decimalField("open_taker", f.OpenTaker)
+}
+
+// CloseMakerDecimal 平仓maker费率
+func (f *FeeRate) CloseMakerDecimal() (Decimal, error) {
+	return decimalField("close_maker", f.CloseMaker)
+}
+
+// CloseTakerDecimal 平仓taker费率
+func (f *FeeRate) CloseTakerDecimal() (Decimal, error) {
+	return decimalField("close_taker", f.CloseTaker)
+}
+
+// PriceDecimal 最新价
+func (c *Contract) PriceDecimal() (Decimal, error) {
+	return decimalField("price", c.Price)
+}
+
+// MarkPriceDecimal 标记价格
+func (c *Contract) MarkPriceDecimal() (Decimal, error) {
+	return decimalField("mark_price", c.MarkPrice)
+}
+
+// IndexPriceDecimal 指数价格
+func (c *Contract) IndexPriceDecimal() (Decimal, error) {
+	return decimalField("index_price", c.IndexPrice)
+}
+
+// UnitAmountDecimal 一张合约对应的quote面值，由float64字段按最短十进制表示转换
+func (c *Contract) UnitAmountDecimal() (Decimal, error) {
+	return floatField("unit_amount", c.UnitAmount)
+}
+
+// MinTradeUnitDecimal 最小交易单位，由float64字段按最短十进制表示转换
+func (c *Contract) MinTradeUnitDecimal() (Decimal, error) {
+	return floatField("min_trade_unit", c.MinTradeUnit)
+}
+
+// LastPriceDecimal 最新成交价格
+func (t *TickerData) LastPriceDecimal() (Decimal, error) {
+	return decimalField("last_price", t.LastPrice)
+}
+
+// BidDecimal 买一价
+func (t *TickerData) BidDecimal() (Decimal, error) {
+	return decimalField("bid", t.Bid)
+}
+
+// AskDecimal 卖一价
+func (t *TickerData) AskDecimal() (Decimal, error) {
+	return decimalField("ask", t.Ask)
+}
+
+// HighDecimal 24小时最高价
+func (t *TickerData) HighDecimal() (Decimal, error) {
+	return decimalField("high", t.High)
+}
+
+// LowDecimal 24小时最低价
+func (t *TickerData) LowDecimal() (Decimal, error) {
+	return decimalField("low", t.Low)
+}
+
+// MarkPriceDecimal 标记价格
+func (t *TickerData) MarkPriceDecimal() (Decimal, error) {
+	return decimalField("mark_price", t.MarkPrice)
+}
+
+// IndexPriceDecimal 指数价格
+func (t *TickerData) IndexPriceDecimal() (Decimal, error) {
+	return decimalField("index_price", t.IndexPrice)
+}
+
+// FundingRateDecimal 资金费率
+func (t *TickerData) FundingRateDecimal() (Decimal, error) {
+	return decimalField("funding_rate", t.FundingRate)
+}
+
+// UnitAmountDecimal 一张合约对应的quote面值，由float64字段按最短十进制表示转换
+func (t *TickerData) UnitAmountDecimal() (Decimal, error) {
+	return floatField("unit_amount", t.UnitAmount)
+}
+
+// MinTradeUnitDecimal 最小交易单位，由float64字段按最短十进制表示转换
+func (t *TickerData) MinTradeUnitDecimal() (Decimal, error) {
+	return floatField("min_trade_unit", t.MinTradeUnit)
+}
+
+// ContractSizeDecimal 合约面值
+func (e *ContractElement) ContractSizeDecimal() (Decimal, error) {
+	return decimalField("contract_size", e.ContractSize)
+}
+
+// PriceTickDecimal 最小变动价位
+func (e *ContractElement) PriceTickDecimal() (Decimal, error) {
+	return decimalField("price_tick", e.PriceTick)
+}
+
+// OpenDecimal 开盘价
+func (k *KlineData) OpenDecimal() (Decimal, error) {
+	return decimalField("open", k.Open)
+}
+
+// HighDecimal 最高价
+func (k *KlineData) HighDecimal() (Decimal, error) {
+	return decimalField("high", k.High)
+}
+
+// LowDecimal 最低价
+func (k *KlineData) LowDecimal() (Decimal, error) {
+	return decimalField("low", k.Low)
+}
+
+// CloseDecimal 收盘价
+func (k *KlineData) CloseDecimal() (Decimal, error) {
+	return decimalField("close", k.Close)
+}
+
+// VolumeDecimal 成交量
+func (k *KlineData) VolumeDecimal() (Decimal, error) {
+	return decimalField("volume", k.Volume)
+}
+
+// PriceDecimal 成交价格
+func (t *TradeData) PriceDecimal() (Decimal, error) {
+	return decimalField("price", t.Price)
+}
+
+// AmountDecimal 成交数量
+func (t *TradeData) AmountDecimal() (Decimal, error) {
+	return decimalField("amount", t.Amount)
+}
+
+// FundingRateDecimal 资金费率
+func (f *FundingRate) FundingRateDecimal() (Decimal, error) {
+	return decimalField("funding_rate", f.FundingRate)
+}
+
+// MarkPriceDecimal 标记价格
+func (f *FundingRate) MarkPriceDecimal() (Decimal, error) {
+	return decimalField("mark_price", f.MarkPrice)
+}
+
+// IndexPriceDecimal 指数价格
+func (f *FundingRate) IndexPriceDecimal() (Decimal, error) {
+	return decimalField("index_price", f.IndexPrice)
+}
+
+// BidLevels 买盘档位
+func (d *DepthData) BidLevels() ([]DepthLevel, error) {
+	return parseDepthLevels(d.Bids)
+}
+
+// AskLevels 卖盘档位
+func (d *DepthData) AskLevels() ([]DepthLevel, error) {
+	return parseDepthLevels(d.Asks)
+}
@@ -0,0 +1,158 @@
+package hotcoin
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestDecimalParseAndString(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"0", "0"},
+		{"1.50", "1.50"},
+		{"-0.001", "-0.001"},
+		{"+12.5", "12.5"},
+		{".5", "0.5"},
+		{"1e3", "1000"},
+		{"1.5E-3", "0.0015"},
+		{"1e+2", "100"},
+		{"37000.1", "37000.1"},
+	}
+
+	for _, tt := range tests {
+		d, err := NewDecimalFromString(tt.input)
+		if err != nil {
+			t.Errorf("parse %q: %v", tt.input, err)
+			continue
+		}
+		if d.String() != tt.want {
+			t.Errorf("parse %q: got %s, want %s", tt.input, d.String(), tt.want)
+		}
+	}
+
+	for _, input := range []string{"", "abc", "1.2.3", "--1", "1e", "+-1", "1e5x", "1e 5", "1e999999999", "1e-999999999", "1e1001"} {
+		if _, err := NewDecimalFromString(input); err == nil {
+			t.Errorf("parse %q should fail", input)
+		}
+	}
+}
+
+func TestDecimalArithmetic(t *testing.T) {
+	a := MustDecimal("0.1")
+	b := MustDecimal("0.2")
+
+	if got := a.Add(b).String(); got != "0.3" {
+		t.Errorf("0.1 + 0.2 = %s", got)
+	}
+	if got := a.Sub(b).String(); got != "-0.1" {
+		t.Errorf("0.1 - 0.2 = %s", got)
+	}
+	if got := a.Mul(b).String(); got != "0.02" {
+		t.Errorf("0.1 * 0.2 = %s", got)
+	}
+	if got := NewDecimalFromInt(2).DivRound(NewDecimalFromInt(3), 4).String(); got != "0.6667" {
+		t.Errorf("2 / 3 = %s", got)
+	}
+	if got := MustDecimal("-2.5").Round(0).String(); got != "-3" {
+		t.Errorf("round(-2.5) = %s", got)
+	}
+	if got := MustDecimal("1.2399").Truncate(2).String(); got != "1.23" {
+		t.Errorf("truncate(1.2399) = %s", got)
+	}
+	if !MustDecimal("1.0").Equal(MustDecimal("1")) {
+		t.Error("1.0 should equal 1")
+	}
+
+	var zero Decimal
+	if !zero.IsZero() || zero.Add(a).String() != "0.1" {
+		t.Error("zero value should behave as 0")
+	}
+}
+
+func TestDecimalRoundingModes(t *testing.T) {
+	tests := []struct {
+		input string
+		mode  RoundingMode
+		want  string
+	}{
+		{"2.5", RoundHalfUp, "3"},
+		{"-2.5", RoundHalfUp, "-3"},
+		{"2.5", RoundHalfEven, "2"},
+		{"3.5", RoundHalfEven, "4"},
+		{"2.5", RoundHalfDown, "2"},
+		{"2.51", RoundHalfDown, "3"},
+		{"2.9", RoundDown, "2"},
+		{"-2.9", RoundDown, "-2"},
+		{"2.1", RoundUp, "3"},
+		{"-2.1", RoundFloor, "-3"},
+		{"-2.9", RoundCeiling, "-2"},
+		{"2", RoundUp, "2"},
+	}
+
+	for _, tt := range tests {
+		if got := MustDecimal(tt.input).RoundMode(0, tt.mode).String(); got != tt.want {
+			t.Errorf("%s %s: got %s, want %s", tt.input, tt.mode, got, tt.want)
+		}
+	}
+
+	if got := MustDecimal("1234.5").RoundMode(-2, RoundHalfUp).String(); got != "1200" {
+		t.Errorf("round to hundreds: got %s", got)
+	}
+	if got := MustDecimal("37000.27").RoundStep(MustDecimal("0.5"), RoundHalfEven).String(); got != "37000.5" {
+		t.Errorf("round step: got %s", got)
+	}
+	if got := NewDecimalFromInt(2).DivRoundMode(NewDecimalFromInt(3), 2, RoundDown).String(); got != "0.66" {
+		t.Errorf("div round down: got %s", got)
+	}
+	if got := NewDecimalFromInt(1).DivRound(NewDecimalFromInt(-8), 2).String(); got != "-0.13" {
+		t.Errorf("div round negative: got %s", got)
+	}
+}
+
+func TestDecimalJSON(t *testing.T) {
+	var v struct {
+		Quoted  Decimal `json:"quoted"`
+		Numeric Decimal `json:"numeric"`
+		Empty   Decimal `json:"empty"`
+		Null    Decimal `json:"null"`
+	}
+	v.Null = NewDecimalFromInt(7)
+
+	data := `{"quoted":"0.1","numeric":37000.25,"empty":"","null":null}`
+	if err := json.Unmarshal([]byte(data), &v); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if v.Quoted.String() != "0.1" || v.Numeric.String() != "37000.25" || !v.Empty.IsZero() || v.Null.String() != "7" {
+		t.Errorf("unexpected values: %+v", v)
+	}
+
+	out, err := json.Marshal(v.Numeric)
+	if err != nil || string(out) != `"37000.25"` {
+		t.Errorf("marshal: %s %v", out, err)
+	}
+	if err := json.Unmarshal([]byte(`"abc"`), &v.Quoted); err == nil {
+		t.Error("expected error for invalid decimal")
+	}
+}
+
+func TestDecimalFields(t *testing.T) {
+	contract := Contract{UnitAmount: 0.1, MinTradeUnit: 1e-4}
+	unit, err := contract.UnitAmountDecimal()
+	if err != nil || unit.String() != "0.1" {
+		t.Errorf("unit amount: %s %v", unit, err)
+	}
+	if minUnit, _ := contract.MinTradeUnitDecimal(); minUnit.String() != "0.0001" {
+		t.Errorf("min trade unit: %s", minUnit)
+	}
+
+	var request OrderPlaceRequest
+	request.SetPrice(MustDecimal("37000.5"))
+	if price, _ := request.PriceDecimal(); price.String() != "37000.5" || request.Price != "37000.5" {
+		t.Errorf("price: %s", request.Price)
+	}
+	if _, err := request.VolumeDecimal(); err == nil {
+		t.Error("expected error for empty volume")
+	}
+}
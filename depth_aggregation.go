@@ -0,0 +1,152 @@
+package hotcoin
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DepthAggregator 本地深度聚合，将全精度深度按最小变动价位的倍数合并为更粗的档位
+// 买盘价格向下取整、卖盘价格向上取整，聚合后的买卖盘不会交叉
+type DepthAggregator struct {
+	tick Decimal
+}
+
+// NewDepthAggregator 使用最小变动价位创建聚合器
+func NewDepthAggregator(tick Decimal) (*DepthAggregator, error) {
+	if tick.Sign() <= 0 {
+		return nil, fmt.Errorf("tick must be positive")
+	}
+	return &DepthAggregator{tick: tick}, nil
+}
+
+// NewDepthAggregatorForContract 使用合约的价格小数位创建聚合器
+func NewDepthAggregatorForContract(contract *Contract) (*DepthAggregator, error) {
+	if contract == nil {
+		return nil, fmt.Errorf("contract is required")
+	}
+	return NewDepthAggregator(TickFromDigits(contract.MarketPriceDigit))
+}
+
+// NewDepthAggregatorForElement 使用合约要素中的最小变动价位创建聚合器
+func NewDepthAggregatorForElement(element *ContractElement) (*DepthAggregator, error) {
+	if element == nil {
+		return nil, fmt.Errorf("contract element is required")
+	}
+	tick, err := element.PriceTickDecimal()
+	if err != nil {
+		return nil, err
+	}
+	return NewDepthAggregator(tick)
+}
+
+// TickFromDigits 由价格小数位计算最小变动价位，如2位小数为0.01
+func TickFromDigits(digits int) Decimal {
+	return NewDecimal(1, int32(digits))
+}
+
+// DepthStepMultiplier 深度类型对应的最小变动价位倍数，stepN为10^N倍
+func DepthStepMultiplier(depthType string) (int64, error) {
+	if !strings.HasPrefix(depthType, "step") {
+		return 0, fmt.Errorf("invalid depth type %q", depthType)
+	}
+	n, err := strconv.Atoi(strings.TrimPrefix(depthType, "step"))
+	if err != nil || n < 0 || n > 5 {
+		return 0, fmt.Errorf("invalid depth type %q", depthType)
+	}
+
+	multiplier := int64(1)
+	for i := 0; i < n; i++ {
+		multiplier *= 10
+	}
+	return multiplier, nil
+}
+
+// Tick 获取最小变动价位
+func (a *DepthAggregator) Tick() Decimal {
+	return a.tick
+}
+
+// Step 获取深度类型对应的聚合步长
+func (a *DepthAggregator) Step(depthType string) (Decimal, error) {
+	multiplier, err := DepthStepMultiplier(depthType)
+	if err != nil {
+		return Decimal{}, err
+	}
+	return a.tick.Mul(NewDecimalFromInt(multiplier)).Normalize(), nil
+}
+
+// Aggregate 按深度类型聚合，step0返回按价格合并后的原始深度
+func (a *DepthAggregator) Aggregate(depth *DepthData, depthType string) (*DepthData, error) {
+	step, err := a.Step(depthType)
+	if err != nil {
+		return nil, err
+	}
+	return AggregateDepth(depth, step)
+}
+
+// AggregateAll 生成step0-step5所有聚合档位
+func (a *DepthAggregator) AggregateAll(depth *DepthData) (map[string]*DepthData, error) {
+	analytics, err := AnalyzeDepth(depth)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]*DepthData, 6)
+	for n := 0; n <= 5; n++ {
+		depthType := fmt.Sprintf("step%d", n)
+		step, _ := a.Step(depthType)
+		result[depthType] = &DepthData{
+			Bids: aggregateLevels(analytics.Bids, step, false),
+			Asks: aggregateLevels(analytics.Asks, step, true),
+		}
+	}
+	return result, nil
+}
+
+// AggregateDepth 将深度按step的整数倍合并档位
+func AggregateDepth(depth *DepthData, step Decimal) (*DepthData, error) {
+	if step.Sign() <= 0 {
+		return nil, fmt.Errorf("step must be positive")
+	}
+
+	analytics, err := AnalyzeDepth(depth)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DepthData{
+		Bids: aggregateLevels(analytics.Bids, step, false),
+		Asks: aggregateLevels(analytics.Asks, step, true),
+	}, nil
+}
+
+// aggregateLevels 合并有序档位，up为true时价格向上取整
+func aggregateLevels(levels []DepthLevel, step Decimal, up bool) [][]string {
+	result := make([][]string, 0, len(levels))
+
+	var bucket Decimal
+	var amount Decimal
+	started := false
+	for _, level := range levels {
+		price := level.Price.FloorTo(step)
+		if up {
+			price = level.Price.CeilTo(step)
+		}
+
+		if started && price.Equal(bucket) {
+			amount = amount.Add(level.Amount)
+			continue
+		}
+		if started {
+			result = append(result, []string{bucket.String(), amount.String()})
+		}
+		bucket = price
+		amount = level.Amount
+		started = true
+	}
+	if started {
+		result = append(result, []string{bucket.String(), amount.String()})
+	}
+	return result
+}
@@ -0,0 +1,132 @@
+package hotcoin
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestDecimalFloorCeilTo(t *testing.T) {
+	tests := []struct {
+		value, step string
+		floor, ceil string
+	}{
+		{"100.5", "1", "100", "101"},
+		{"100", "1", "100", "100"},
+		{"100.00", "0.5", "100", "100"},
+		{"99.99", "10", "90", "100"},
+		{"0.001", "0.01", "0", "0.01"},
+		{"-1.5", "1", "-2", "-1"},
+		{"37000.05", "0.1", "37000", "37000.1"},
+	}
+	for _, tt := range tests {
+		value, step := MustDecimal(tt.value), MustDecimal(tt.step)
+		if got := value.FloorTo(step); !got.Equal(MustDecimal(tt.floor)) {
+			t.Errorf("%s.FloorTo(%s) = %s, want %s", tt.value, tt.step, got, tt.floor)
+		}
+		if got := value.CeilTo(step); !got.Equal(MustDecimal(tt.ceil)) {
+			t.Errorf("%s.CeilTo(%s) = %s, want %s", tt.value, tt.step, got, tt.ceil)
+		}
+	}
+}
+
+func TestDepthAggregatorBuckets(t *testing.T) {
+	aggregator, err := NewDepthAggregator(MustDecimal("0.1"))
+	if err != nil {
+		t.Fatalf("new aggregator: %v", err)
+	}
+	depth := &DepthData{
+		Bids: [][]string{{"100.0", "1"}, {"99.9", "2"}, {"99.1", "3"}, {"99.0", "4"}, {"98.95", "5"}},
+		Asks: [][]string{{"100.1", "1"}, {"100.2", "2"}, {"101.0", "3"}, {"101.01", "4"}},
+	}
+
+	tests := []struct {
+		depthType  string
+		bids, asks [][]string
+	}{
+		{
+			"step0",
+			[][]string{{"100.0", "1"}, {"99.9", "2"}, {"99.1", "3"}, {"99.0", "4"}, {"98.9", "5"}},
+			[][]string{{"100.1", "1"}, {"100.2", "2"}, {"101.0", "3"}, {"101.1", "4"}},
+		},
+		{
+			// 档位边界上的价格归入自身所在的档位
+			"step1",
+			[][]string{{"100", "1"}, {"99", "9"}, {"98", "5"}},
+			[][]string{{"101", "6"}, {"102", "4"}},
+		},
+		{
+			"step2",
+			[][]string{{"100", "1"}, {"90", "14"}},
+			[][]string{{"110", "10"}},
+		},
+	}
+	for _, tt := range tests {
+		result, err := aggregator.Aggregate(depth, tt.depthType)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.depthType, err)
+		}
+		if !reflect.DeepEqual(result.Bids, tt.bids) || !reflect.DeepEqual(result.Asks, tt.asks) {
+			t.Errorf("%s: bids %v asks %v, want %v %v", tt.depthType, result.Bids, result.Asks, tt.bids, tt.asks)
+		}
+	}
+
+	if _, err := aggregator.Aggregate(depth, "step6"); err == nil {
+		t.Error("expected invalid depth type error")
+	}
+}
+
+func TestGetAggregatedDepth(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.RequestURI())
+		switch r.URL.Path {
+		case "/api/v1/perpetual/public":
+			fmt.Fprint(w, `{"code":200,"data":[{"code":"btcusdt","marketPriceDigit":1}]}`)
+		case "/api/v1/perpetual/public/products/btcusdt/orderbook":
+			fmt.Fprint(w, `{"code":200,"data":{"bids":[["100.0","1"],["99.9","2"]],"asks":[["100.1","1"],["100.9","2"]]}}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.BaseURL = server.URL
+	client := NewClientWithConfig(config)
+
+	depth, err := client.Market.GetAggregatedDepth("btcusdt", "step1")
+	if err != nil {
+		t.Fatalf("aggregated depth: %v", err)
+	}
+	if !reflect.DeepEqual(depth.Bids, [][]string{{"100", "1"}, {"99", "2"}}) || !reflect.DeepEqual(depth.Asks, [][]string{{"101", "3"}}) {
+		t.Errorf("unexpected depth: %+v", depth)
+	}
+	if depth.SourceBids != 2 || depth.SourceAsks != 2 {
+		t.Errorf("source levels = %d/%d", depth.SourceBids, depth.SourceAsks)
+	}
+	want := []string{
+		"/api/v1/perpetual/public?symbol=btcusdt",
+		"/api/v1/perpetual/public/products/btcusdt/orderbook?size=20",
+	}
+	if !reflect.DeepEqual(requests, want) {
+		t.Errorf("requests = %v, want %v", requests, want)
+	}
+
+	// 启用合约注册表时不再查询合约信息
+	registry := NewContractRegistry(client, nil)
+	registry.Load(testContracts(), nil)
+	client.SetContractRegistry(registry)
+	requests = nil
+	if _, err := client.Market.GetAggregatedDepth("btc/usdt", "step0"); err != nil {
+		t.Fatalf("aggregated depth with registry: %v", err)
+	}
+	if len(requests) != 1 {
+		t.Errorf("requests = %v", requests)
+	}
+	if _, err := client.Market.GetAggregatedDepth("btcusdt", "step9"); err == nil {
+		t.Error("expected invalid depth type error")
+	}
+}
@@ -0,0 +1,288 @@
+package hotcoin
+
+import (
+	"fmt"
+	"sort"
+)
+
+// bpsFactor 基点换算系数
+var bpsFactor = NewDecimalFromInt(10000)
+
+// DepthLevel 精确数值表示的价格档位
+type DepthLevel struct {
+	Price  Decimal // 价格
+	Amount Decimal // 数量
+}
+
+// DepthAnalytics 深度分析，基于DepthData/WSDepthData使用精确十进制计算
+// 成交额按价格×数量计算，数量单位与深度数据一致
+type DepthAnalytics struct {
+	Bids []DepthLevel // 买盘，按价格从高到低
+	Asks []DepthLevel // 卖盘，按价格从低到高
+}
+
+// AnalyzeDepth 分析REST深度数据
+func AnalyzeDepth(depth *DepthData) (*DepthAnalytics, error) {
+	if depth == nil {
+		return nil, fmt.Errorf("depth is required")
+	}
+	return newDepthAnalytics(depth.Bids, depth.Asks)
+}
+
+// AnalyzeWSDepth 分析WebSocket深度数据
+func AnalyzeWSDepth(depth *WSDepthData) (*DepthAnalytics, error) {
+	if depth == nil {
+		return nil, fmt.Errorf("depth is required")
+	}
+	return newDepthAnalytics(depth.Bids, depth.Asks)
+}
+
+// newDepthAnalytics 解析并排序档位
+func newDepthAnalytics(bids, asks [][]string) (*DepthAnalytics, error) {
+	parsedBids, err := parseDepthLevels(bids)
+	if err != nil {
+		return nil, fmt.Errorf("parse bids: %w", err)
+	}
+	parsedAsks, err := parseDepthLevels(asks)
+	if err != nil {
+		return nil, fmt.Errorf("parse asks: %w", err)
+	}
+
+	sort.Slice(parsedBids, func(i, j int) bool { return parsedBids[i].Price.GreaterThan(parsedBids[j].Price) })
+	sort.Slice(parsedAsks, func(i, j int) bool { return parsedAsks[i].Price.LessThan(parsedAsks[j].Price) })
+
+	return &DepthAnalytics{Bids: parsedBids, Asks: parsedAsks}, nil
+}
+
+// parseDepthLevels 解析[价格, 数量]档位
+func parseDepthLevels(raw [][]string) ([]DepthLevel, error) {
+	levels := make([]DepthLevel, 0, len(raw))
+	for i, item := range raw {
+		if len(item) < 2 {
+			return nil, fmt.Errorf("level %d: expected [price, amount]", i)
+		}
+		price, err := NewDecimalFromString(item[0])
+		if err != nil {
+			return nil, fmt.Errorf("level %d: %w", i, err)
+		}
+		amount, err := NewDecimalFromString(item[1])
+		if err != nil {
+			return nil, fmt.Errorf("level %d: %w", i, err)
+		}
+		levels = append(levels, DepthLevel{Price: price, Amount: amount})
+	}
+	return levels, nil
+}
+
+// BestBid 买一
+func (a *DepthAnalytics) BestBid() (DepthLevel, bool) {
+	if len(a.Bids) == 0 {
+		return DepthLevel{}, false
+	}
+	return a.Bids[0], true
+}
+
+// BestAsk 卖一
+func (a *DepthAnalytics) BestAsk() (DepthLevel, bool) {
+	if len(a.Asks) == 0 {
+		return DepthLevel{}, false
+	}
+	return a.Asks[0], true
+}
+
+// Mid 中间价
+func (a *DepthAnalytics) Mid() (Decimal, bool) {
+	bid, okBid := a.BestBid()
+	ask, okAsk := a.BestAsk()
+	if !okBid || !okAsk {
+		return Decimal{}, false
+	}
+	return bid.Price.Add(ask.Price).Div(NewDecimalFromInt(2)), true
+}
+
+// Microprice 按买一卖一数量加权的微观价格
+// microprice = (买一价×卖一量 + 卖一价×买一量) / (买一量 + 卖一量)
+func (a *DepthAnalytics) Microprice() (Decimal, bool) {
+	bid, okBid := a.BestBid()
+	ask, okAsk := a.BestAsk()
+	if !okBid || !okAsk {
+		return Decimal{}, false
+	}
+
+	total := bid.Amount.Add(ask.Amount)
+	if total.IsZero() {
+		return a.Mid()
+	}
+	weighted := bid.Price.Mul(ask.Amount).Add(ask.Price.Mul(bid.Amount))
+	return weighted.Div(total), true
+}
+
+// Spread 买卖价差
+func (a *DepthAnalytics) Spread() (Decimal, bool) {
+	bid, okBid := a.BestBid()
+	ask, okAsk := a.BestAsk()
+	if !okBid || !okAsk {
+		return Decimal{}, false
+	}
+	return ask.Price.Sub(bid.Price), true
+}
+
+// SpreadBps 以中间价为基准的价差（基点）
+func (a *DepthAnalytics) SpreadBps() (Decimal, bool) {
+	spread, ok := a.Spread()
+	if !ok {
+		return Decimal{}, false
+	}
+	mid, _ := a.Mid()
+	if mid.IsZero() {
+		return Decimal{}, false
+	}
+	return spread.Mul(bpsFactor).Div(mid), true
+}
+
+// DepthWithin 距中间价bps基点范围内的累计数量
+// side为OrderSideBuy时统计买盘，OrderSideSell时统计卖盘
+func (a *DepthAnalytics) DepthWithin(side OrderSide, bps Decimal) (Decimal, error) {
+	mid, ok := a.Mid()
+	if !ok {
+		return Decimal{}, fmt.Errorf("book has no two-sided quote")
+	}
+
+	offset := mid.Mul(bps).Div(bpsFactor)
+	var total Decimal
+	switch side {
+	case OrderSideBuy:
+		limit := mid.Sub(offset)
+		for _, level := range a.Bids {
+			if level.Price.LessThan(limit) {
+				break
+			}
+			total = total.Add(level.Amount)
+		}
+	case OrderSideSell:
+		limit := mid.Add(offset)
+		for _, level := range a.Asks {
+			if level.Price.GreaterThan(limit) {
+				break
+			}
+			total = total.Add(level.Amount)
+		}
+	default:
+		return Decimal{}, fmt.Errorf("invalid side %q", side)
+	}
+	return total, nil
+}
+
+// Imbalance 前levels档买卖盘数量不平衡度，范围[-1, 1]，正值表示买盘更厚
+// levels<=0时统计全部档位
+func (a *DepthAnalytics) Imbalance(levels int) Decimal {
+	bidVolume := sumAmount(a.Bids, levels)
+	askVolume := sumAmount(a.Asks, levels)
+
+	total := bidVolume.Add(askVolume)
+	if total.IsZero() {
+		return Decimal{}
+	}
+	return bidVolume.Sub(askVolume).Div(total)
+}
+
+// FillByVolume 估算以市价成交volume数量的成本
+// side为吃单方向：OrderSideBuy消耗卖盘，OrderSideSell消耗买盘
+func (a *DepthAnalytics) FillByVolume(side OrderSide, volume Decimal) (*FillEstimate, error) {
+	if volume.Sign() <= 0 {
+		return nil, fmt.Errorf("volume must be positive")
+	}
+	return a.fill(side, volume, false)
+}
+
+// FillByNotional 估算以市价成交notional金额的成本
+func (a *DepthAnalytics) FillByNotional(side OrderSide, notional Decimal) (*FillEstimate, error) {
+	if notional.Sign() <= 0 {
+		return nil, fmt.Errorf("notional must be positive")
+	}
+	return a.fill(side, notional, true)
+}
+
+// fill 按档位逐级成交，byNotional为true时target为成交额，否则为数量
+func (a *DepthAnalytics) fill(side OrderSide, target Decimal, byNotional bool) (*FillEstimate, error) {
+	var levels []DepthLevel
+	switch side {
+	case OrderSideBuy:
+		levels = a.Asks
+	case OrderSideSell:
+		levels = a.Bids
+	default:
+		return nil, fmt.Errorf("invalid side %q", side)
+	}
+	if len(levels) == 0 {
+		return nil, fmt.Errorf("no liquidity on %s side", side)
+	}
+
+	estimate := &FillEstimate{Side: side, BestPrice: levels[0].Price}
+	remaining := target
+	for _, level := range levels {
+		if remaining.Sign() <= 0 {
+			break
+		}
+
+		amount := level.Amount
+		notional := level.Price.Mul(amount)
+		if byNotional {
+			if !notional.LessThan(remaining) {
+				// 当前档位足以成交剩余金额，除法的舍入误差不再计入剩余
+				amount = remaining.Div(level.Price)
+				notional = remaining
+			}
+			remaining = remaining.Sub(notional)
+		} else {
+			if !amount.LessThan(remaining) {
+				amount = remaining
+				notional = level.Price.Mul(amount)
+			}
+			remaining = remaining.Sub(amount)
+		}
+		if amount.Sign() <= 0 {
+			continue
+		}
+
+		estimate.Filled = estimate.Filled.Add(amount)
+		estimate.Notional = estimate.Notional.Add(notional)
+		estimate.WorstPrice = level.Price
+		estimate.Levels++
+	}
+
+	estimate.Complete = remaining.Sign() <= 0
+	if estimate.Filled.IsZero() {
+		return estimate, nil
+	}
+
+	estimate.AvgPrice = estimate.Notional.Div(estimate.Filled)
+	slippage := estimate.AvgPrice.Sub(estimate.BestPrice)
+	if side == OrderSideSell {
+		slippage = slippage.Neg()
+	}
+	estimate.Slippage = slippage
+	if !estimate.BestPrice.IsZero() {
+		estimate.SlippageBps = slippage.Mul(bpsFactor).Div(estimate.BestPrice)
+	}
+	if mid, ok := a.Mid(); ok && !mid.IsZero() {
+		cost := estimate.AvgPrice.Sub(mid)
+		if side == OrderSideSell {
+			cost = cost.Neg()
+		}
+		estimate.MidCostBps = cost.Mul(bpsFactor).Div(mid)
+	}
+	return estimate, nil
+}
+
+// sumAmount 累计前n档数量
+func sumAmount(levels []DepthLevel, n int) Decimal {
+	if n <= 0 || n > len(levels) {
+		n = len(levels)
+	}
+	var total Decimal
+	for _, level := range levels[:n] {
+		total = total.Add(level.Amount)
+	}
+	return total
+}
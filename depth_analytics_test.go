@@ -0,0 +1,105 @@
+package hotcoin
+
+import (
+	"testing"
+)
+
+func TestDepthAnalytics(t *testing.T) {
+	analytics, err := AnalyzeDepth(&DepthData{
+		Bids: [][]string{{"99", "2"}, {"100", "1"}},
+		Asks: [][]string{{"101", "1"}, {"102", "3"}},
+	})
+	if err != nil {
+		t.Fatalf("analyze: %v", err)
+	}
+
+	mid, _ := analytics.Mid()
+	if !mid.Equal(MustDecimal("100.5")) {
+		t.Errorf("mid = %s", mid)
+	}
+
+	micro, _ := analytics.Microprice()
+	if !micro.Equal(MustDecimal("100.5")) {
+		t.Errorf("microprice = %s", micro)
+	}
+
+	spreadBps, _ := analytics.SpreadBps()
+	if got := spreadBps.StringFixed(4); got != "99.5025" {
+		t.Errorf("spread bps = %s", got)
+	}
+
+	// 买入2张：1@101 + 1@102，均价101.5
+	fill, err := analytics.FillByVolume(OrderSideBuy, NewDecimalFromInt(2))
+	if err != nil {
+		t.Fatalf("fill: %v", err)
+	}
+	if !fill.Complete || !fill.AvgPrice.Equal(MustDecimal("101.5")) || fill.Levels != 2 {
+		t.Errorf("unexpected fill: %+v", fill)
+	}
+	if !fill.Slippage.Equal(MustDecimal("0.5")) {
+		t.Errorf("slippage = %s", fill.Slippage)
+	}
+
+	// 卖出金额超出深度
+	fill, err = analytics.FillByNotional(OrderSideSell, NewDecimalFromInt(1000))
+	if err != nil {
+		t.Fatalf("fill: %v", err)
+	}
+	if fill.Complete || !fill.Filled.Equal(NewDecimalFromInt(3)) || !fill.Notional.Equal(NewDecimalFromInt(298)) {
+		t.Errorf("unexpected fill: %+v", fill)
+	}
+
+	// 不平衡度：(3-4)/7
+	if got := analytics.Imbalance(0).StringFixed(6); got != "-0.142857" {
+		t.Errorf("imbalance = %s", got)
+	}
+
+	// 中间价100.5，100基点范围上限101.505，200基点范围上限102.51
+	within, _ := analytics.DepthWithin(OrderSideSell, NewDecimalFromInt(100))
+	if !within.Equal(NewDecimalFromInt(1)) {
+		t.Errorf("ask depth within 100bps = %s", within)
+	}
+	within, _ = analytics.DepthWithin(OrderSideSell, NewDecimalFromInt(200))
+	if !within.Equal(NewDecimalFromInt(4)) {
+		t.Errorf("ask depth within 200bps = %s", within)
+	}
+}
+
+func TestDepthAnalyticsFillByNotionalRounding(t *testing.T) {
+	analytics, err := AnalyzeDepth(&DepthData{
+		Bids: [][]string{{"2", "100"}},
+		Asks: [][]string{{"3", "100"}, {"5", "100"}},
+	})
+	if err != nil {
+		t.Fatalf("analyze: %v", err)
+	}
+
+	// 100/3无法整除，剩余金额不应进入下一档
+	fill, err := analytics.FillByNotional(OrderSideBuy, NewDecimalFromInt(100))
+	if err != nil {
+		t.Fatalf("fill: %v", err)
+	}
+	if fill.Levels != 1 || !fill.WorstPrice.Equal(MustDecimal("3")) || !fill.Complete {
+		t.Errorf("unexpected fill: %+v", fill)
+	}
+	if !fill.Notional.Equal(NewDecimalFromInt(100)) {
+		t.Errorf("notional = %s", fill.Notional)
+	}
+}
+
+func TestDepthAnalyticsZeroBestPrice(t *testing.T) {
+	analytics, err := AnalyzeDepth(&DepthData{
+		Asks: [][]string{{"0", "1"}, {"2", "1"}},
+	})
+	if err != nil {
+		t.Fatalf("analyze: %v", err)
+	}
+
+	fill, err := analytics.FillByVolume(OrderSideBuy, NewDecimalFromInt(2))
+	if err != nil {
+		t.Fatalf("fill: %v", err)
+	}
+	if !fill.SlippageBps.IsZero() || fill.Levels != 2 {
+		t.Errorf("unexpected fill: %+v", fill)
+	}
+}
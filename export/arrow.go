@@ -0,0 +1,305 @@
+package export
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"strings"
+
+	hotcoin "github.com/kivenman/hotcoin-go-sdk"
+)
+
+// Arrow IPC文件格式常量
+const (
+	arrowMagic           = "ARROW1"
+	arrowMetadataV5      = 4
+	arrowHeaderSchema    = 1
+	arrowHeaderBatch     = 3
+	arrowTypeInt         = 2
+	arrowTypeFloat       = 3
+	arrowTypeUtf8        = 5
+	arrowTypeDecimal     = 7
+	arrowTypeTimestamp   = 10
+	arrowPrecisionDouble = 2
+	arrowUnitMillisecond = 1
+	arrowContinuation    = 0xFFFFFFFF
+	arrowDecimalWidth    = 128
+)
+
+var (
+	// decimalFactor Decimal列的缩放系数10^DecimalScale
+	decimalFactor = hotcoin.MustDecimal("1" + strings.Repeat("0", DecimalScale))
+	// decimalLimit decimal128(DecimalPrecision)的上界10^DecimalPrecision
+	decimalLimit = new(big.Int).Exp(big.NewInt(10), big.NewInt(DecimalPrecision), nil)
+	// decimalModulus 128位补码的模2^128
+	decimalModulus = new(big.Int).Lsh(big.NewInt(1), arrowDecimalWidth)
+)
+
+// arrowBlock 记录批次在文件中的位置
+type arrowBlock struct {
+	offset         int64
+	metadataLength int32
+	bodyLength     int64
+}
+
+// arrowEncoder Arrow IPC文件编码器
+// 文件结构：魔数、Schema消息、记录批次消息、结束标记、文件尾、文件尾长度、魔数
+type arrowEncoder struct {
+	writer  io.Writer
+	schema  []Column
+	offset  int64
+	started bool
+	blocks  []arrowBlock
+}
+
+// newArrowEncoder 创建Arrow编码器
+func newArrowEncoder(w io.Writer, schema []Column) *arrowEncoder {
+	return &arrowEncoder{writer: w, schema: schema}
+}
+
+// write 写入数据并记录偏移
+func (e *arrowEncoder) write(data []byte) error {
+	n, err := e.writer.Write(data)
+	e.offset += int64(n)
+	return err
+}
+
+// start 写入文件头和Schema消息
+func (e *arrowEncoder) start() error {
+	if e.started {
+		return nil
+	}
+	e.started = true
+
+	if err := e.write([]byte(arrowMagic + "\x00\x00")); err != nil {
+		return err
+	}
+	message := newFBTable(5).
+		scalar(0, 2, arrowMetadataV5).
+		scalar(1, 1, arrowHeaderSchema).
+		offset(2, e.schemaTable()).
+		scalar(3, 8, 0)
+	_, err := e.writeMessage(buildFlatbuffer(message), nil)
+	return err
+}
+
+// writeRows 写入一个记录批次
+func (e *arrowEncoder) writeRows(rows [][]Value) error {
+	if err := e.start(); err != nil {
+		return err
+	}
+
+	var body []byte
+	var nodes, buffers []byte
+	addBuffer := func(data []byte) {
+		buffers = binary.LittleEndian.AppendUint64(buffers, uint64(len(body)))
+		buffers = binary.LittleEndian.AppendUint64(buffers, uint64(len(data)))
+		body = append(body, data...)
+		for len(body)%8 != 0 {
+			body = append(body, 0)
+		}
+	}
+
+	for col, column := range e.schema {
+		validity, nulls := arrowValidity(rows, col)
+		nodes = binary.LittleEndian.AppendUint64(nodes, uint64(len(rows)))
+		nodes = binary.LittleEndian.AppendUint64(nodes, uint64(nulls))
+		addBuffer(validity)
+
+		switch column.Type {
+		case Int32:
+			values := make([]byte, 0, 4*len(rows))
+			for _, row := range rows {
+				values = binary.LittleEndian.AppendUint32(values, uint32(row[col].Int))
+			}
+			addBuffer(values)
+		case Int64, Timestamp:
+			values := make([]byte, 0, 8*len(rows))
+			for _, row := range rows {
+				values = binary.LittleEndian.AppendUint64(values, uint64(row[col].Int))
+			}
+			addBuffer(values)
+		case Float64:
+			values := make([]byte, 0, 8*len(rows))
+			for _, row := range rows {
+				values = binary.LittleEndian.AppendUint64(values, math.Float64bits(row[col].Float))
+			}
+			addBuffer(values)
+		case Decimal:
+			values := make([]byte, 0, 16*len(rows))
+			for _, row := range rows {
+				var err error
+				if values, err = appendDecimal128(values, row[col]); err != nil {
+					return fmt.Errorf("column %s: %w", column.Name, err)
+				}
+			}
+			addBuffer(values)
+		case String:
+			offsets := make([]byte, 0, 4*(len(rows)+1))
+			var data []byte
+			offsets = binary.LittleEndian.AppendUint32(offsets, 0)
+			for _, row := range rows {
+				data = append(data, row[col].Raw...)
+				offsets = binary.LittleEndian.AppendUint32(offsets, uint32(len(data)))
+			}
+			addBuffer(offsets)
+			addBuffer(data)
+		}
+	}
+
+	batch := newFBTable(3).
+		scalar(0, 8, uint64(len(rows))).
+		offset(1, &fbStructs{align: 8, count: len(e.schema), data: nodes}).
+		offset(2, &fbStructs{align: 8, count: len(buffers) / 16, data: buffers})
+	message := newFBTable(5).
+		scalar(0, 2, arrowMetadataV5).
+		scalar(1, 1, arrowHeaderBatch).
+		offset(2, batch).
+		scalar(3, 8, uint64(len(body)))
+
+	block, err := e.writeMessage(buildFlatbuffer(message), body)
+	if err != nil {
+		return err
+	}
+	e.blocks = append(e.blocks, block)
+	return nil
+}
+
+// close 写入结束标记和文件尾
+func (e *arrowEncoder) close() error {
+	if err := e.start(); err != nil {
+		return err
+	}
+
+	var eos [8]byte
+	binary.LittleEndian.PutUint32(eos[:], arrowContinuation)
+	if err := e.write(eos[:]); err != nil {
+		return err
+	}
+
+	blocks := make([]byte, 0, 24*len(e.blocks))
+	for _, block := range e.blocks {
+		blocks = binary.LittleEndian.AppendUint64(blocks, uint64(block.offset))
+		blocks = binary.LittleEndian.AppendUint32(blocks, uint32(block.metadataLength))
+		blocks = binary.LittleEndian.AppendUint32(blocks, 0)
+		blocks = binary.LittleEndian.AppendUint64(blocks, uint64(block.bodyLength))
+	}
+	footer := buildFlatbuffer(newFBTable(4).
+		scalar(0, 2, arrowMetadataV5).
+		offset(1, e.schemaTable()).
+		offset(2, &fbStructs{align: 8}).
+		offset(3, &fbStructs{align: 8, count: len(e.blocks), data: blocks}))
+
+	if err := e.write(footer); err != nil {
+		return err
+	}
+	var size [4]byte
+	binary.LittleEndian.PutUint32(size[:], uint32(len(footer)))
+	if err := e.write(size[:]); err != nil {
+		return err
+	}
+	return e.write([]byte(arrowMagic))
+}
+
+// writeMessage 写入封装的IPC消息：续写标记、元数据长度、元数据、消息体
+func (e *arrowEncoder) writeMessage(metadata, body []byte) (arrowBlock, error) {
+	block := arrowBlock{
+		offset:         e.offset,
+		metadataLength: int32(8 + len(metadata)),
+		bodyLength:     int64(len(body)),
+	}
+
+	var prefix [8]byte
+	binary.LittleEndian.PutUint32(prefix[:], arrowContinuation)
+	binary.LittleEndian.PutUint32(prefix[4:], uint32(len(metadata)))
+	for _, data := range [][]byte{prefix[:], metadata, body} {
+		if err := e.write(data); err != nil {
+			return block, fmt.Errorf("write arrow message: %w", err)
+		}
+	}
+	return block, nil
+}
+
+// schemaTable 构建Schema表
+func (e *arrowEncoder) schemaTable() *fbTable {
+	fields := make(fbTables, len(e.schema))
+	for i, column := range e.schema {
+		typeID, typeTable := arrowType(column.Type)
+		fields[i] = newFBTable(7).
+			offset(0, fbString(column.Name)).
+			scalar(1, 1, 1).
+			scalar(2, 1, typeID).
+			offset(3, typeTable).
+			offset(5, fbTables{})
+	}
+	return newFBTable(4).
+		scalar(0, 2, 0).
+		offset(1, fields)
+}
+
+// arrowType 获取列对应的Arrow类型
+func arrowType(t ColumnType) (uint64, *fbTable) {
+	switch t {
+	case Int32:
+		return arrowTypeInt, newFBTable(2).scalar(0, 4, 32).scalar(1, 1, 1)
+	case Int64:
+		return arrowTypeInt, newFBTable(2).scalar(0, 4, 64).scalar(1, 1, 1)
+	case Float64:
+		return arrowTypeFloat, newFBTable(1).scalar(0, 2, arrowPrecisionDouble)
+	case Decimal:
+		return arrowTypeDecimal, newFBTable(3).
+			scalar(0, 4, DecimalPrecision).
+			scalar(1, 4, DecimalScale).
+			scalar(2, 4, arrowDecimalWidth)
+	case Timestamp:
+		return arrowTypeTimestamp, newFBTable(2).scalar(0, 2, arrowUnitMillisecond).offset(1, fbString("UTC"))
+	default:
+		return arrowTypeUtf8, newFBTable(0)
+	}
+}
+
+// arrowValidity 构建有效位图，无空值时返回空位图
+func arrowValidity(rows [][]Value, col int) ([]byte, int) {
+	nulls := 0
+	for _, row := range rows {
+		if row[col].Null {
+			nulls++
+		}
+	}
+	if nulls == 0 {
+		return nil, 0
+	}
+
+	bitmap := make([]byte, (len(rows)+7)/8)
+	for i, row := range rows {
+		if !row[col].Null {
+			bitmap[i/8] |= 1 << (i % 8)
+		}
+	}
+	return bitmap, nulls
+}
+
+// appendDecimal128 按DecimalScale缩放为128位小端序补码整数，空值写入0
+func appendDecimal128(values []byte, value Value) ([]byte, error) {
+	var word [16]byte
+	if !value.Null {
+		scaled := value.Decimal.Mul(decimalFactor).Normalize()
+		if scaled.Scale() > 0 {
+			return nil, fmt.Errorf("%s exceeds %d decimal places", value.Decimal, DecimalScale)
+		}
+		unscaled, _ := new(big.Int).SetString(scaled.String(), 10)
+		if new(big.Int).Abs(unscaled).Cmp(decimalLimit) >= 0 {
+			return nil, fmt.Errorf("%s exceeds decimal precision %d", value.Decimal, DecimalPrecision)
+		}
+		if unscaled.Sign() < 0 {
+			unscaled.Add(unscaled, decimalModulus)
+		}
+		unscaled.FillBytes(word[:])
+		for i, j := 0, len(word)-1; i < j; i, j = i+1, j-1 {
+			word[i], word[j] = word[j], word[i]
+		}
+	}
+	return append(values, word[:]...), nil
+}
@@ -0,0 +1,156 @@
+package arrowcheck
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/ipc"
+
+	hotcoin "github.com/kivenman/hotcoin-go-sdk"
+	"github.com/kivenman/hotcoin-go-sdk/export"
+)
+
+// readArrow 用arrow-go读取文件，返回Schema和全部记录批次
+func readArrow(t *testing.T, file []byte) (*arrow.Schema, []arrow.RecordBatch) {
+	t.Helper()
+	reader, err := ipc.NewFileReader(bytes.NewReader(file))
+	if err != nil {
+		t.Fatalf("open arrow file: %v", err)
+	}
+	t.Cleanup(func() { reader.Close() })
+
+	batches := make([]arrow.RecordBatch, reader.NumRecords())
+	for i := range batches {
+		batch, err := reader.RecordBatch(i)
+		if err != nil {
+			t.Fatalf("read batch %d: %v", i, err)
+		}
+		batch.Retain()
+		t.Cleanup(batch.Release)
+		batches[i] = batch
+	}
+	return reader.Schema(), batches
+}
+
+// decimalString 读取decimal128值，空值返回空字符串
+func decimalString(column arrow.Array, i int) string {
+	if column.IsNull(i) {
+		return ""
+	}
+	return hotcoin.MustDecimal(column.(*array.Decimal128).ValueStr(i)).Normalize().String()
+}
+
+func TestKlinesRoundTrip(t *testing.T) {
+	klines := []hotcoin.KlineData{
+		{Timestamp: 1700000000, Open: "36990.0", High: "37005.0", Low: "36985.5", Close: "37000.5", Volume: "1520"},
+		{Timestamp: 1700000060, Open: "0.000000000000000001", High: "99999999999999999999.999999999999999999", Low: "-12.5", Close: "37008", Volume: ""},
+		{Timestamp: 1700000120, Open: "37008", High: "37008", Low: "37001", Close: "37002.25", Volume: "12.5"},
+	}
+
+	var buf bytes.Buffer
+	writer, err := export.NewKlineWriter(&buf, export.FormatArrow)
+	if err != nil {
+		t.Fatalf("new writer: %v", err)
+	}
+	writer.SetBatchSize(2)
+	if err := writer.Write(klines...); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	schema, batches := readArrow(t, buf.Bytes())
+	wantTypes := []arrow.DataType{&arrow.TimestampType{Unit: arrow.Millisecond, TimeZone: "UTC"}}
+	for range export.KlineSchema[1:] {
+		wantTypes = append(wantTypes, &arrow.Decimal128Type{Precision: export.DecimalPrecision, Scale: export.DecimalScale})
+	}
+	for i, field := range schema.Fields() {
+		if field.Name != export.KlineSchema[i].Name || !arrow.TypeEqual(field.Type, wantTypes[i]) || !field.Nullable {
+			t.Errorf("field %d = %s, want %s: %s", i, field, export.KlineSchema[i].Name, wantTypes[i])
+		}
+	}
+	if len(batches) != 2 || batches[0].NumRows() != 2 || batches[1].NumRows() != 1 {
+		t.Fatalf("unexpected batches: %d", len(batches))
+	}
+
+	row := 0
+	for _, batch := range batches {
+		for i := 0; i < int(batch.NumRows()); i++ {
+			kline := klines[row]
+			ts := batch.Column(0).(*array.Timestamp).Value(i)
+			if want := time.Unix(kline.Timestamp, 0).UnixMilli(); int64(ts) != want {
+				t.Errorf("row %d timestamp = %d, want %d", row, ts, want)
+			}
+			for col, want := range []string{kline.Open, kline.High, kline.Low, kline.Close, kline.Volume} {
+				if want != "" {
+					want = hotcoin.MustDecimal(want).Normalize().String()
+				}
+				if got := decimalString(batch.Column(col+1), i); got != want {
+					t.Errorf("row %d %s = %q, want %q", row, export.KlineSchema[col+1].Name, got, want)
+				}
+			}
+			row++
+		}
+	}
+}
+
+func TestRecordsRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	trades := []hotcoin.TradeData{
+		{ID: 7, Price: "37000.5", Amount: "0.001", Side: "buy", Timestamp: 1700000000123},
+		{ID: 8, Price: "37001", Amount: "2", Side: "sell", Timestamp: 1700000000456},
+	}
+	if err := export.WriteTrades(&buf, export.FormatArrow, trades); err != nil {
+		t.Fatalf("write trades: %v", err)
+	}
+	_, batches := readArrow(t, buf.Bytes())
+	batch := batches[0]
+	for i, trade := range trades {
+		if id := batch.Column(0).(*array.Int64).Value(i); id != trade.ID {
+			t.Errorf("trade %d id = %d", i, id)
+		}
+		if ts := batch.Column(1).(*array.Timestamp).Value(i); int64(ts) != trade.Timestamp {
+			t.Errorf("trade %d timestamp = %d", i, ts)
+		}
+		if price := decimalString(batch.Column(2), i); price != trade.Price {
+			t.Errorf("trade %d price = %s", i, price)
+		}
+		if amount := decimalString(batch.Column(3), i); amount != trade.Amount {
+			t.Errorf("trade %d amount = %s", i, amount)
+		}
+		if side := batch.Column(4).(*array.String).Value(i); side != trade.Side {
+			t.Errorf("trade %d side = %s", i, side)
+		}
+	}
+
+	buf.Reset()
+	records := []hotcoin.FinancialRecord{{ID: 3, Type: 5, Amount: "-0.00012345", Symbol: "usdt", ContractCode: "btcusdt", Ts: hotcoin.Timestamp{Time: time.UnixMilli(1700000000000)}}}
+	if err := export.WriteFinancialRecords(&buf, export.FormatArrow, records); err != nil {
+		t.Fatalf("write financial records: %v", err)
+	}
+	_, batches = readArrow(t, buf.Bytes())
+	batch = batches[0]
+	if kind := batch.Column(2).(*array.Int32).Value(0); kind != 5 {
+		t.Errorf("type = %d", kind)
+	}
+	if amount := decimalString(batch.Column(3), 0); amount != "-0.00012345" {
+		t.Errorf("amount = %s", amount)
+	}
+	if code := batch.Column(5).(*array.String).Value(0); code != "btcusdt" {
+		t.Errorf("contract_code = %s", code)
+	}
+
+	// 无数据时仍是合法的空文件
+	buf.Reset()
+	if err := export.WriteFundingRates(&buf, export.FormatArrow, nil); err != nil {
+		t.Fatalf("write empty: %v", err)
+	}
+	schema, batches := readArrow(t, buf.Bytes())
+	if len(batches) != 0 || len(schema.Fields()) != len(export.FundingRateSchema) {
+		t.Errorf("empty file: %d batches, %d fields", len(batches), len(schema.Fields()))
+	}
+}
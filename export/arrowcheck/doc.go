@@ -0,0 +1,6 @@
+// Package arrowcheck 用Apache Arrow官方Go实现读取export包生成的Arrow IPC文件，校验手写编码器的兼容性
+//
+// 独立模块：arrow-go要求Go 1.25及以上，不将其引入SDK的依赖。在本目录下运行：
+//
+//	go test ./...
+package arrowcheck
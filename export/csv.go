@@ -0,0 +1,60 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+)
+
+// csvEncoder CSV编码器
+type csvEncoder struct {
+	writer *csv.Writer
+	schema []Column
+	header bool
+}
+
+// newCSVEncoder 创建CSV编码器
+func newCSVEncoder(w io.Writer, schema []Column) *csvEncoder {
+	return &csvEncoder{writer: csv.NewWriter(w), schema: schema}
+}
+
+// writeHeader 写入列名
+func (e *csvEncoder) writeHeader() error {
+	if e.header {
+		return nil
+	}
+	e.header = true
+
+	names := make([]string, len(e.schema))
+	for i, column := range e.schema {
+		names[i] = column.Name
+	}
+	return e.writer.Write(names)
+}
+
+// writeRows 写入数据行，空值输出为空字符串
+func (e *csvEncoder) writeRows(rows [][]Value) error {
+	if err := e.writeHeader(); err != nil {
+		return err
+	}
+
+	record := make([]string, len(e.schema))
+	for _, row := range rows {
+		for i, value := range row {
+			record[i] = value.Raw
+		}
+		if err := e.writer.Write(record); err != nil {
+			return err
+		}
+	}
+	e.writer.Flush()
+	return e.writer.Error()
+}
+
+// close 写入列名（无数据时）并刷新
+func (e *csvEncoder) close() error {
+	if err := e.writeHeader(); err != nil {
+		return err
+	}
+	e.writer.Flush()
+	return e.writer.Error()
+}
@@ -0,0 +1,325 @@
+// Package export 将行情和账户数据导出为CSV或Arrow IPC文件
+//
+// 支持KlineData、TradeData、FundingRate和FinancialRecord。价格和数量不经过float64转换：
+// CSV中保留接口返回的原始十进制文本，Arrow中为decimal128(38, 18)；时间戳导出为UTC毫秒时间戳。
+// Arrow文件可直接用pandas.read_feather（十进制列读取为decimal.Decimal）或polars.read_ipc读取。
+// Arrow编码器为纯Go实现，export/arrowcheck模块用arrow-go读取校验其输出。
+//
+//	file, _ := os.Create("klines.arrow")
+//	defer file.Close()
+//	err := export.WriteKlines(file, export.FormatArrow, klines)
+//
+// 流式写入：
+//
+//	writer, _ := export.NewTradeWriter(file, export.FormatArrow)
+//	for trades := range batches {
+//		writer.Write(trades...)
+//	}
+//	writer.Close()
+package export
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	hotcoin "github.com/kivenman/hotcoin-go-sdk"
+)
+
+// Format 导出格式
+type Format int
+
+const (
+	FormatCSV   Format = 0 // CSV，首行为列名
+	FormatArrow Format = 1 // Arrow IPC文件格式（Feather V2）
+)
+
+// String 返回格式名称
+func (f Format) String() string {
+	switch f {
+	case FormatCSV:
+		return "csv"
+	case FormatArrow:
+		return "arrow"
+	default:
+		return fmt.Sprintf("Format(%d)", int(f))
+	}
+}
+
+// ColumnType 列类型
+type ColumnType int
+
+const (
+	Int32     ColumnType = 0 // 32位整数
+	Int64     ColumnType = 1 // 64位整数
+	Float64   ColumnType = 2 // 双精度浮点数
+	String    ColumnType = 3 // UTF-8字符串
+	Timestamp ColumnType = 4 // UTC毫秒时间戳
+	Decimal   ColumnType = 5 // 十进制数，Arrow中为decimal128(DecimalPrecision, DecimalScale)
+)
+
+// Decimal列在Arrow中的精度和小数位数，小数位超过DecimalScale或整数部分超过20位的值写入时报错
+const (
+	DecimalPrecision = 38
+	DecimalScale     = 18
+)
+
+// Column 列定义
+type Column struct {
+	Name string
+	Type ColumnType
+}
+
+// Value 单元格值，Raw为CSV中输出的原始文本
+type Value struct {
+	Int     int64
+	Float   float64
+	Decimal hotcoin.Decimal
+	Raw     string
+	Null    bool
+}
+
+// DefaultBatchSize Arrow格式每个记录批次的默认行数
+const DefaultBatchSize = 65536
+
+// encoder 格式编码器
+type encoder interface {
+	writeRows(rows [][]Value) error
+	close() error
+}
+
+// Writer 流式写入器，Arrow格式按批次写入，Close时写入文件尾
+type Writer[T any] struct {
+	encoder   encoder
+	extract   func(T) []Value
+	rows      [][]Value
+	batchSize int
+	closed    bool
+}
+
+// NewWriter 创建自定义表结构的流式写入器
+func NewWriter[T any](w io.Writer, format Format, schema []Column, extract func(T) []Value) (*Writer[T], error) {
+	var enc encoder
+	switch format {
+	case FormatCSV:
+		enc = newCSVEncoder(w, schema)
+	case FormatArrow:
+		enc = newArrowEncoder(w, schema)
+	default:
+		return nil, fmt.Errorf("unsupported export format %s", format)
+	}
+	return &Writer[T]{encoder: enc, extract: extract, batchSize: DefaultBatchSize}, nil
+}
+
+// SetBatchSize 设置Arrow记录批次的行数
+func (w *Writer[T]) SetBatchSize(size int) {
+	if size > 0 {
+		w.batchSize = size
+	}
+}
+
+// Write 写入数据，缓存达到批次大小时自动写出
+func (w *Writer[T]) Write(items ...T) error {
+	if w.closed {
+		return fmt.Errorf("writer closed")
+	}
+	for _, item := range items {
+		w.rows = append(w.rows, w.extract(item))
+		if len(w.rows) >= w.batchSize {
+			if err := w.Flush(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Flush 写出缓存的数据
+func (w *Writer[T]) Flush() error {
+	if len(w.rows) == 0 {
+		return nil
+	}
+	rows := w.rows
+	w.rows = nil
+	return w.encoder.writeRows(rows)
+}
+
+// Close 写出剩余数据并结束文件，不关闭底层io.Writer
+func (w *Writer[T]) Close() error {
+	if w.closed {
+		return nil
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	w.closed = true
+	return w.encoder.close()
+}
+
+// writeAll 批量写入并结束文件
+func writeAll[T any](w *Writer[T], err error, items []T) error {
+	if err != nil {
+		return err
+	}
+	if err := w.Write(items...); err != nil {
+		return err
+	}
+	return w.Close()
+}
+
+// KlineSchema K线表结构
+var KlineSchema = []Column{
+	{"timestamp", Timestamp},
+	{"open", Decimal},
+	{"high", Decimal},
+	{"low", Decimal},
+	{"close", Decimal},
+	{"volume", Decimal},
+}
+
+// NewKlineWriter 创建K线流式写入器
+func NewKlineWriter(w io.Writer, format Format) (*Writer[hotcoin.KlineData], error) {
+	return NewWriter(w, format, KlineSchema, func(k hotcoin.KlineData) []Value {
+		return []Value{
+			TimeValue(k.Timestamp),
+			DecimalValue(k.Open),
+			DecimalValue(k.High),
+			DecimalValue(k.Low),
+			DecimalValue(k.Close),
+			DecimalValue(k.Volume),
+		}
+	})
+}
+
+// WriteKlines 批量导出K线
+func WriteKlines(w io.Writer, format Format, klines []hotcoin.KlineData) error {
+	writer, err := NewKlineWriter(w, format)
+	return writeAll(writer, err, klines)
+}
+
+// TradeSchema 成交记录表结构
+var TradeSchema = []Column{
+	{"id", Int64},
+	{"timestamp", Timestamp},
+	{"price", Decimal},
+	{"amount", Decimal},
+	{"side", String},
+}
+
+// NewTradeWriter 创建成交记录流式写入器
+func NewTradeWriter(w io.Writer, format Format) (*Writer[hotcoin.TradeData], error) {
+	return NewWriter(w, format, TradeSchema, func(t hotcoin.TradeData) []Value {
+		return []Value{
+			IntValue(t.ID),
+			TimeValue(t.Timestamp),
+			DecimalValue(t.Price),
+			DecimalValue(t.Amount),
+			StringValue(t.Side),
+		}
+	})
+}
+
+// WriteTrades 批量导出成交记录
+func WriteTrades(w io.Writer, format Format, trades []hotcoin.TradeData) error {
+	writer, err := NewTradeWriter(w, format)
+	return writeAll(writer, err, trades)
+}
+
+// FundingRateSchema 资金费率表结构
+var FundingRateSchema = []Column{
+	{"symbol", String},
+	{"funding_time", Timestamp},
+	{"funding_rate", Decimal},
+	{"mark_price", Decimal},
+	{"index_price", Decimal},
+}
+
+// NewFundingRateWriter 创建资金费率流式写入器
+func NewFundingRateWriter(w io.Writer, format Format) (*Writer[hotcoin.FundingRate], error) {
+	return NewWriter(w, format, FundingRateSchema, func(f hotcoin.FundingRate) []Value {
+		return []Value{
+			StringValue(f.Symbol),
+			TimeValue(f.FundingTime.UnixMilli()),
+			DecimalValue(f.FundingRate),
+			DecimalValue(f.MarkPrice),
+			DecimalValue(f.IndexPrice),
+		}
+	})
+}
+
+// WriteFundingRates 批量导出资金费率
+func WriteFundingRates(w io.Writer, format Format, rates []hotcoin.FundingRate) error {
+	writer, err := NewFundingRateWriter(w, format)
+	return writeAll(writer, err, rates)
+}
+
+// FinancialRecordSchema 财务记录表结构
+var FinancialRecordSchema = []Column{
+	{"id", Int64},
+	{"timestamp", Timestamp},
+	{"type", Int32},
+	{"amount", Decimal},
+	{"symbol", String},
+	{"contract_code", String},
+}
+
+// NewFinancialRecordWriter 创建财务记录流式写入器
+func NewFinancialRecordWriter(w io.Writer, format Format) (*Writer[hotcoin.FinancialRecord], error) {
+	return NewWriter(w, format, FinancialRecordSchema, func(r hotcoin.FinancialRecord) []Value {
+		return []Value{
+			IntValue(r.ID),
+			TimeValue(r.Ts.UnixMilli()),
+			IntValue(int64(r.Type)),
+			DecimalValue(r.Amount),
+			StringValue(r.Symbol),
+			StringValue(r.ContractCode),
+		}
+	})
+}
+
+// WriteFinancialRecords 批量导出财务记录
+func WriteFinancialRecords(w io.Writer, format Format, records []hotcoin.FinancialRecord) error {
+	writer, err := NewFinancialRecordWriter(w, format)
+	return writeAll(writer, err, records)
+}
+
+// IntValue 整数值
+func IntValue(v int64) Value {
+	return Value{Int: v, Raw: strconv.FormatInt(v, 10)}
+}
+
+// DecimalValue 由十进制字符串创建十进制值，空字符串或无法解析时为空值，CSV中保留原始文本
+func DecimalValue(s string) Value {
+	s = strings.TrimSpace(s)
+	d, err := hotcoin.NewDecimalFromString(s)
+	if err != nil {
+		return Value{Null: true}
+	}
+	return Value{Decimal: d, Raw: s}
+}
+
+// FloatValue 由十进制字符串创建浮点值，空字符串或无法解析时为空值，CSV中保留原始文本
+// Arrow中按float64写入会丢失精度，价格和数量应使用DecimalValue
+func FloatValue(s string) Value {
+	s = strings.TrimSpace(s)
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return Value{Null: true}
+	}
+	return Value{Float: f, Raw: s}
+}
+
+// StringValue 字符串值
+func StringValue(s string) Value {
+	return Value{Raw: s}
+}
+
+// TimeValue 由秒或毫秒时间戳创建时间值，0为空值
+func TimeValue(ts int64) Value {
+	if ts == 0 {
+		return Value{Null: true}
+	}
+	t := hotcoin.KlineTime(ts)
+	return Value{Int: t.UnixMilli(), Raw: t.Format("2006-01-02T15:04:05.000Z")}
+}
@@ -0,0 +1,249 @@
+package export
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math/big"
+	"strconv"
+	"strings"
+	"testing"
+
+	hotcoin "github.com/kivenman/hotcoin-go-sdk"
+)
+
+var testKlines = []hotcoin.KlineData{
+	{Timestamp: 1700000000, Open: "36990.0", High: "37005.0", Low: "36985.5", Close: "37000.5", Volume: "1520"},
+	{Timestamp: 1700000060, Open: "37000.5", High: "37010", Low: "36999", Close: "37008", Volume: ""},
+	{Timestamp: 1700000120, Open: "37008", High: "37008", Low: "37001", Close: "37002.25", Volume: "12.5"},
+}
+
+func TestWriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteKlines(&buf, FormatCSV, testKlines[:2]); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	want := "timestamp,open,high,low,close,volume\n" +
+		"2023-11-14T22:13:20.000Z,36990.0,37005.0,36985.5,37000.5,1520\n" +
+		"2023-11-14T22:14:20.000Z,37000.5,37010,36999,37008,\n"
+	if buf.String() != want {
+		t.Errorf("unexpected csv:\n%s", buf.String())
+	}
+}
+
+func TestWriteArrow(t *testing.T) {
+	var buf bytes.Buffer
+	writer, err := NewKlineWriter(&buf, FormatArrow)
+	if err != nil {
+		t.Fatalf("new writer: %v", err)
+	}
+	writer.SetBatchSize(2)
+	if err := writer.Write(testKlines...); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	file := buf.Bytes()
+	if !bytes.HasPrefix(file, []byte("ARROW1\x00\x00")) || !bytes.HasSuffix(file, []byte("ARROW1")) {
+		t.Fatal("missing arrow magic")
+	}
+	footerSize := int(binary.LittleEndian.Uint32(file[len(file)-10:]))
+	footer := fbRoot(file[len(file)-10-footerSize : len(file)-10])
+
+	// 文件尾中的Schema
+	fields := footer.table(1).vector(1)
+	if len(fields) != len(KlineSchema) {
+		t.Fatalf("got %d fields, want %d", len(fields), len(KlineSchema))
+	}
+	for i, field := range fields {
+		if name := field.string(0); name != KlineSchema[i].Name {
+			t.Errorf("field %d name = %q", i, name)
+		}
+	}
+	if typeID := fields[0].uint8(2); typeID != arrowTypeTimestamp {
+		t.Errorf("timestamp type = %d", typeID)
+	}
+	if typeID := fields[1].uint8(2); typeID != arrowTypeDecimal {
+		t.Errorf("open type = %d", typeID)
+	}
+
+	// 两个记录批次：2行和1行
+	blocks := footer.structs(3, 24)
+	if len(blocks) != 2 {
+		t.Fatalf("got %d blocks, want 2", len(blocks))
+	}
+	var timestamps []int64
+	var volumes []string
+	var volumeValid []bool
+	for _, block := range blocks {
+		offset := int(binary.LittleEndian.Uint64(block))
+		metaLength := int(binary.LittleEndian.Uint32(block[8:]))
+		bodyLength := int(binary.LittleEndian.Uint64(block[16:]))
+		if offset%8 != 0 || binary.LittleEndian.Uint32(file[offset:]) != arrowContinuation {
+			t.Fatalf("invalid block offset %d", offset)
+		}
+
+		message := fbRoot(file[offset+8 : offset+metaLength])
+		if message.uint8(1) != arrowHeaderBatch {
+			t.Fatalf("unexpected header type %d", message.uint8(1))
+		}
+		batch := message.table(2)
+		rows := int(batch.uint64(0))
+		nodes := batch.structs(1, 16)
+		buffers := batch.structs(2, 16)
+		body := file[offset+metaLength : offset+metaLength+bodyLength]
+		buffer := func(i int) []byte {
+			start := binary.LittleEndian.Uint64(buffers[i])
+			return body[start : start+binary.LittleEndian.Uint64(buffers[i][8:])]
+		}
+
+		for i := 0; i < rows; i++ {
+			timestamps = append(timestamps, int64(binary.LittleEndian.Uint64(buffer(1)[8*i:])))
+			volumes = append(volumes, decodeDecimal128(buffer(11)[16*i:]))
+			valid := true
+			if nulls := binary.LittleEndian.Uint64(nodes[5][8:]); nulls > 0 {
+				valid = buffer(10)[i/8]&(1<<(i%8)) != 0
+			}
+			volumeValid = append(volumeValid, valid)
+		}
+	}
+
+	if len(timestamps) != 3 || timestamps[0] != 1700000000000 || timestamps[2] != 1700000120000 {
+		t.Errorf("unexpected timestamps: %v", timestamps)
+	}
+	if volumes[0] != "1520" || volumes[2] != "12.5" || !volumeValid[0] || volumeValid[1] {
+		t.Errorf("unexpected volumes: %v %v", volumes, volumeValid)
+	}
+}
+
+func TestWriteTradesStrings(t *testing.T) {
+	var buf bytes.Buffer
+	err := WriteTrades(&buf, FormatCSV, []hotcoin.TradeData{{ID: 7, Price: "37000.123456789012345678", Amount: "2", Side: "buy", Timestamp: 1700000000123}})
+	if err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if !strings.Contains(buf.String(), "7,2023-11-14T22:13:20.123Z,37000.123456789012345678,2,buy") {
+		t.Errorf("unexpected csv: %s", buf.String())
+	}
+
+	buf.Reset()
+	if err := WriteTrades(&buf, FormatArrow, nil); err != nil {
+		t.Fatalf("write empty arrow: %v", err)
+	}
+	if !bytes.HasSuffix(buf.Bytes(), []byte("ARROW1")) {
+		t.Error("empty arrow file not terminated")
+	}
+}
+
+func TestDecimal128(t *testing.T) {
+	tests := []string{"0", "37000.5", "-0.000000000000000001", "-12.5", "99999999999999999999.999999999999999999"}
+	for _, tt := range tests {
+		values, err := appendDecimal128(nil, DecimalValue(tt))
+		if err != nil {
+			t.Errorf("%s: %v", tt, err)
+			continue
+		}
+		if got := decodeDecimal128(values); !hotcoin.MustDecimal(got).Equal(hotcoin.MustDecimal(tt)) {
+			t.Errorf("%s: decoded %s", tt, got)
+		}
+	}
+
+	// 超出小数位或精度时报错，不静默舍入
+	for _, tt := range []string{"0.0000000000000000001", "100000000000000000000"} {
+		if _, err := appendDecimal128(nil, DecimalValue(tt)); err == nil {
+			t.Errorf("%s: expected error", tt)
+		}
+	}
+	var buf bytes.Buffer
+	err := WriteTrades(&buf, FormatArrow, []hotcoin.TradeData{{ID: 1, Price: "0.0000000000000000001", Amount: "1"}})
+	if err == nil || !strings.Contains(err.Error(), "column price") {
+		t.Errorf("expected price column error, got %v", err)
+	}
+}
+
+// decodeDecimal128 将128位小端序补码整数按DecimalScale还原为十进制字符串
+func decodeDecimal128(data []byte) string {
+	word := make([]byte, 16)
+	for i := range word {
+		word[i] = data[15-i]
+	}
+	unscaled := new(big.Int).SetBytes(word)
+	if word[0]&0x80 != 0 {
+		unscaled.Sub(unscaled, decimalModulus)
+	}
+	return hotcoin.MustDecimal(unscaled.String() + "e-" + strconv.Itoa(DecimalScale)).Normalize().String()
+}
+
+// fbTableReader 测试用的flatbuffer表读取
+type fbTableReader struct {
+	buf []byte
+	pos int
+}
+
+// fbRoot 读取根表
+func fbRoot(buf []byte) fbTableReader {
+	return fbTableReader{buf: buf, pos: int(binary.LittleEndian.Uint32(buf))}
+}
+
+// field 获取字段位置，不存在时返回0
+func (t fbTableReader) field(slot int) int {
+	vtable := t.pos - int(int32(binary.LittleEndian.Uint32(t.buf[t.pos:])))
+	size := int(binary.LittleEndian.Uint16(t.buf[vtable:]))
+	if 4+2*slot >= size {
+		return 0
+	}
+	offset := int(binary.LittleEndian.Uint16(t.buf[vtable+4+2*slot:]))
+	if offset == 0 {
+		return 0
+	}
+	return t.pos + offset
+}
+
+func (t fbTableReader) uint8(slot int) uint8 {
+	return t.buf[t.field(slot)]
+}
+
+func (t fbTableReader) uint64(slot int) uint64 {
+	return binary.LittleEndian.Uint64(t.buf[t.field(slot):])
+}
+
+func (t fbTableReader) deref(slot int) int {
+	pos := t.field(slot)
+	return pos + int(binary.LittleEndian.Uint32(t.buf[pos:]))
+}
+
+func (t fbTableReader) table(slot int) fbTableReader {
+	return fbTableReader{buf: t.buf, pos: t.deref(slot)}
+}
+
+func (t fbTableReader) string(slot int) string {
+	pos := t.deref(slot)
+	size := int(binary.LittleEndian.Uint32(t.buf[pos:]))
+	return string(t.buf[pos+4 : pos+4+size])
+}
+
+func (t fbTableReader) vector(slot int) []fbTableReader {
+	pos := t.deref(slot)
+	count := int(binary.LittleEndian.Uint32(t.buf[pos:]))
+	tables := make([]fbTableReader, count)
+	for i := range tables {
+		elem := pos + 4 + 4*i
+		tables[i] = fbTableReader{buf: t.buf, pos: elem + int(binary.LittleEndian.Uint32(t.buf[elem:]))}
+	}
+	return tables
+}
+
+func (t fbTableReader) structs(slot, size int) [][]byte {
+	pos := t.deref(slot)
+	count := int(binary.LittleEndian.Uint32(t.buf[pos:]))
+	if (pos+4)%8 != 0 {
+		panic("misaligned struct vector")
+	}
+	items := make([][]byte, count)
+	for i := range items {
+		items[i] = t.buf[pos+4+size*i : pos+4+size*(i+1)]
+	}
+	return items
+}
@@ -0,0 +1,158 @@
+package export
+
+import "encoding/binary"
+
+// fbObject 待序列化的flatbuffer对象：*fbTable、fbString、fbTables、*fbStructs
+type fbObject interface{}
+
+// fbTable flatbuffer表，fields下标即字段槽位
+type fbTable struct {
+	fields []fbField
+}
+
+// fbField 表字段，child非nil时为偏移量字段，否则为size字节的标量
+type fbField struct {
+	set    bool
+	size   int
+	scalar uint64
+	child  fbObject
+}
+
+// fbString flatbuffer字符串
+type fbString string
+
+// fbTables 表向量
+type fbTables []*fbTable
+
+// fbStructs 结构体向量，data为按小端序排列的元素
+type fbStructs struct {
+	align int
+	count int
+	data  []byte
+}
+
+// newFBTable 创建有n个槽位的表
+func newFBTable(n int) *fbTable {
+	return &fbTable{fields: make([]fbField, n)}
+}
+
+// scalar 设置标量字段
+func (t *fbTable) scalar(slot, size int, value uint64) *fbTable {
+	t.fields[slot] = fbField{set: true, size: size, scalar: value}
+	return t
+}
+
+// offset 设置偏移量字段
+func (t *fbTable) offset(slot int, child fbObject) *fbTable {
+	t.fields[slot] = fbField{set: true, size: 4, child: child}
+	return t
+}
+
+// fbWriter 从前向后序列化flatbuffer，子对象总在引用它的字段之后
+type fbWriter struct {
+	buf []byte
+}
+
+// buildFlatbuffer 序列化以root为根表的flatbuffer，长度补齐到8字节
+func buildFlatbuffer(root *fbTable) []byte {
+	w := &fbWriter{buf: make([]byte, 4, 256)}
+	pos := w.write(root)
+	binary.LittleEndian.PutUint32(w.buf, uint32(pos))
+	w.pad(8)
+	return w.buf
+}
+
+// pad 补齐到align字节
+func (w *fbWriter) pad(align int) {
+	for len(w.buf)%align != 0 {
+		w.buf = append(w.buf, 0)
+	}
+}
+
+// grow 追加n个字节并返回起始位置
+func (w *fbWriter) grow(n int) int {
+	pos := len(w.buf)
+	w.buf = append(w.buf, make([]byte, n)...)
+	return pos
+}
+
+// patch 在pos处写入指向target的偏移量
+func (w *fbWriter) patch(pos, target int) {
+	binary.LittleEndian.PutUint32(w.buf[pos:], uint32(target-pos))
+}
+
+// write 序列化对象，返回对象位置
+func (w *fbWriter) write(obj fbObject) int {
+	switch v := obj.(type) {
+	case *fbTable:
+		return w.writeTable(v)
+	case fbString:
+		w.pad(4)
+		pos := w.grow(4)
+		binary.LittleEndian.PutUint32(w.buf[pos:], uint32(len(v)))
+		w.buf = append(w.buf, v...)
+		w.buf = append(w.buf, 0)
+		return pos
+	case fbTables:
+		w.pad(4)
+		pos := w.grow(4 + 4*len(v))
+		binary.LittleEndian.PutUint32(w.buf[pos:], uint32(len(v)))
+		for i, table := range v {
+			w.patch(pos+4+4*i, w.writeTable(table))
+		}
+		return pos
+	case *fbStructs:
+		// 元素需按结构体对齐，长度字段紧邻元素之前
+		for (len(w.buf)+4)%v.align != 0 {
+			w.buf = append(w.buf, 0)
+		}
+		pos := w.grow(4)
+		binary.LittleEndian.PutUint32(w.buf[pos:], uint32(v.count))
+		w.buf = append(w.buf, v.data...)
+		return pos
+	}
+	panic("export: unsupported flatbuffer object")
+}
+
+// writeTable 序列化表：vtable在前，表数据在后，子对象最后
+func (w *fbWriter) writeTable(t *fbTable) int {
+	w.pad(2)
+	vtable := w.grow(4 + 2*len(t.fields))
+
+	w.pad(4)
+	table := w.grow(4)
+	positions := make([]int, len(t.fields))
+	for i, field := range t.fields {
+		if !field.set {
+			continue
+		}
+		w.pad(field.size)
+		positions[i] = w.grow(field.size)
+		switch field.size {
+		case 1:
+			w.buf[positions[i]] = byte(field.scalar)
+		case 2:
+			binary.LittleEndian.PutUint16(w.buf[positions[i]:], uint16(field.scalar))
+		case 4:
+			binary.LittleEndian.PutUint32(w.buf[positions[i]:], uint32(field.scalar))
+		case 8:
+			binary.LittleEndian.PutUint64(w.buf[positions[i]:], field.scalar)
+		}
+	}
+
+	binary.LittleEndian.PutUint16(w.buf[vtable:], uint16(4+2*len(t.fields)))
+	binary.LittleEndian.PutUint16(w.buf[vtable+2:], uint16(len(w.buf)-table))
+	for i, pos := range positions {
+		if pos > 0 {
+			binary.LittleEndian.PutUint16(w.buf[vtable+4+2*i:], uint16(pos-table))
+		}
+	}
+	binary.LittleEndian.PutUint32(w.buf[table:], uint32(table-vtable))
+
+	for i, field := range t.fields {
+		if field.set && field.child != nil {
+			w.patch(positions[i], w.write(field.child))
+		}
+	}
+	return table
+}
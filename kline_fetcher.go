@@ -0,0 +1,369 @@
+package hotcoin
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// KlineFetcher 历史K线区间下载器
+// 将[from, to)按单次请求上限切分为多个窗口，限制并发和请求频率下载，
+// 结果按时间戳去重排序并报告缺失的区间
+type KlineFetcher struct {
+	market  *MarketService
+	config  *KlineFetchConfig
+	limiter *rateLimiter
+	fetch   func(ctx context.Context, symbol, period string, from, to int64) ([]KlineData, error) // from/to为秒级时间戳
+}
+
+// klineWindow 单次请求的时间窗口
+type klineWindow struct {
+	from time.Time
+	to   time.Time
+}
+
+// klineWindowResult 窗口下载结果
+type klineWindowResult struct {
+	klines []KlineData
+	err    error
+}
+
+// NewKlineFetcher 创建历史K线下载器
+func NewKlineFetcher(market *MarketService, config *KlineFetchConfig) *KlineFetcher {
+	if config == nil {
+		config = DefaultKlineFetchConfig()
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = DefaultKlineFetchConfig().BatchSize
+	}
+	if config.Concurrency <= 0 {
+		config.Concurrency = 1
+	}
+
+	return &KlineFetcher{
+		market:  market,
+		config:  config,
+		limiter: newRateLimiter(config.RequestInterval),
+		fetch: func(ctx context.Context, symbol, period string, from, to int64) ([]KlineData, error) {
+			return market.getHistoricalKline(ctx, symbol, period, time.Unix(from, 0), time.Unix(to, 0))
+		},
+	}
+}
+
+// Fetch 下载[from, to)区间的K线，返回排序去重后的结果和缺失区间
+func (f *KlineFetcher) Fetch(ctx context.Context, symbol, period string, from, to time.Time) (*KlineRange, error) {
+	iter, err := f.Iterate(ctx, symbol, period, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+
+	result := &KlineRange{Symbol: symbol, Period: period, From: from, To: to}
+	for iter.Next() {
+		result.Klines = append(result.Klines, iter.Kline())
+	}
+	if err := iter.Err(); err != nil {
+		return nil, err
+	}
+	result.Gaps = iter.Gaps()
+	return result, nil
+}
+
+// Iterate 以流式迭代器下载[from, to)区间的K线，按时间顺序返回
+// 窗口并发下载，已下载但未消费的窗口数不超过并发数
+func (f *KlineFetcher) Iterate(ctx context.Context, symbol, period string, from, to time.Time) (*KlineIterator, error) {
+	if symbol == "" {
+		return nil, fmt.Errorf("symbol is required")
+	}
+	if !from.Before(to) {
+		return nil, fmt.Errorf("from must be before to")
+	}
+
+	step, err := KlinePeriodDuration(period)
+	if err != nil {
+		return nil, err
+	}
+
+	windows, err := splitKlineWindows(period, from, to, f.config.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	iter := &KlineIterator{
+		ctx:     ctx,
+		cancel:  cancel,
+		period:  period,
+		step:    step,
+		from:    from,
+		to:      to,
+		results: make([]chan klineWindowResult, len(windows)),
+		slots:   make(chan struct{}, f.config.Concurrency),
+	}
+	for i := range iter.results {
+		iter.results[i] = make(chan klineWindowResult, 1)
+	}
+
+	go f.dispatch(ctx, symbol, period, windows, iter)
+	return iter, nil
+}
+
+// dispatch 按顺序派发窗口下载任务
+func (f *KlineFetcher) dispatch(ctx context.Context, symbol, period string, windows []klineWindow, iter *KlineIterator) {
+	for i, window := range windows {
+		select {
+		case <-ctx.Done():
+			return
+		case iter.slots <- struct{}{}:
+		}
+
+		go func(i int, window klineWindow) {
+			klines, err := f.fetchWindow(ctx, symbol, period, window)
+			iter.results[i] <- klineWindowResult{klines: klines, err: err}
+		}(i, window)
+	}
+}
+
+// fetchWindow 下载单个窗口，失败时按配置重试
+func (f *KlineFetcher) fetchWindow(ctx context.Context, symbol, period string, window klineWindow) ([]KlineData, error) {
+	var lastErr error
+	for attempt := 0; attempt <= f.config.MaxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(f.config.RetryBackoff * time.Duration(attempt)):
+			}
+		}
+		if err := f.limiter.wait(ctx); err != nil {
+			return nil, err
+		}
+
+		klines, err := f.fetch(ctx, symbol, period, window.from.Unix(), window.to.Unix())
+		if err == nil {
+			return klines, nil
+		}
+		lastErr = err
+	}
+	return nil, fmt.Errorf("fetch klines %s-%s: %w", window.from.Format(time.RFC3339), window.to.Format(time.RFC3339), lastErr)
+}
+
+// splitKlineWindows 将区间切分为每个不超过batchSize根K线的窗口
+func splitKlineWindows(period string, from, to time.Time, batchSize int) ([]klineWindow, error) {
+	var windows []klineWindow
+	for start := from; start.Before(to); {
+		end, err := KlinePeriodAdd(start, period, batchSize)
+		if err != nil {
+			return nil, err
+		}
+		if end.After(to) {
+			end = to
+		}
+		windows = append(windows, klineWindow{from: start, to: end})
+		start = end
+	}
+	return windows, nil
+}
+
+// KlineIterator 历史K线流式迭代器
+//
+//	iter, _ := fetcher.Iterate(ctx, "btcusdt", "1min", from, to)
+//	defer iter.Close()
+//	for iter.Next() {
+//		kline := iter.Kline()
+//	}
+//	if err := iter.Err(); err != nil {}
+type KlineIterator struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	period string
+	step   time.Duration
+	from   time.Time
+	to     time.Time
+
+	results []chan klineWindowResult
+	slots   chan struct{}
+	window  int
+
+	buffer  []KlineData
+	current KlineData
+	lastTs  int64
+	started bool
+	gaps    []KlineGap
+	err     error
+	once    sync.Once
+}
+
+// Next 移动到下一根K线，没有更多数据或出错时返回false
+func (it *KlineIterator) Next() bool {
+	for len(it.buffer) == 0 {
+		if it.err != nil || it.window >= len(it.results) {
+			it.finish()
+			return false
+		}
+
+		var result klineWindowResult
+		select {
+		case <-it.ctx.Done():
+			it.err = it.ctx.Err()
+			return false
+		case result = <-it.results[it.window]:
+		}
+		it.window++
+		<-it.slots
+
+		if result.err != nil {
+			it.err = result.err
+			it.cancel()
+			return false
+		}
+		it.buffer = it.prepare(result.klines)
+	}
+
+	it.current = it.buffer[0]
+	it.buffer = it.buffer[1:]
+	it.recordGap(KlineTime(it.current.Timestamp))
+	it.lastTs = it.current.Timestamp
+	it.started = true
+	return true
+}
+
+// Kline 获取当前K线
+func (it *KlineIterator) Kline() KlineData {
+	return it.current
+}
+
+// Err 获取迭代过程中的错误
+func (it *KlineIterator) Err() error {
+	return it.err
+}
+
+// Gaps 获取已发现的缺失区间，迭代结束后包含区间末尾的缺失
+func (it *KlineIterator) Gaps() []KlineGap {
+	return it.gaps
+}
+
+// Close 停止下载
+func (it *KlineIterator) Close() {
+	it.cancel()
+}
+
+// prepare 排序、去重并过滤区间外的K线
+func (it *KlineIterator) prepare(klines []KlineData) []KlineData {
+	sort.Slice(klines, func(i, j int) bool { return klines[i].Timestamp < klines[j].Timestamp })
+
+	result := klines[:0]
+	for _, kline := range klines {
+		t := KlineTime(kline.Timestamp)
+		if t.Before(it.from) || !t.Before(it.to) {
+			continue
+		}
+		if it.started && kline.Timestamp <= it.lastTs {
+			continue
+		}
+		if len(result) > 0 && result[len(result)-1].Timestamp == kline.Timestamp {
+			continue
+		}
+		result = append(result, kline)
+	}
+	return result
+}
+
+// recordGap 记录上一根K线与t之间的缺失区间
+func (it *KlineIterator) recordGap(t time.Time) {
+	expected := it.from
+	if it.started {
+		next, _ := KlinePeriodAdd(KlineTime(it.lastTs), it.period, 1)
+		expected = next
+	} else if start, err := KlinePeriodStart(it.from, it.period); err == nil && start.Before(it.from) {
+		expected, _ = KlinePeriodAdd(start, it.period, 1)
+	}
+
+	if t.After(expected) {
+		it.gaps = append(it.gaps, KlineGap{
+			From:    expected,
+			To:      t,
+			Missing: countPeriods(it.period, it.step, expected, t),
+		})
+	}
+}
+
+// finish 迭代正常结束时记录区间末尾的缺失
+func (it *KlineIterator) finish() {
+	it.once.Do(func() {
+		if it.err != nil {
+			return
+		}
+		expected := it.from
+		if it.started {
+			expected, _ = KlinePeriodAdd(KlineTime(it.lastTs), it.period, 1)
+		}
+		if expected.Before(it.to) {
+			it.gaps = append(it.gaps, KlineGap{
+				From:    expected,
+				To:      it.to,
+				Missing: countPeriods(it.period, it.step, expected, it.to),
+			})
+		}
+	})
+}
+
+// countPeriods 计算[from, to)中的K线周期数
+func countPeriods(period string, step time.Duration, from, to time.Time) int {
+	if period == "1mon" {
+		months := (to.Year()-from.Year())*12 + int(to.Month()-from.Month())
+		if months < 1 {
+			months = 1
+		}
+		return months
+	}
+	n := int((to.Sub(from) + step - 1) / step)
+	if n < 1 {
+		n = 1
+	}
+	return n
+}
+
+// rateLimiter 请求间隔限制
+type rateLimiter struct {
+	mutex    sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+// newRateLimiter 创建请求间隔限制，interval<=0时不限制
+func newRateLimiter(interval time.Duration) *rateLimiter {
+	return &rateLimiter{interval: interval}
+}
+
+// wait 等待直到允许发送下一个请求
+func (l *rateLimiter) wait(ctx context.Context) error {
+	if l.interval <= 0 {
+		return ctx.Err()
+	}
+
+	l.mutex.Lock()
+	now := time.Now()
+	at := l.next
+	if at.Before(now) {
+		at = now
+	}
+	l.next = at.Add(l.interval)
+	l.mutex.Unlock()
+
+	delay := time.Until(at)
+	if delay <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,145 @@
+package hotcoin
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeKlineFetch 模拟历史K线接口，返回[from, to]内（含to，模拟窗口边界重叠）的1min K线，
+// 跳过missing中的时间，结果倒序
+func fakeKlineFetch(missing map[int64]bool, windows *[][2]int64, mutex *sync.Mutex) func(ctx context.Context, symbol, period string, from, to int64) ([]KlineData, error) {
+	return func(ctx context.Context, symbol, period string, from, to int64) ([]KlineData, error) {
+		mutex.Lock()
+		*windows = append(*windows, [2]int64{from, to})
+		mutex.Unlock()
+
+		var klines []KlineData
+		for ts := to; ts >= from; ts -= 60 {
+			if !missing[ts] {
+				klines = append(klines, KlineData{Timestamp: ts, Close: "1"})
+			}
+		}
+		return klines, nil
+	}
+}
+
+func TestKlineFetcherPagination(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(10 * time.Minute)
+	minute := func(n int) int64 { return from.Add(time.Duration(n) * time.Minute).Unix() }
+
+	var mutex sync.Mutex
+	var windows [][2]int64
+	fetcher := NewKlineFetcher(nil, &KlineFetchConfig{BatchSize: 3, Concurrency: 2})
+	fetcher.fetch = fakeKlineFetch(map[int64]bool{minute(4): true, minute(9): true}, &windows, &mutex)
+
+	result, err := fetcher.Fetch(context.Background(), "btcusdt", "1min", from, to)
+	if err != nil {
+		t.Fatalf("fetch: %v", err)
+	}
+
+	sort.Slice(windows, func(i, j int) bool { return windows[i][0] < windows[j][0] })
+	wantWindows := [][2]int64{{minute(0), minute(3)}, {minute(3), minute(6)}, {minute(6), minute(9)}, {minute(9), minute(10)}}
+	if !reflect.DeepEqual(windows, wantWindows) {
+		t.Errorf("windows = %v, want %v", windows, wantWindows)
+	}
+
+	// 窗口边界上的K线只保留一次，区间外的K线被过滤
+	var got []int64
+	for _, kline := range result.Klines {
+		got = append(got, kline.Timestamp)
+	}
+	want := []int64{minute(0), minute(1), minute(2), minute(3), minute(5), minute(6), minute(7), minute(8)}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("klines = %v, want %v", got, want)
+	}
+
+	wantGaps := []KlineGap{
+		{From: KlineTime(minute(4)), To: KlineTime(minute(5)), Missing: 1},
+		{From: KlineTime(minute(9)), To: to, Missing: 1},
+	}
+	if !reflect.DeepEqual(result.Gaps, wantGaps) {
+		t.Errorf("gaps = %+v, want %+v", result.Gaps, wantGaps)
+	}
+}
+
+func TestKlineFetcherRetry(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(5 * time.Minute)
+
+	calls, failures := 0, 1
+	failure := errors.New("rate limited")
+	fetcher := NewKlineFetcher(nil, &KlineFetchConfig{BatchSize: 10, MaxRetries: 1, RetryBackoff: time.Millisecond})
+	fetcher.fetch = func(ctx context.Context, symbol, period string, from, to int64) ([]KlineData, error) {
+		calls++
+		if failures > 0 {
+			failures--
+			return nil, failure
+		}
+		return []KlineData{{Timestamp: from}}, nil
+	}
+
+	result, err := fetcher.Fetch(context.Background(), "btcusdt", "1min", from, to)
+	if err != nil || len(result.Klines) != 1 || calls != 2 {
+		t.Fatalf("fetch with retry: %d klines, %d calls, %v", len(result.Klines), calls, err)
+	}
+
+	// 重试次数用尽后返回最后一次错误
+	failures = 2
+	if _, err := fetcher.Fetch(context.Background(), "btcusdt", "1min", from, to); !errors.Is(err, failure) {
+		t.Errorf("expected %v, got %v", failure, err)
+	}
+}
+
+func TestKlineFetcherInvalidRange(t *testing.T) {
+	fetcher := NewKlineFetcher(nil, nil)
+	now := time.Now()
+
+	if _, err := fetcher.Fetch(context.Background(), "btcusdt", "1min", now, now); err == nil {
+		t.Error("expected empty range error")
+	}
+	if _, err := fetcher.Fetch(context.Background(), "btcusdt", "2min", now, now.Add(time.Hour)); err == nil {
+		t.Error("expected unsupported period error")
+	}
+	if _, err := fetcher.Fetch(context.Background(), "", "1min", now, now.Add(time.Hour)); err == nil {
+		t.Error("expected symbol required error")
+	}
+}
+
+func TestKlineFetcherCancelsRequest(t *testing.T) {
+	release := make(chan struct{})
+	requested := make(chan struct{}, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested <- struct{}{}
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	clientConfig := DefaultConfig()
+	clientConfig.BaseURL = server.URL
+	client := NewClientWithConfig(clientConfig)
+	fetcher := NewKlineFetcher(client.Market, &KlineFetchConfig{BatchSize: 10})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-requested
+		cancel()
+	}()
+
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	start := time.Now()
+	if _, err := fetcher.Fetch(ctx, "btcusdt", "1min", from, from.Add(5*time.Minute)); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context canceled, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("cancel did not reach the request, took %v", elapsed)
+	}
+}
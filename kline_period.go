@@ -0,0 +1,68 @@
+package hotcoin
+
+import (
+	"fmt"
+	"time"
+)
+
+// klinePeriods 支持的K线周期
+var klinePeriods = map[string]time.Duration{
+	"1min":  time.Minute,
+	"5min":  5 * time.Minute,
+	"15min": 15 * time.Minute,
+	"30min": 30 * time.Minute,
+	"1hour": time.Hour,
+	"4hour": 4 * time.Hour,
+	"1day":  24 * time.Hour,
+	"1week": 7 * 24 * time.Hour,
+	"1mon":  30 * 24 * time.Hour,
+}
+
+// KlinePeriodDuration 获取K线周期的时长，1mon按30天近似
+func KlinePeriodDuration(period string) (time.Duration, error) {
+	d, ok := klinePeriods[period]
+	if !ok {
+		return 0, fmt.Errorf("unsupported kline period %q", period)
+	}
+	return d, nil
+}
+
+// KlinePeriodStart 获取t所在K线周期的开始时间（UTC），1week从周一开始，1mon从每月1日开始
+func KlinePeriodStart(t time.Time, period string) (time.Time, error) {
+	d, err := KlinePeriodDuration(period)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	t = t.UTC()
+	switch period {
+	case "1week":
+		day := t.Truncate(24 * time.Hour)
+		offset := (int(day.Weekday()) + 6) % 7
+		return day.AddDate(0, 0, -offset), nil
+	case "1mon":
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC), nil
+	default:
+		return t.Truncate(d), nil
+	}
+}
+
+// KlinePeriodAdd 将t向后移动n个K线周期，1mon按自然月计算
+func KlinePeriodAdd(t time.Time, period string, n int) (time.Time, error) {
+	d, err := KlinePeriodDuration(period)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if period == "1mon" {
+		return t.AddDate(0, n, 0), nil
+	}
+	return t.Add(time.Duration(n) * d), nil
+}
+
+// KlineTime 将K线时间戳转换为时间，自动识别秒和毫秒
+func KlineTime(ts int64) time.Time {
+	if ts > 1e12 || ts < -1e12 {
+		return time.UnixMilli(ts).UTC()
+	}
+	return time.Unix(ts, 0).UTC()
+}
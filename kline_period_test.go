@@ -0,0 +1,80 @@
+package hotcoin
+
+import (
+	"testing"
+	"time"
+)
+
+func TestKlinePeriodStart(t *testing.T) {
+	at := time.Date(2024, 1, 3, 13, 47, 12, 0, time.UTC) // 周三
+	tests := []struct {
+		period string
+		want   time.Time
+	}{
+		{"1min", time.Date(2024, 1, 3, 13, 47, 0, 0, time.UTC)},
+		{"15min", time.Date(2024, 1, 3, 13, 45, 0, 0, time.UTC)},
+		{"4hour", time.Date(2024, 1, 3, 12, 0, 0, 0, time.UTC)},
+		{"1day", time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC)},
+		{"1week", time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"1mon", time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		got, err := KlinePeriodStart(at, tt.period)
+		if err != nil || !got.Equal(tt.want) {
+			t.Errorf("KlinePeriodStart(%s) = %s, %v, want %s", tt.period, got, err, tt.want)
+		}
+	}
+
+	// 周日属于上一周
+	sunday := time.Date(2024, 1, 7, 23, 0, 0, 0, time.UTC)
+	if got, _ := KlinePeriodStart(sunday, "1week"); !got.Equal(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("week start of sunday = %s", got)
+	}
+	if _, err := KlinePeriodStart(at, "2min"); err == nil {
+		t.Error("expected unsupported period error")
+	}
+}
+
+func TestKlinePeriodAdd(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		period string
+		n      int
+		want   time.Time
+	}{
+		{"5min", 3, start.Add(15 * time.Minute)},
+		{"1hour", -2, start.Add(-2 * time.Hour)},
+		{"1week", 1, start.AddDate(0, 0, 7)},
+		{"1mon", 2, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{"1mon", -1, time.Date(2023, 12, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		got, err := KlinePeriodAdd(start, tt.period, tt.n)
+		if err != nil || !got.Equal(tt.want) {
+			t.Errorf("KlinePeriodAdd(%s, %d) = %s, %v, want %s", tt.period, tt.n, got, err, tt.want)
+		}
+	}
+	if _, err := KlinePeriodDuration("1year"); err == nil {
+		t.Error("expected unsupported period error")
+	}
+}
+
+func TestKlineTime(t *testing.T) {
+	want := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	if got := KlineTime(want.Unix()); !got.Equal(want) {
+		t.Errorf("seconds: %s", got)
+	}
+	if got := KlineTime(want.UnixMilli()); !got.Equal(want) {
+		t.Errorf("milliseconds: %s", got)
+	}
+}
+
+func TestCountPeriods(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	if n := countPeriods("1min", time.Minute, from, from.Add(150*time.Second)); n != 3 {
+		t.Errorf("1min periods = %d, want 3", n)
+	}
+	if n := countPeriods("1mon", 30*24*time.Hour, from, time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)); n != 3 {
+		t.Errorf("1mon periods = %d, want 3", n)
+	}
+}
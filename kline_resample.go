@@ -0,0 +1,311 @@
+package hotcoin
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// intervalPattern 自定义K线周期格式，如3min、2hour、1day、2week
+var intervalPattern = regexp.MustCompile(`^(\d+)(min|hour|day|week)$`)
+
+// ParseKlineInterval 解析K线周期，支持3min、2hour、1day、2week等格式及time.ParseDuration格式
+func ParseKlineInterval(interval string) (time.Duration, error) {
+	if m := intervalPattern.FindStringSubmatch(interval); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		if n <= 0 {
+			return 0, fmt.Errorf("invalid kline interval %q", interval)
+		}
+		unit := map[string]time.Duration{
+			"min":  time.Minute,
+			"hour": time.Hour,
+			"day":  24 * time.Hour,
+			"week": 7 * 24 * time.Hour,
+		}[m[2]]
+		return time.Duration(n) * unit, nil
+	}
+
+	d, err := time.ParseDuration(interval)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid kline interval %q", interval)
+	}
+	return d, nil
+}
+
+// FixedBuckets 按固定时长划分K线区间，origin为对齐基准
+func FixedBuckets(interval time.Duration, origin time.Time) KlineBucketFunc {
+	return func(t time.Time) (time.Time, time.Time) {
+		offset := t.Sub(origin)
+		n := offset / interval
+		if offset < 0 && offset%interval != 0 {
+			n--
+		}
+		start := origin.Add(n * interval)
+		return start, start.Add(interval)
+	}
+}
+
+// DailySessions 按交易时段划分日K线，每日从loc时区的offset时刻开始
+// 例如DailySessions(shanghai, 8*time.Hour)表示每个交易日从北京时间08:00开始
+func DailySessions(loc *time.Location, offset time.Duration) KlineBucketFunc {
+	return func(t time.Time) (time.Time, time.Time) {
+		local := t.In(loc).Add(-offset)
+		day := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+		start := day.Add(offset)
+		return start, day.AddDate(0, 0, 1).Add(offset)
+	}
+}
+
+// KlineResampler K线重采样，将较细周期的K线合并为任意周期
+type KlineResampler struct {
+	config *ResampleConfig
+}
+
+// NewKlineResampler 创建K线重采样器
+func NewKlineResampler(config *ResampleConfig) (*KlineResampler, error) {
+	if config == nil {
+		return nil, fmt.Errorf("config is required")
+	}
+	cfg := *config
+	config = &cfg
+	if config.Buckets == nil {
+		if config.Interval <= 0 {
+			return nil, fmt.Errorf("interval or buckets is required")
+		}
+		origin := config.Origin
+		if origin.IsZero() {
+			origin = time.Unix(0, 0).UTC()
+		}
+		config.Buckets = FixedBuckets(config.Interval, origin)
+	}
+	if config.SourcePeriod > 0 && config.Interval > 0 && config.Interval%config.SourcePeriod != 0 {
+		return nil, fmt.Errorf("interval %s is not a multiple of source period %s", config.Interval, config.SourcePeriod)
+	}
+	return &KlineResampler{config: config}, nil
+}
+
+// ResampleKlines 按周期字符串重采样，如ResampleKlines(klines, "3min", FillFlat)
+func ResampleKlines(klines []KlineData, interval string, fill KlineFillMode) ([]ResampledKline, error) {
+	d, err := ParseKlineInterval(interval)
+	if err != nil {
+		return nil, err
+	}
+	resampler, err := NewKlineResampler(&ResampleConfig{Interval: d, Fill: fill, Validate: true})
+	if err != nil {
+		return nil, err
+	}
+	return resampler.Resample(klines)
+}
+
+// Resample 重采样K线，输入无需有序，重复时间戳只保留最后一根
+// 输出时间戳单位与输入一致（秒或毫秒）
+func (r *KlineResampler) Resample(klines []KlineData) ([]ResampledKline, error) {
+	if len(klines) == 0 {
+		return nil, nil
+	}
+
+	sorted := make([]KlineData, len(klines))
+	copy(sorted, klines)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Timestamp < sorted[j].Timestamp })
+
+	millis := sorted[0].Timestamp > 1e12
+	var result []ResampledKline
+	var current *klineAccumulator
+	var lastClose Decimal
+
+	for i, kline := range sorted {
+		if i+1 < len(sorted) && sorted[i+1].Timestamp == kline.Timestamp {
+			continue
+		}
+
+		parsed, err := parseKline(kline)
+		if err != nil {
+			return nil, fmt.Errorf("kline %d: %w", kline.Timestamp, err)
+		}
+		if r.config.Validate {
+			if err := parsed.validate(); err != nil {
+				return nil, fmt.Errorf("kline %d: %w", kline.Timestamp, err)
+			}
+		}
+
+		t := KlineTime(kline.Timestamp)
+		start, end := r.config.Buckets(t)
+		if current != nil && start.Equal(current.start) {
+			current.add(parsed)
+			continue
+		}
+
+		if current != nil {
+			result = append(result, current.result(millis))
+			lastClose = current.close
+			result = append(result, r.fill(current.end, start, lastClose, millis)...)
+		}
+		current = newKlineAccumulator(start, end, parsed)
+	}
+	result = append(result, current.result(millis))
+
+	return result, nil
+}
+
+// fill 生成[from, to)之间缺失区间的K线
+func (r *KlineResampler) fill(from, to time.Time, lastClose Decimal, millis bool) []ResampledKline {
+	if r.config.Fill == FillNone {
+		return nil
+	}
+
+	var result []ResampledKline
+	for start := from; start.Before(to); {
+		bucketStart, end := r.config.Buckets(start)
+		if !end.After(start) {
+			break
+		}
+		if bucketStart.Before(start) {
+			bucketStart = start
+		}
+
+		missing := ResampledKline{
+			KlineData: KlineData{Timestamp: klineTimestamp(bucketStart, millis)},
+			Start:     bucketStart,
+			End:       end,
+			Filled:    true,
+		}
+		if r.config.Fill == FillFlat {
+			price := lastClose.String()
+			missing.Open, missing.High, missing.Low, missing.Close = price, price, price, price
+			missing.Volume = "0"
+		}
+		result = append(result, missing)
+		start = end
+	}
+	return result
+}
+
+// ValidateKline 校验K线OHLC一致性：最高价不低于开盘、收盘、最低价，最低价不高于开盘、收盘价，成交量非负
+func ValidateKline(kline KlineData) error {
+	parsed, err := parseKline(kline)
+	if err != nil {
+		return err
+	}
+	return parsed.validate()
+}
+
+// ValidateKlines 校验K线序列，返回所有问题，包括OHLC不一致和重复或倒序的时间戳
+func ValidateKlines(klines []KlineData) []KlineIssue {
+	var issues []KlineIssue
+	for i, kline := range klines {
+		if err := ValidateKline(kline); err != nil {
+			issues = append(issues, KlineIssue{Index: i, Timestamp: kline.Timestamp, Err: err})
+		}
+		if i > 0 && kline.Timestamp <= klines[i-1].Timestamp {
+			issues = append(issues, KlineIssue{
+				Index:     i,
+				Timestamp: kline.Timestamp,
+				Err:       fmt.Errorf("timestamp not increasing: %d after %d", kline.Timestamp, klines[i-1].Timestamp),
+			})
+		}
+	}
+	return issues
+}
+
+// parsedKline 精确数值表示的K线
+type parsedKline struct {
+	open, high, low, close, volume Decimal
+}
+
+// parseKline 解析K线价格和成交量
+func parseKline(kline KlineData) (parsedKline, error) {
+	var parsed parsedKline
+	fields := []struct {
+		name  string
+		value string
+		dst   *Decimal
+	}{
+		{"open", kline.Open, &parsed.open},
+		{"high", kline.High, &parsed.high},
+		{"low", kline.Low, &parsed.low},
+		{"close", kline.Close, &parsed.close},
+		{"volume", kline.Volume, &parsed.volume},
+	}
+	for _, field := range fields {
+		if field.name == "volume" && field.value == "" {
+			continue
+		}
+		d, err := NewDecimalFromString(field.value)
+		if err != nil {
+			return parsed, fmt.Errorf("%s: %w", field.name, err)
+		}
+		*field.dst = d
+	}
+	return parsed, nil
+}
+
+// validate 校验OHLC一致性
+func (k parsedKline) validate() error {
+	if k.high.LessThan(k.open.Max(k.close).Max(k.low)) {
+		return fmt.Errorf("high %s below open/close/low", k.high)
+	}
+	if k.low.GreaterThan(k.open.Min(k.close)) {
+		return fmt.Errorf("low %s above open/close", k.low)
+	}
+	if k.volume.Sign() < 0 {
+		return fmt.Errorf("negative volume %s", k.volume)
+	}
+	return nil
+}
+
+// klineAccumulator 单个区间的K线合并
+type klineAccumulator struct {
+	start, end                     time.Time
+	open, high, low, close, volume Decimal
+	count                          int
+}
+
+// newKlineAccumulator 以第一根K线开始新区间
+func newKlineAccumulator(start, end time.Time, k parsedKline) *klineAccumulator {
+	return &klineAccumulator{
+		start:  start,
+		end:    end,
+		open:   k.open,
+		high:   k.high,
+		low:    k.low,
+		close:  k.close,
+		volume: k.volume,
+		count:  1,
+	}
+}
+
+// add 合并一根K线
+func (a *klineAccumulator) add(k parsedKline) {
+	a.high = a.high.Max(k.high)
+	a.low = a.low.Min(k.low)
+	a.close = k.close
+	a.volume = a.volume.Add(k.volume)
+	a.count++
+}
+
+// result 输出合并后的K线
+func (a *klineAccumulator) result(millis bool) ResampledKline {
+	return ResampledKline{
+		KlineData: KlineData{
+			Timestamp: klineTimestamp(a.start, millis),
+			Open:      a.open.String(),
+			High:      a.high.String(),
+			Low:       a.low.String(),
+			Close:     a.close.String(),
+			Volume:    a.volume.String(),
+		},
+		Start:  a.start,
+		End:    a.end,
+		Source: a.count,
+	}
+}
+
+// klineTimestamp 将时间转换为K线时间戳
+func klineTimestamp(t time.Time, millis bool) int64 {
+	if millis {
+		return t.UnixMilli()
+	}
+	return t.Unix()
+}
@@ -0,0 +1,81 @@
+package hotcoin
+
+import (
+	"testing"
+	"time"
+)
+
+func TestResampleKlines(t *testing.T) {
+	// 1分钟K线，缺少第3、4、5分钟
+	klines := []KlineData{
+		{Timestamp: 60, Open: "10", High: "12", Low: "9", Close: "11", Volume: "1"},
+		{Timestamp: 0, Open: "9", High: "10", Low: "8", Close: "10", Volume: "2"},
+		{Timestamp: 120, Open: "11", High: "11.5", Low: "10", Close: "10.5", Volume: "0.5"},
+		{Timestamp: 360, Open: "10", High: "10", Low: "10", Close: "10", Volume: "1"},
+	}
+
+	result, err := ResampleKlines(klines, "3min", FillFlat)
+	if err != nil {
+		t.Fatalf("resample: %v", err)
+	}
+	if len(result) != 3 {
+		t.Fatalf("got %d klines, want 3", len(result))
+	}
+
+	first := result[0]
+	if first.Timestamp != 0 || first.Open != "9" || first.High != "12" || first.Low != "8" ||
+		first.Close != "10.5" || first.Volume != "3.5" || first.Source != 3 {
+		t.Errorf("unexpected first kline: %+v", first)
+	}
+
+	filled := result[1]
+	if !filled.Filled || filled.Timestamp != 180 || filled.Open != "10.5" || filled.Volume != "0" {
+		t.Errorf("unexpected filled kline: %+v", filled)
+	}
+
+	if result[2].Timestamp != 360 || result[2].Filled {
+		t.Errorf("unexpected last kline: %+v", result[2])
+	}
+}
+
+func TestResampleSessions(t *testing.T) {
+	loc := time.FixedZone("CST", 8*3600)
+	resampler, err := NewKlineResampler(&ResampleConfig{Buckets: DailySessions(loc, 8*time.Hour)})
+	if err != nil {
+		t.Fatalf("new resampler: %v", err)
+	}
+
+	// 北京时间07:00属于前一交易日，09:00属于当日
+	before := time.Date(2024, 1, 2, 7, 0, 0, 0, loc)
+	after := time.Date(2024, 1, 2, 9, 0, 0, 0, loc)
+	result, err := resampler.Resample([]KlineData{
+		{Timestamp: before.UnixMilli(), Open: "1", High: "1", Low: "1", Close: "1", Volume: "1"},
+		{Timestamp: after.UnixMilli(), Open: "2", High: "2", Low: "2", Close: "2", Volume: "1"},
+	})
+	if err != nil {
+		t.Fatalf("resample: %v", err)
+	}
+	if len(result) != 2 {
+		t.Fatalf("got %d klines, want 2", len(result))
+	}
+	want := time.Date(2024, 1, 2, 8, 0, 0, 0, loc)
+	if !result[1].Start.Equal(want) || result[1].Timestamp != want.UnixMilli() {
+		t.Errorf("session start = %s", result[1].Start)
+	}
+}
+
+func TestValidateKline(t *testing.T) {
+	if err := ValidateKline(KlineData{Open: "10", High: "9", Low: "8", Close: "9"}); err == nil {
+		t.Error("expected error for high below open")
+	}
+	if err := ValidateKline(KlineData{Open: "10", High: "11", Low: "10.5", Close: "11"}); err == nil {
+		t.Error("expected error for low above open")
+	}
+	if err := ValidateKline(KlineData{Open: "10", High: "11", Low: "9", Close: "10", Volume: "1"}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	if _, err := ResampleKlines([]KlineData{{Open: "10", High: "9", Low: "8", Close: "9"}}, "5min", FillNone); err == nil {
+		t.Error("expected resample to reject invalid kline")
+	}
+}
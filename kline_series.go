@@ -0,0 +1,518 @@
+package hotcoin
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// KlineSeries 连续K线序列，由REST历史K线初始化并合并WebSocket K线推送
+// WebSocket只推送未收盘的K线，序列在收到下一周期推送或周期结束后显式发出收盘事件，
+// 推送出现周期跳跃（如断线重连后）时通过REST补齐缺失的K线。
+// 序列中的时间戳统一为毫秒，所有读取方法并发安全
+type KlineSeries struct {
+	client  *Client
+	symbol  string
+	period  string
+	config  *KlineSeriesConfig
+	fetcher *KlineFetcher
+
+	mutex   sync.RWMutex
+	bars    []KlineData // 已收盘K线，按时间排序
+	current *KlineData  // 未收盘K线
+	timer   *time.Timer
+
+	// 回补期间缓存的推送
+	syncing  bool
+	buffered []WSKlineData
+
+	// 待触发的事件，同一时刻只有一个goroutine触发回调
+	pending    []KlineBar
+	delivering bool
+
+	sub *Subscription
+
+	onUpdate KlineBarHandler
+	onClose  KlineBarHandler
+	onError  ErrorHandler
+}
+
+// NewKlineSeries 创建连续K线序列
+func NewKlineSeries(client *Client, symbol, period string, config *KlineSeriesConfig) (*KlineSeries, error) {
+	if _, err := KlinePeriodDuration(period); err != nil {
+		return nil, err
+	}
+	if config == nil {
+		config = DefaultKlineSeriesConfig()
+	}
+	if config.History < 0 {
+		config.History = 0
+	}
+
+	return &KlineSeries{
+		client:  client,
+		symbol:  symbol,
+		period:  period,
+		config:  config,
+		fetcher: NewKlineFetcher(client.Market, config.Fetch),
+	}, nil
+}
+
+// OnUpdate 设置未收盘K线更新回调
+func (s *KlineSeries) OnUpdate(handler KlineBarHandler) {
+	s.onUpdate = handler
+}
+
+// OnClose 设置K线收盘回调，按时间顺序触发，包括REST补齐的K线，
+// REST修正已收盘K线时以Corrected标记再次触发
+func (s *KlineSeries) OnClose(handler KlineBarHandler) {
+	s.onClose = handler
+}
+
+// OnError 设置错误回调
+func (s *KlineSeries) OnError(handler ErrorHandler) {
+	s.onError = handler
+}
+
+// Topic 获取K线推送主题
+func (s *KlineSeries) Topic() string {
+	return fmt.Sprintf("market.%s.kline.%s", s.symbol, s.period)
+}
+
+// Start 加载历史K线并开始接收WebSocket推送，WebSocket需已连接
+func (s *KlineSeries) Start(ctx context.Context) error {
+	ws := s.client.WebSocket
+	if !ws.IsConnected() {
+		return fmt.Errorf("not connected")
+	}
+
+	s.mutex.Lock()
+	if s.sub != nil {
+		s.mutex.Unlock()
+		return fmt.Errorf("kline series already started")
+	}
+	// 历史K线加载完成前先缓存推送
+	s.syncing = true
+	s.sub = ws.Bus().Subscribe(s.Topic(), s.handleMessage)
+	s.mutex.Unlock()
+
+	subscribed := false
+	for _, topic := range ws.Topics() {
+		if topic == s.Topic() {
+			subscribed = true
+			break
+		}
+	}
+	if !subscribed {
+		if err := ws.Subscribe(s.Topic()); err != nil {
+			s.Stop()
+			return err
+		}
+	}
+
+	if err := s.backfill(ctx); err != nil {
+		s.Stop()
+		return err
+	}
+	return nil
+}
+
+// Stop 停止接收推送，不取消WebSocket订阅
+func (s *KlineSeries) Stop() {
+	s.mutex.Lock()
+	sub := s.sub
+	s.sub = nil
+	s.syncing = false
+	s.buffered = nil
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+	s.mutex.Unlock()
+
+	if sub != nil {
+		sub.Unsubscribe()
+	}
+}
+
+// Apply 应用一次K线推送，可用于手动驱动序列
+func (s *KlineSeries) Apply(update WSKlineData) {
+	s.mutex.Lock()
+	if s.syncing {
+		s.buffered = append(s.buffered, update)
+		s.mutex.Unlock()
+		return
+	}
+
+	events, gap := s.applyLocked(update)
+	if gap {
+		s.syncing = true
+		s.buffered = append(s.buffered, update)
+	}
+	s.pending = append(s.pending, events...)
+	s.mutex.Unlock()
+
+	s.deliver()
+	if gap {
+		go s.repair(context.Background())
+	}
+}
+
+// Repair 通过REST重新加载最后一根已收盘K线之后的数据，修正已收盘K线并补齐缺失
+func (s *KlineSeries) Repair(ctx context.Context) error {
+	s.mutex.Lock()
+	if s.syncing {
+		s.mutex.Unlock()
+		return nil
+	}
+	s.syncing = true
+	s.mutex.Unlock()
+
+	return s.repair(ctx)
+}
+
+// Bars 获取已收盘K线
+func (s *KlineSeries) Bars() []KlineData {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	bars := make([]KlineData, len(s.bars))
+	copy(bars, s.bars)
+	return bars
+}
+
+// Last 获取最近n根已收盘K线
+func (s *KlineSeries) Last(n int) []KlineData {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	if n > len(s.bars) {
+		n = len(s.bars)
+	}
+	bars := make([]KlineData, n)
+	copy(bars, s.bars[len(s.bars)-n:])
+	return bars
+}
+
+// Current 获取未收盘K线
+func (s *KlineSeries) Current() (KlineData, bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	if s.current == nil {
+		return KlineData{}, false
+	}
+	return *s.current, true
+}
+
+// handleMessage 处理K线推送消息
+func (s *KlineSeries) handleMessage(message *WebSocketMessage) {
+	var update WSKlineData
+	if err := message.DecodeTick(&update); err != nil {
+		s.fail(fmt.Errorf("decode kline update: %w", err))
+		return
+	}
+	s.Apply(update)
+}
+
+// backfill 加载历史K线并应用缓存的推送，调用前需将syncing置为true
+func (s *KlineSeries) backfill(ctx context.Context) error {
+	now := time.Now()
+	start, err := KlinePeriodStart(now, s.period)
+	if err != nil {
+		return err
+	}
+	from, _ := KlinePeriodAdd(start, s.period, -s.config.History)
+	to, _ := KlinePeriodAdd(start, s.period, 1)
+
+	var klines []KlineData
+	if from.Before(to) {
+		result, err := s.fetcher.Fetch(ctx, s.symbol, s.period, from, to)
+		if err != nil {
+			s.mutex.Lock()
+			s.syncing = false
+			s.buffered = nil
+			s.mutex.Unlock()
+			return fmt.Errorf("load kline history: %w", err)
+		}
+		klines = result.Klines
+	}
+
+	s.mutex.Lock()
+	s.bars = s.bars[:0]
+	s.current = nil
+	for _, kline := range klines {
+		kline.Timestamp = KlineTime(kline.Timestamp).UnixMilli()
+		if !KlineTime(kline.Timestamp).Before(start) {
+			bar := kline
+			s.current = &bar
+			continue
+		}
+		s.bars = append(s.bars, kline)
+	}
+	s.trimLocked()
+	s.scheduleLocked()
+	s.pending = append(s.pending, s.flushLocked()...)
+	s.mutex.Unlock()
+
+	s.deliver()
+	return nil
+}
+
+// repair 补齐最后一根已收盘K线之后的数据，调用前需将syncing置为true
+func (s *KlineSeries) repair(ctx context.Context) error {
+	s.mutex.RLock()
+	var from time.Time
+	switch {
+	case len(s.bars) > 0:
+		from = KlineTime(s.bars[len(s.bars)-1].Timestamp)
+	case s.current != nil:
+		from = KlineTime(s.current.Timestamp)
+	default:
+		from, _ = KlinePeriodStart(time.Now(), s.period)
+	}
+	s.mutex.RUnlock()
+
+	to, _ := KlinePeriodStart(time.Now(), s.period)
+	to, _ = KlinePeriodAdd(to, s.period, 1)
+
+	result, err := s.fetcher.Fetch(ctx, s.symbol, s.period, from, to)
+	if err != nil {
+		s.mutex.Lock()
+		s.syncing = false
+		s.pending = append(s.pending, s.flushLocked()...)
+		s.mutex.Unlock()
+
+		s.deliver()
+		err = fmt.Errorf("repair klines: %w", err)
+		s.fail(err)
+		return err
+	}
+
+	s.mutex.Lock()
+	for _, kline := range result.Klines {
+		kline.Timestamp = KlineTime(kline.Timestamp).UnixMilli()
+		s.pending = append(s.pending, s.mergeLocked(kline)...)
+	}
+	s.scheduleLocked()
+	s.pending = append(s.pending, s.flushLocked()...)
+	s.mutex.Unlock()
+
+	s.deliver()
+	return nil
+}
+
+// mergeLocked 合并一根REST K线，调用方需持有写锁
+func (s *KlineSeries) mergeLocked(kline KlineData) []KlineBar {
+	// 修正已收盘K线
+	if n := len(s.bars); n > 0 && kline.Timestamp <= s.bars[n-1].Timestamp {
+		for i := n - 1; i >= 0 && s.bars[i].Timestamp >= kline.Timestamp; i-- {
+			if s.bars[i].Timestamp == kline.Timestamp && s.bars[i] != kline {
+				s.bars[i] = kline
+				event := s.barEvent(kline, true, true)
+				event.Corrected = true
+				return []KlineBar{event}
+			}
+		}
+		return nil
+	}
+
+	if s.current == nil || kline.Timestamp > s.current.Timestamp {
+		var events []KlineBar
+		if s.current != nil {
+			events = append(events, s.closeLocked(false))
+		}
+		bar := kline
+		s.current = &bar
+		if s.isPastLocked(kline.Timestamp) {
+			events = append(events, s.closeLocked(true))
+		}
+		return events
+	}
+	if kline.Timestamp == s.current.Timestamp {
+		*s.current = kline
+	}
+	return nil
+}
+
+// flushLocked 应用缓存的推送并结束回补，调用方需持有写锁
+func (s *KlineSeries) flushLocked() []KlineBar {
+	var events []KlineBar
+	buffered := s.buffered
+	s.buffered = nil
+	s.syncing = false
+	for _, update := range buffered {
+		applied, gap := s.applyLocked(update)
+		events = append(events, applied...)
+		if gap {
+			// 补齐后仍有缺失，REST数据尚未包含，按推送继续
+			if s.current != nil {
+				events = append(events, s.closeLocked(false))
+			}
+			s.startLocked(update)
+			events = append(events, s.barEvent(*s.current, false, false))
+		}
+	}
+	return events
+}
+
+// applyLocked 应用推送，发现周期跳跃时返回gap为true且不修改序列，调用方需持有写锁
+func (s *KlineSeries) applyLocked(update WSKlineData) ([]KlineBar, bool) {
+	ts := KlineTime(update.ID).UnixMilli()
+	last := s.lastTimestampLocked()
+
+	switch {
+	case s.current != nil && ts == s.current.Timestamp:
+		s.current.Open = update.Open
+		s.current.High = update.High
+		s.current.Low = update.Low
+		s.current.Close = update.Close
+		s.current.Volume = update.Amount
+		return []KlineBar{s.barEvent(*s.current, false, false)}, false
+	case last > 0 && ts <= last:
+		// 已收盘K线的延迟推送
+		return nil, false
+	}
+
+	if last > 0 {
+		next, _ := KlinePeriodAdd(KlineTime(last), s.period, 1)
+		if ts > next.UnixMilli() {
+			return nil, true
+		}
+	}
+
+	var events []KlineBar
+	if s.current != nil {
+		events = append(events, s.closeLocked(false))
+	}
+	s.startLocked(update)
+	events = append(events, s.barEvent(*s.current, false, false))
+	return events, false
+}
+
+// startLocked 以推送开始新的未收盘K线，调用方需持有写锁
+func (s *KlineSeries) startLocked(update WSKlineData) {
+	s.current = &KlineData{
+		Timestamp: KlineTime(update.ID).UnixMilli(),
+		Open:      update.Open,
+		High:      update.High,
+		Low:       update.Low,
+		Close:     update.Close,
+		Volume:    update.Amount,
+	}
+	s.scheduleLocked()
+}
+
+// closeLocked 收盘当前K线，调用方需持有写锁
+func (s *KlineSeries) closeLocked(backfilled bool) KlineBar {
+	bar := *s.current
+	s.current = nil
+	s.bars = append(s.bars, bar)
+	s.trimLocked()
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+	return s.barEvent(bar, true, backfilled)
+}
+
+// scheduleLocked 在当前K线周期结束后按时收盘，调用方需持有写锁
+func (s *KlineSeries) scheduleLocked() {
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+	if s.current == nil || s.sub == nil {
+		return
+	}
+
+	end, _ := KlinePeriodAdd(KlineTime(s.current.Timestamp), s.period, 1)
+	ts := s.current.Timestamp
+	s.timer = time.AfterFunc(time.Until(end)+s.config.CloseDelay, func() {
+		s.mutex.Lock()
+		if s.syncing || s.current == nil || s.current.Timestamp != ts {
+			s.mutex.Unlock()
+			return
+		}
+		s.pending = append(s.pending, s.closeLocked(false))
+		s.mutex.Unlock()
+
+		s.deliver()
+	})
+}
+
+// isPastLocked K线周期是否已结束
+func (s *KlineSeries) isPastLocked(ts int64) bool {
+	end, _ := KlinePeriodAdd(KlineTime(ts), s.period, 1)
+	return !time.Now().Before(end)
+}
+
+// lastTimestampLocked 最近一根K线的时间戳，调用方需持有锁
+func (s *KlineSeries) lastTimestampLocked() int64 {
+	if s.current != nil {
+		return s.current.Timestamp
+	}
+	if n := len(s.bars); n > 0 {
+		return s.bars[n-1].Timestamp
+	}
+	return 0
+}
+
+// trimLocked 限制保留的已收盘K线数量，调用方需持有写锁
+func (s *KlineSeries) trimLocked() {
+	if s.config.MaxBars > 0 && len(s.bars) > s.config.MaxBars {
+		s.bars = append(s.bars[:0], s.bars[len(s.bars)-s.config.MaxBars:]...)
+	}
+}
+
+// barEvent 构建K线事件
+func (s *KlineSeries) barEvent(kline KlineData, closed, backfilled bool) KlineBar {
+	return KlineBar{
+		Symbol:     s.symbol,
+		Period:     s.period,
+		KlineData:  kline,
+		Closed:     closed,
+		Backfilled: backfilled,
+	}
+}
+
+// deliver 按产生顺序触发待触发的事件，其他goroutine正在触发时直接返回，
+// 由该goroutine继续送出新产生的事件，保证回调不会并发或乱序
+func (s *KlineSeries) deliver() {
+	s.mutex.Lock()
+	if s.delivering {
+		s.mutex.Unlock()
+		return
+	}
+	s.delivering = true
+	for len(s.pending) > 0 {
+		events := s.pending
+		s.pending = nil
+		s.mutex.Unlock()
+		s.emit(events)
+		s.mutex.Lock()
+	}
+	s.delivering = false
+	s.mutex.Unlock()
+}
+
+// emit 按顺序触发回调
+func (s *KlineSeries) emit(events []KlineBar) {
+	for _, event := range events {
+		if event.Closed {
+			if s.onClose != nil {
+				s.onClose(event)
+			}
+		} else if s.onUpdate != nil {
+			s.onUpdate(event)
+		}
+	}
+}
+
+// fail 触发错误回调
+func (s *KlineSeries) fail(err error) {
+	if s.onError != nil {
+		s.onError(err)
+	}
+}
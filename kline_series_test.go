@@ -0,0 +1,179 @@
+package hotcoin
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestKlineSeriesRepairsGap(t *testing.T) {
+	start, _ := KlinePeriodStart(time.Now(), "1min")
+	minute := func(n int) int64 { return start.Add(time.Duration(n) * time.Minute).Unix() }
+
+	var mutex sync.Mutex
+	available := []int64{minute(-3), minute(-2)}
+	series, err := NewKlineSeries(&Client{Market: &MarketService{}}, "btcusdt", "1min", &KlineSeriesConfig{History: 3})
+	if err != nil {
+		t.Fatalf("new series: %v", err)
+	}
+	series.fetcher.fetch = func(ctx context.Context, symbol, period string, from, to int64) ([]KlineData, error) {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		var klines []KlineData
+		for _, ts := range available {
+			if ts >= from && ts < to {
+				klines = append(klines, KlineData{Timestamp: ts, Open: "1", High: "2", Low: "1", Close: "2", Volume: "1"})
+			}
+		}
+		return klines, nil
+	}
+
+	series.syncing = true
+	if err := series.backfill(context.Background()); err != nil {
+		t.Fatalf("backfill: %v", err)
+	}
+	if bars := series.Bars(); len(bars) != 2 {
+		t.Fatalf("got %d bars, want 2", len(bars))
+	}
+
+	var closed []KlineBar
+	updated := make(chan KlineBar, 10)
+	series.OnClose(func(bar KlineBar) { closed = append(closed, bar) })
+	series.OnUpdate(func(bar KlineBar) { updated <- bar })
+
+	// 断线期间缺失第-1分钟，REST恢复后补齐
+	mutex.Lock()
+	available = append(available, minute(-1), minute(0))
+	mutex.Unlock()
+	series.Apply(WSKlineData{ID: minute(0), Open: "2", High: "3", Low: "2", Close: "3", Amount: "5"})
+
+	select {
+	case bar := <-updated:
+		if bar.Close != "3" || bar.Timestamp != minute(0)*1000 {
+			t.Errorf("unexpected update: %+v", bar)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for update")
+	}
+
+	if len(closed) != 1 || !closed[0].Backfilled || closed[0].Timestamp != minute(-1)*1000 {
+		t.Fatalf("unexpected closed bars: %+v", closed)
+	}
+	if bars := series.Bars(); len(bars) != 3 {
+		t.Errorf("got %d bars, want 3", len(bars))
+	}
+
+	// 下一周期推送使当前K线收盘
+	series.Apply(WSKlineData{ID: minute(1), Open: "3", High: "3", Low: "3", Close: "3", Amount: "1"})
+	if len(closed) != 2 || closed[1].Backfilled || closed[1].Close != "3" || closed[1].Volume != "5" {
+		t.Errorf("unexpected closed bars: %+v", closed)
+	}
+	if current, ok := series.Current(); !ok || current.Timestamp != minute(1)*1000 {
+		t.Errorf("unexpected current bar: %+v", current)
+	}
+}
+
+// newTestKlineSeries 创建从klines读取历史数据的K线序列并完成初始化
+func newTestKlineSeries(t *testing.T, klines func() []KlineData) *KlineSeries {
+	series, err := NewKlineSeries(&Client{Market: &MarketService{}}, "btcusdt", "1min", &KlineSeriesConfig{History: 3})
+	if err != nil {
+		t.Fatalf("new series: %v", err)
+	}
+	series.fetcher.fetch = func(ctx context.Context, symbol, period string, from, to int64) ([]KlineData, error) {
+		var result []KlineData
+		for _, kline := range klines() {
+			if kline.Timestamp >= from && kline.Timestamp < to {
+				result = append(result, kline)
+			}
+		}
+		return result, nil
+	}
+
+	series.syncing = true
+	if err := series.backfill(context.Background()); err != nil {
+		t.Fatalf("backfill: %v", err)
+	}
+	return series
+}
+
+func TestKlineSeriesRepairCorrection(t *testing.T) {
+	start, _ := KlinePeriodStart(time.Now(), "1min")
+	minute := func(n int) int64 { return start.Add(time.Duration(n) * time.Minute).Unix() }
+
+	price := "2"
+	series := newTestKlineSeries(t, func() []KlineData {
+		return []KlineData{
+			{Timestamp: minute(-3), Open: "1", High: "2", Low: "1", Close: "2", Volume: "1"},
+			{Timestamp: minute(-2), Open: "1", High: "2", Low: "1", Close: price, Volume: "1"},
+		}
+	})
+
+	var closed []KlineBar
+	series.OnClose(func(bar KlineBar) { closed = append(closed, bar) })
+
+	// 数据未变化时不触发修正
+	if err := series.Repair(context.Background()); err != nil {
+		t.Fatalf("repair: %v", err)
+	}
+	if len(closed) != 0 {
+		t.Fatalf("unexpected events: %+v", closed)
+	}
+
+	price = "1.5"
+	if err := series.Repair(context.Background()); err != nil {
+		t.Fatalf("repair: %v", err)
+	}
+	if len(closed) != 1 || !closed[0].Corrected || !closed[0].Closed || closed[0].Close != "1.5" ||
+		closed[0].Timestamp != minute(-2)*1000 {
+		t.Fatalf("unexpected events: %+v", closed)
+	}
+	if bars := series.Bars(); bars[1].Close != "1.5" {
+		t.Errorf("bar not corrected: %+v", bars[1])
+	}
+}
+
+func TestKlineSeriesSerializesDelivery(t *testing.T) {
+	start, _ := KlinePeriodStart(time.Now(), "1min")
+	minute := func(n int) int64 { return start.Add(time.Duration(n) * time.Minute).Unix() }
+	series := newTestKlineSeries(t, func() []KlineData {
+		return []KlineData{{Timestamp: minute(-1), Open: "1", High: "1", Low: "1", Close: "1", Volume: "1"}}
+	})
+
+	var mutex sync.Mutex
+	var closes []string
+	active := 0
+	applied := make(chan struct{})
+	series.OnUpdate(func(bar KlineBar) {
+		mutex.Lock()
+		active++
+		if active > 1 {
+			t.Error("callbacks run concurrently")
+		}
+		closes = append(closes, bar.Close)
+		first := len(closes) == 1
+		mutex.Unlock()
+
+		if first {
+			// 回调期间其他goroutine产生的事件排在当前事件之后触发
+			go func() {
+				series.Apply(WSKlineData{ID: minute(0), Open: "1", High: "3", Low: "1", Close: "3", Amount: "2"})
+				close(applied)
+			}()
+			<-applied
+		}
+
+		mutex.Lock()
+		active--
+		mutex.Unlock()
+	})
+
+	series.Apply(WSKlineData{ID: minute(0), Open: "1", High: "2", Low: "1", Close: "2", Amount: "1"})
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	if len(closes) != 2 || closes[0] != "2" || closes[1] != "3" {
+		t.Errorf("unexpected delivery order: %v", closes)
+	}
+}
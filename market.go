@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // 行情接口相关方法
@@ -13,13 +14,18 @@ import (
 // GetContracts 获取合约列表
 // symbol: 交易对符号，可选，如果不传则返回所有合约
 func (m *MarketService) GetContracts(symbol string) ([]Contract, error) {
+	return m.getContracts(context.Background(), symbol)
+}
+
+// getContracts 获取合约列表，请求随ctx取消
+func (m *MarketService) getContracts(ctx context.Context, symbol string) ([]Contract, error) {
 	params := make(map[string]string)
 	if symbol != "" {
 		params["symbol"] = symbol
 	}
 
 	var result []Contract
-	resp, err := m.client.get(context.Background(), "/api/v1/perpetual/public", params, false)
+	resp, err := m.client.get(ctx, "/api/v1/perpetual/public", params, false)
 	if err != nil {
 		return nil, err
 	}
@@ -49,8 +55,11 @@ func (m *MarketService) GetKline(symbol, period string, size int) ([]KlineData,
 		return nil, fmt.Errorf("period is required")
 	}
 
-	// 构建正确的API路径，contractCode从symbol中提取（去掉-转换为小写）
-	contractCode := strings.ToLower(strings.Replace(symbol, "-", "", -1))
+	// 构建正确的API路径，contractCode由symbol规范化得到，启用合约注册表时拒绝未知交易对
+	contractCode, err := m.client.contractCode(symbol)
+	if err != nil {
+		return nil, err
+	}
 	path := fmt.Sprintf("/api/v1/perpetual/public/%s/candles", contractCode)
 
 	// 设置查询参数
@@ -114,21 +123,29 @@ func (m *MarketService) GetKline(symbol, period string, size int) ([]KlineData,
 // symbol: 交易对符号
 // depthType: 深度类型，支持: step0, step1, step2, step3, step4, step5
 func (m *MarketService) GetDepth(symbol, depthType string) (*DepthData, error) {
+	return m.getDepth(context.Background(), symbol, depthType)
+}
+
+// getDepth 获取深度信息，请求随ctx取消
+func (m *MarketService) getDepth(ctx context.Context, symbol, depthType string) (*DepthData, error) {
 	if symbol == "" {
 		return nil, fmt.Errorf("symbol is required")
 	}
 
 	// 构建正确的API路径
-	contractCode := strings.ToLower(strings.Replace(symbol, "-", "", -1))
+	contractCode, err := m.client.contractCode(symbol)
+	if err != nil {
+		return nil, err
+	}
 	path := fmt.Sprintf("/api/v1/perpetual/public/products/%s/orderbook", contractCode)
 
 	// 设置查询参数
 	params := map[string]string{}
 	if depthType != "" {
-		params["size"] = "20" // 深度数量，根据需要调整
+		params["size"] = strconv.Itoa(DepthLevels)
 	}
 
-	resp, err := m.client.get(context.Background(), path, params, false)
+	resp, err := m.client.get(ctx, path, params, false)
 	if err != nil {
 		return nil, err
 	}
@@ -157,12 +174,23 @@ func (m *MarketService) GetDepth(symbol, depthType string) (*DepthData, error) {
 // symbol: 交易对符号
 // size: 获取数量，默认1，最大2000
 func (m *MarketService) GetTrades(symbol string, size int) ([]TradeData, error) {
+	return m.getTrades(context.Background(), symbol, size)
+}
+
+// getTrades 获取交易记录，请求随ctx取消
+func (m *MarketService) getTrades(ctx context.Context, symbol string, size int) ([]TradeData, error) {
 	if symbol == "" {
 		return nil, fmt.Errorf("symbol is required")
 	}
 
+	// 交易对按规范合约代码发送，启用合约注册表时拒绝未知交易对
+	contractCode, err := m.client.contractCode(symbol)
+	if err != nil {
+		return nil, err
+	}
+
 	params := map[string]string{
-		"symbol": symbol,
+		"symbol": contractCode,
 	}
 	if size > 0 {
 		params["size"] = strconv.Itoa(size)
@@ -176,10 +204,9 @@ func (m *MarketService) GetTrades(symbol string, size int) ([]TradeData, error)
 	}
 
 	// 构建正确的API路径
-	contractCode := strings.ToLower(strings.Replace(symbol, "-", "", -1))
 	path := fmt.Sprintf("/api/v1/perpetual/public/%s/fills", contractCode)
 
-	resp, err := m.client.get(context.Background(), path, params, false)
+	resp, err := m.client.get(ctx, path, params, false)
 	if err != nil {
 		return nil, err
 	}
@@ -206,7 +233,11 @@ func (m *MarketService) GetTrades(symbol string, size int) ([]TradeData, error)
 func (m *MarketService) GetIndexPrice(symbol string) ([]IndexPriceComponent, error) {
 	params := make(map[string]string)
 	if symbol != "" {
-		params["symbol"] = symbol
+		code, err := m.client.contractCode(symbol)
+		if err != nil {
+			return nil, err
+		}
+		params["symbol"] = code
 	}
 
 	var response struct {
@@ -244,8 +275,14 @@ func (m *MarketService) GetFundingRate(symbol string) (*FundingRate, error) {
 		return nil, fmt.Errorf("symbol is required")
 	}
 
+	// 交易对按规范合约代码发送，启用合约注册表时拒绝未知交易对
+	contractCode, err := m.client.contractCode(symbol)
+	if err != nil {
+		return nil, err
+	}
+
 	params := map[string]string{
-		"symbol": symbol,
+		"symbol": contractCode,
 	}
 
 	var response struct {
@@ -255,7 +292,6 @@ func (m *MarketService) GetFundingRate(symbol string) (*FundingRate, error) {
 	}
 
 	// 构建正确的API路径
-	contractCode := strings.ToLower(strings.Replace(symbol, "-", "", -1))
 	path := fmt.Sprintf("/api/v1/perpetual/public/products/%s/funding-rate", contractCode)
 
 	resp, err := m.client.get(context.Background(), path, params, false)
@@ -289,8 +325,14 @@ func (m *MarketService) GetHistoricalFundingRate(symbol string, pageIndex, pageS
 		return nil, fmt.Errorf("symbol is required")
 	}
 
+	// 交易对按规范合约代码发送，启用合约注册表时拒绝未知交易对
+	contractCode, err := m.client.contractCode(symbol)
+	if err != nil {
+		return nil, err
+	}
+
 	params := map[string]string{
-		"symbol": symbol,
+		"symbol": contractCode,
 	}
 	if pageIndex > 0 {
 		params["page_index"] = strconv.Itoa(pageIndex)
@@ -306,7 +348,6 @@ func (m *MarketService) GetHistoricalFundingRate(symbol string, pageIndex, pageS
 	}
 
 	// 构建正确的API路径
-	contractCode := strings.ToLower(strings.Replace(symbol, "-", "", -1))
 	path := fmt.Sprintf("/api/v1/perpetual/public/products/%s/funding-rate/history", contractCode)
 
 	resp, err := m.client.get(context.Background(), path, params, false)
@@ -334,13 +375,22 @@ func (m *MarketService) GetHistoricalFundingRate(symbol string, pageIndex, pageS
 // GetTicker 获取24小时行情统计
 // symbol: 交易对符号，可选，如果不传则返回所有
 func (m *MarketService) GetTicker(symbol string) ([]TickerData, error) {
+	return m.getTicker(context.Background(), symbol)
+}
+
+// getTicker 获取24小时行情统计，请求随ctx取消
+func (m *MarketService) getTicker(ctx context.Context, symbol string) ([]TickerData, error) {
 	params := make(map[string]string)
 	if symbol != "" {
-		params["symbol"] = symbol
+		code, err := m.client.contractCode(symbol)
+		if err != nil {
+			return nil, err
+		}
+		params["symbol"] = code
 	}
 
 	// 24小时行情统计从产品列表接口获取
-	resp, err := m.client.get(context.Background(), "/api/v1/perpetual/public", params, false)
+	resp, err := m.client.get(ctx, "/api/v1/perpetual/public", params, false)
 	if err != nil {
 		return nil, err
 	}
@@ -368,9 +418,14 @@ func (m *MarketService) GetTicker(symbol string) ([]TickerData, error) {
 // GetHistoricalKline 获取历史K线数据（支持更大范围查询）
 // symbol: 交易对符号
 // period: K线周期
-// from: 开始时间戳（秒）
-// to: 结束时间戳（秒）
-func (m *MarketService) GetHistoricalKline(symbol, period string, from, to int64) ([]KlineData, error) {
+// from: 开始时间，零值表示不限，按秒发送
+// to: 结束时间，零值表示不限，按秒发送
+func (m *MarketService) GetHistoricalKline(symbol, period string, from, to time.Time) ([]KlineData, error) {
+	return m.getHistoricalKline(context.Background(), symbol, period, from, to)
+}
+
+// getHistoricalKline 按ctx获取历史K线数据，ctx取消时中止请求
+func (m *MarketService) getHistoricalKline(ctx context.Context, symbol, period string, from, to time.Time) ([]KlineData, error) {
 	if symbol == "" {
 		return nil, fmt.Errorf("symbol is required")
 	}
@@ -378,15 +433,21 @@ func (m *MarketService) GetHistoricalKline(symbol, period string, from, to int64
 		return nil, fmt.Errorf("period is required")
 	}
 
+	// 交易对按规范合约代码发送，启用合约注册表时拒绝未知交易对
+	contractCode, err := m.client.contractCode(symbol)
+	if err != nil {
+		return nil, err
+	}
+
 	params := map[string]string{
-		"symbol": symbol,
+		"symbol": contractCode,
 		"period": period,
 	}
-	if from > 0 {
-		params["from"] = strconv.FormatInt(from, 10)
+	if !from.IsZero() {
+		params["from"] = strconv.FormatInt(from.Unix(), 10)
 	}
-	if to > 0 {
-		params["to"] = strconv.FormatInt(to, 10)
+	if !to.IsZero() {
+		params["to"] = strconv.FormatInt(to.Unix(), 10)
 	}
 
 	var response struct {
@@ -396,10 +457,9 @@ func (m *MarketService) GetHistoricalKline(symbol, period string, from, to int64
 	}
 
 	// 构建正确的API路径
-	contractCode := strings.ToLower(strings.Replace(symbol, "-", "", -1))
 	path := fmt.Sprintf("/api/v1/perpetual/public/%s/candles/history", contractCode)
 
-	resp, err := m.client.get(context.Background(), path, params, false)
+	resp, err := m.client.get(ctx, path, params, false)
 	if err != nil {
 		return nil, err
 	}
@@ -449,8 +509,17 @@ func (m *MarketService) GetBatchTicker(symbols []string) ([]TickerData, error) {
 		return m.GetTicker("") // 获取所有
 	}
 
+	codes := make([]string, len(symbols))
+	for i, symbol := range symbols {
+		code, err := m.client.contractCode(symbol)
+		if err != nil {
+			return nil, err
+		}
+		codes[i] = code
+	}
+
 	params := map[string]string{
-		"symbol": strings.Join(symbols, ","),
+		"symbol": strings.Join(codes, ","),
 	}
 
 	var response struct {
@@ -481,3 +550,62 @@ func (m *MarketService) GetBatchTicker(symbols []string) ([]TickerData, error) {
 
 	return response.Data, nil
 }
+
+// GetAggregatedDepth 获取深度并按合约价格精度在本地聚合
+// symbol: 交易对符号
+// depthType: 深度类型，支持: step0, step1, step2, step3, step4, step5，stepN为最小变动价位的10^N倍
+// 聚合基于每侧最多DepthLevels档的step0深度，返回结果中记录实际参与聚合的源档位数
+func (m *MarketService) GetAggregatedDepth(symbol, depthType string) (*AggregatedDepth, error) {
+	if symbol == "" {
+		return nil, fmt.Errorf("symbol is required")
+	}
+	if depthType == "" {
+		depthType = "step0"
+	}
+
+	contractCode, err := m.client.contractCode(symbol)
+	if err != nil {
+		return nil, err
+	}
+	aggregator, err := m.depthAggregator(contractCode)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := aggregator.Step(depthType); err != nil {
+		return nil, err
+	}
+
+	// 始终基于全精度深度聚合
+	depth, err := m.getDepth(context.Background(), contractCode, "step0")
+	if err != nil {
+		return nil, err
+	}
+
+	aggregated, err := aggregator.Aggregate(depth, depthType)
+	if err != nil {
+		return nil, err
+	}
+	return &AggregatedDepth{
+		DepthData:  *aggregated,
+		SourceBids: len(depth.Bids),
+		SourceAsks: len(depth.Asks),
+	}, nil
+}
+
+// depthAggregator 创建合约的深度聚合器，启用合约注册表时使用缓存的价格步长，否则按合约代码查询合约信息
+func (m *MarketService) depthAggregator(contractCode string) (*DepthAggregator, error) {
+	if registry := m.client.ContractRegistry(); registry != nil {
+		if info, ok := registry.Lookup(contractCode); ok {
+			return NewDepthAggregator(info.PriceTick)
+		}
+	}
+
+	contracts, err := m.GetContracts(contractCode)
+	if err != nil {
+		return nil, err
+	}
+	if len(contracts) == 0 || NormalizeSymbol(contracts[0].Code) != contractCode {
+		return nil, fmt.Errorf("contract %s not found", contractCode)
+	}
+	return NewDepthAggregatorForContract(&contracts[0])
+}
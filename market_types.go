@@ -1,44 +1,51 @@
 package hotcoin
 
+import (
+	"fmt"
+	"time"
+)
+
 // Contract 合约信息
 type Contract struct {
-	Code                     string  `json:"code"`                     // 合约代码
-	Base                     string  `json:"base"`                     // 基础货币
-	BaseDisplayName          string  `json:"baseDisplayName"`          // 基础货币显示名称
-	Quote                    string  `json:"quote"`                    // 计价货币
-	QuoteDisplayName         string  `json:"quoteDisplayName"`         // 计价货币显示名称
-	IndexBase                string  `json:"indexBase"`                // 指数基础货币
-	IndexBaseDisplayName     string  `json:"indexBaseDisplayName"`     // 指数基础货币显示名称
-	IndexBaseAppLogo         string  `json:"indexBaseAppLogo"`         // APP Logo
-	IndexBaseWebLogo         string  `json:"indexBaseWebLogo"`         // Web Logo
-	Direction                int     `json:"direction"`                // 方向 0:正向合约,1:反向合约
-	Price                    string  `json:"price"`                    // 最新价
-	MarkPrice                string  `json:"markPrice"`                // 标记价格
-	IndexPrice               string  `json:"indexPrice"`               // 指数价格
-	High                     string  `json:"high"`                     // 最高价
-	Low                      string  `json:"low"`                      // 最低价
-	Amount24                 string  `json:"amount24"`                 // 24小时成交张数
-	Size24                   string  `json:"size24"`                   // 24小时成交价值
-	Fluctuation              string  `json:"fluctuation"`              // 涨跌幅
-	TotalPosition            string  `json:"totalPosition"`            // 持仓量
-	Fund                     string  `json:"fund"`                     // 资金费率
-	UnitAmount               float64 `json:"unitAmount"`               // 一张合约对应的quote面值
-	MinTradeUnit             float64 `json:"minTradeUnit"`             // 最小交易单位
-	MinTradeDigit            int     `json:"minTradeDigit"`            // 基础货币最小交易小数位
-	MinQuoteDigit            int     `json:"minQuoteDigit"`            // 计价货币最小交易小数位
-	MarketPriceDigit         int     `json:"marketPriceDigit"`         // 市价小数位
-	MaxLever                 int     `json:"maxLever"`                 // 最大杠杆
-	Env                      int     `json:"env"`                      // 是否测试盘 0:线上盘,1:测试盘
-	TradeStatus              int     `json:"tradeStatus"`              // 交易状态
-	GuaranteedStopLossRate   string  `json:"guaranteedStopLossRate"`   // 保证止损费率
-	GuaranteedStopLossStatus int     `json:"guaranteedStopLossStatus"` // 保证止损状态
-	LiquidationTime          int64   `json:"liquidationTime"`          // 清算时间
-	NextLiquidationInterval  int     `json:"nextLiquidationInterval"`  // 下次清算间隔
-	OpenTradeTime            string  `json:"openTradeTime"`            // 开放交易时间
-	PreDeliveryPrice         string  `json:"preDeliveryPrice"`         // 预交割价格
+	Code                     string    `json:"code"`                     // 合约代码
+	Base                     string    `json:"base"`                     // 基础货币
+	BaseDisplayName          string    `json:"baseDisplayName"`          // 基础货币显示名称
+	Quote                    string    `json:"quote"`                    // 计价货币
+	QuoteDisplayName         string    `json:"quoteDisplayName"`         // 计价货币显示名称
+	IndexBase                string    `json:"indexBase"`                // 指数基础货币
+	IndexBaseDisplayName     string    `json:"indexBaseDisplayName"`     // 指数基础货币显示名称
+	IndexBaseAppLogo         string    `json:"indexBaseAppLogo"`         // APP Logo
+	IndexBaseWebLogo         string    `json:"indexBaseWebLogo"`         // Web Logo
+	Direction                int       `json:"direction"`                // 方向 0:正向合约,1:反向合约
+	Price                    string    `json:"price"`                    // 最新价
+	MarkPrice                string    `json:"markPrice"`                // 标记价格
+	IndexPrice               string    `json:"indexPrice"`               // 指数价格
+	High                     string    `json:"high"`                     // 最高价
+	Low                      string    `json:"low"`                      // 最低价
+	Amount24                 string    `json:"amount24"`                 // 24小时成交张数
+	Size24                   string    `json:"size24"`                   // 24小时成交价值
+	Fluctuation              string    `json:"fluctuation"`              // 涨跌幅
+	TotalPosition            string    `json:"totalPosition"`            // 持仓量
+	Fund                     string    `json:"fund"`                     // 资金费率
+	UnitAmount               float64   `json:"unitAmount"`               // 一张合约对应的quote面值
+	MinTradeUnit             float64   `json:"minTradeUnit"`             // 最小交易单位
+	MinTradeDigit            int       `json:"minTradeDigit"`            // 基础货币最小交易小数位
+	MinQuoteDigit            int       `json:"minQuoteDigit"`            // 计价货币最小交易小数位
+	MarketPriceDigit         int       `json:"marketPriceDigit"`         // 市价小数位
+	MaxLever                 int       `json:"maxLever"`                 // 最大杠杆
+	Env                      int       `json:"env"`                      // 是否测试盘 0:线上盘,1:测试盘
+	TradeStatus              int       `json:"tradeStatus"`              // 交易状态
+	GuaranteedStopLossRate   string    `json:"guaranteedStopLossRate"`   // 保证止损费率
+	GuaranteedStopLossStatus int       `json:"guaranteedStopLossStatus"` // 保证止损状态
+	LiquidationTime          Timestamp `json:"liquidationTime"`          // 清算时间
+	NextLiquidationInterval  int       `json:"nextLiquidationInterval"`  // 下次清算间隔
+	OpenTradeTime            Timestamp `json:"openTradeTime"`            // 开放交易时间
+	PreDeliveryPrice         string    `json:"preDeliveryPrice"`         // 预交割价格
 }
 
 // KlineData K线数据
+// Timestamp保留接口原始的int64值（通常为秒），K线序列、重采样和导出直接以其作为时段键做整数比较和运算，
+// 需要time.Time时通过Time()转换
 type KlineData struct {
 	Timestamp int64  `json:"timestamp"` // 时间戳
 	Open      string `json:"open"`      // 开盘价
@@ -48,37 +55,295 @@ type KlineData struct {
 	Volume    string `json:"volume"`    // 成交量
 }
 
+// KlineGap K线缺失区间
+type KlineGap struct {
+	From    time.Time // 缺失区间开始时间
+	To      time.Time // 缺失区间结束时间（不含）
+	Missing int       // 缺失的K线数量
+}
+
+// KlineRange 区间K线下载结果
+type KlineRange struct {
+	Symbol string      // 交易对
+	Period string      // K线周期
+	From   time.Time   // 开始时间
+	To     time.Time   // 结束时间（不含）
+	Klines []KlineData // 按时间戳排序去重的K线
+	Gaps   []KlineGap  // 缺失区间
+}
+
+// KlineBar K线序列事件
+type KlineBar struct {
+	Symbol string // 交易对
+	Period string // K线周期
+	KlineData
+	Closed     bool // 是否已收盘
+	Backfilled bool // 是否由REST补齐
+	Corrected  bool // 是否为REST对已收盘K线的修正
+}
+
+// KlineBarHandler K线序列事件处理函数
+type KlineBarHandler func(bar KlineBar)
+
+// KlineSeriesConfig 连续K线序列配置
+type KlineSeriesConfig struct {
+	History    int               // 启动时加载的历史K线数量
+	MaxBars    int               // 保留的最大已收盘K线数量，0表示不限制
+	CloseDelay time.Duration     // 周期结束后等待下一周期推送的时间，超时后按时收盘
+	Fetch      *KlineFetchConfig // REST下载配置
+}
+
+// DefaultKlineSeriesConfig 默认连续K线序列配置
+func DefaultKlineSeriesConfig() *KlineSeriesConfig {
+	return &KlineSeriesConfig{
+		History:    500,
+		MaxBars:    5000,
+		CloseDelay: 2 * time.Second,
+	}
+}
+
+// KlineFillMode 重采样缺失区间的填充方式
+type KlineFillMode int
+
+const (
+	FillNone KlineFillMode = 0 // 不输出缺失区间
+	FillFlat KlineFillMode = 1 // 以上一收盘价填充成交量为0的K线
+	FillMark KlineFillMode = 2 // 输出仅包含时间的空K线并标记为填充
+)
+
+// KlineBucketFunc 返回t所在区间的开始和结束时间（不含）
+type KlineBucketFunc func(t time.Time) (start, end time.Time)
+
+// ResampleConfig K线重采样配置
+type ResampleConfig struct {
+	Interval     time.Duration   // 目标周期
+	Origin       time.Time       // 固定周期的对齐基准，零值为Unix纪元
+	Buckets      KlineBucketFunc // 自定义区间划分，设置后忽略Interval和Origin
+	SourcePeriod time.Duration   // 源K线周期，设置后校验目标周期是其整数倍
+	Fill         KlineFillMode   // 缺失区间填充方式
+	Validate     bool            // 是否校验源K线OHLC一致性
+}
+
+// ResampledKline 重采样后的K线
+type ResampledKline struct {
+	KlineData
+	Start  time.Time // 区间开始时间
+	End    time.Time // 区间结束时间（不含）
+	Source int       // 合并的源K线数量
+	Filled bool      // 是否为填充的缺失区间
+}
+
+// KlineIssue K线校验问题
+type KlineIssue struct {
+	Index     int   // K线索引
+	Timestamp int64 // K线时间戳
+	Err       error // 问题描述
+}
+
+// KlineFetchConfig 历史K线下载配置
+type KlineFetchConfig struct {
+	BatchSize       int           // 单次请求的最大K线数量
+	Concurrency     int           // 最大并发请求数
+	RequestInterval time.Duration // 请求最小间隔，用于限制请求频率
+	MaxRetries      int           // 单个窗口失败后的重试次数
+	RetryBackoff    time.Duration // 重试退避基数
+}
+
+// DefaultKlineFetchConfig 默认历史K线下载配置
+func DefaultKlineFetchConfig() *KlineFetchConfig {
+	return &KlineFetchConfig{
+		BatchSize:       2000,
+		Concurrency:     4,
+		RequestInterval: 100 * time.Millisecond,
+		MaxRetries:      3,
+		RetryBackoff:    time.Second,
+	}
+}
+
 // DepthData 深度数据
 type DepthData struct {
 	Bids [][]string `json:"bids"` // 买盘 [价格, 数量]
 	Asks [][]string `json:"asks"` // 卖盘 [价格, 数量]
 }
 
+// DepthLevels GetDepth每侧请求的深度档位数
+const DepthLevels = 20
+
+// AggregatedDepth 本地聚合的深度
+// 聚合的源数据为GetDepth返回的step0深度，每侧最多DepthLevels档，
+// 聚合后覆盖的价格范围受此限制，步长越大档位越少
+type AggregatedDepth struct {
+	DepthData
+	SourceBids int // 参与聚合的买盘源档位数
+	SourceAsks int // 参与聚合的卖盘源档位数
+}
+
+// FillEstimate 市价成交成本估算
+type FillEstimate struct {
+	Side        OrderSide // 吃单方向
+	Filled      Decimal   // 可成交数量
+	Notional    Decimal   // 成交额
+	AvgPrice    Decimal   // 成交均价
+	BestPrice   Decimal   // 对手方最优价
+	WorstPrice  Decimal   // 最后成交档位价格
+	Slippage    Decimal   // 相对最优价的滑点（不利方向为正）
+	SlippageBps Decimal   // 相对最优价的滑点（基点）
+	MidCostBps  Decimal   // 相对中间价的成本（基点）
+	Levels      int       // 消耗的档位数
+	Complete    bool      // 深度是否足以完全成交
+}
+
 // TradeData 交易数据
 type TradeData struct {
 	ID        int64  `json:"id"`        // 交易ID
 	Price     string `json:"price"`     // 成交价格
 	Amount    string `json:"amount"`    // 成交数量
 	Side      string `json:"side"`      // 交易方向
-	Timestamp int64  `json:"timestamp"` // 成交时间
+	Timestamp int64  `json:"timestamp"` // 成交时间（毫秒），与WebSocket成交推送一致保留int64，通过Time()转换
+}
+
+// Trade 精确数值表示的成交
+type Trade struct {
+	ID     int64     // 成交ID
+	Price  Decimal   // 成交价格
+	Amount Decimal   // 成交数量
+	Side   OrderSide // 主动成交方向，未知时为空
+	Time   time.Time // 成交时间
+}
+
+// TradeBarType 成交K线类型
+type TradeBarType int
+
+const (
+	TradeBarTime      TradeBarType = 0 // 时间K线，按固定时长划分
+	TradeBarTick      TradeBarType = 1 // 笔数K线，每N笔成交一根
+	TradeBarVolume    TradeBarType = 2 // 成交量K线，累计成交量达到阈值
+	TradeBarNotional  TradeBarType = 3 // 成交额K线，累计成交额达到阈值
+	TradeBarImbalance TradeBarType = 4 // 不平衡K线，主动买卖量差的绝对值达到阈值
+)
+
+// String 返回K线类型名称
+func (t TradeBarType) String() string {
+	switch t {
+	case TradeBarTime:
+		return "time"
+	case TradeBarTick:
+		return "tick"
+	case TradeBarVolume:
+		return "volume"
+	case TradeBarNotional:
+		return "notional"
+	case TradeBarImbalance:
+		return "imbalance"
+	default:
+		return fmt.Sprintf("TradeBarType(%d)", int(t))
+	}
+}
+
+// DefaultTradeDedupSize 默认记录的最近成交ID数量
+const DefaultTradeDedupSize = 10000
+
+// TradeBarConfig 成交K线配置
+type TradeBarConfig struct {
+	Type         TradeBarType  // K线类型
+	Interval     time.Duration // 时间K线周期
+	Threshold    Decimal       // 笔数、成交量、成交额或不平衡阈值
+	ContractSize Decimal       // 合约面值，非零时成交额乘以面值
+	DedupSize    int           // 用于去重的最近成交ID数量
+}
+
+// TradeBar 成交K线
+type TradeBar struct {
+	Symbol     string       // 交易对
+	Type       TradeBarType // K线类型
+	Start      time.Time    // 开始时间，时间K线为区间开始，其余为首笔成交时间
+	End        time.Time    // 结束时间，时间K线为区间结束（不含），其余为末笔成交时间
+	Open       Decimal      // 开盘价
+	High       Decimal      // 最高价
+	Low        Decimal      // 最低价
+	Close      Decimal      // 收盘价
+	Volume     Decimal      // 成交量
+	Notional   Decimal      // 成交额
+	BuyVolume  Decimal      // 主动买入量
+	SellVolume Decimal      // 主动卖出量
+	Imbalance  Decimal      // 主动买卖量差
+	Trades     int          // 成交笔数
+	FirstID    int64        // 首笔成交ID
+	LastID     int64        // 末笔成交ID
+
+	priceVolume Decimal // 价格与数量乘积之和
+}
+
+// VWAP 成交量加权平均价
+func (b TradeBar) VWAP() Decimal {
+	if b.Volume.IsZero() {
+		return b.Close
+	}
+	return b.priceVolume.Div(b.Volume)
+}
+
+// TradeBarHandler 成交K线完成处理函数
+type TradeBarHandler func(bar TradeBar)
+
+// TradeGap 成交ID缺失区间
+type TradeGap struct {
+	FromID  int64 // 首个缺失的成交ID
+	ToID    int64 // 最后一个缺失的成交ID
+	Missing int64 // 缺失的成交数量
+}
+
+// TapeEntry 有序成交流中的一笔成交
+type TapeEntry struct {
+	Trade      Trade
+	Backfilled bool      // 是否由GetTrades回补
+	Gap        *TradeGap // 该成交之前无法回补的缺失，无缺失时为nil
+}
+
+// TapeHandler 成交流处理函数
+type TapeHandler func(entry TapeEntry)
+
+// TradeTapeStats 成交流统计
+type TradeTapeStats struct {
+	Delivered  int64 // 已输出的成交数
+	Backfilled int64 // 回补的成交数
+	Duplicates int64 // 丢弃的重复成交数
+	Late       int64 // 丢弃的迟到成交数（ID早于已输出成交）
+	Gaps       int64 // 无法回补的缺失次数
+	Missing    int64 // 无法回补的成交总数
+}
+
+// TradeTapeConfig 成交流配置
+type TradeTapeConfig struct {
+	BackfillSize    int           // 回补时GetTrades获取的成交数量，0表示不回补
+	BackfillTimeout time.Duration // 单次回补超时时间，超时后按无法回补处理缓存的成交
+	DedupSize       int           // 用于去重的最近成交ID数量
+}
+
+// DefaultTradeTapeConfig 默认成交流配置
+func DefaultTradeTapeConfig() *TradeTapeConfig {
+	return &TradeTapeConfig{
+		BackfillSize:    2000,
+		BackfillTimeout: 10 * time.Second,
+		DedupSize:       DefaultTradeDedupSize,
+	}
 }
 
 // IndexPriceComponent 指数价格成分
 type IndexPriceComponent struct {
-	Symbol    string `json:"symbol"`    // 交易对
-	Price     string `json:"price"`     // 价格
-	Weight    string `json:"weight"`    // 权重
-	Exchange  string `json:"exchange"`  // 交易所
-	Timestamp int64  `json:"timestamp"` // 时间戳
+	Symbol    string    `json:"symbol"`    // 交易对
+	Price     string    `json:"price"`     // 价格
+	Weight    string    `json:"weight"`    // 权重
+	Exchange  string    `json:"exchange"`  // 交易所
+	Timestamp Timestamp `json:"timestamp"` // 时间戳
 }
 
 // FundingRate 资金费率
 type FundingRate struct {
-	Symbol      string `json:"symbol"`      // 交易对
-	FundingRate string `json:"fundingRate"` // 资金费率
-	FundingTime int64  `json:"fundingTime"` // 资金费用时间
-	MarkPrice   string `json:"markPrice"`   // 标记价格
-	IndexPrice  string `json:"indexPrice"`  // 指数价格
+	Symbol      string    `json:"symbol"`      // 交易对
+	FundingRate string    `json:"fundingRate"` // 资金费率
+	FundingTime Timestamp `json:"fundingTime"` // 资金费用时间
+	MarkPrice   string    `json:"markPrice"`   // 标记价格
+	IndexPrice  string    `json:"indexPrice"`  // 指数价格
 }
 
 // TickerData 行情ticker数据（基于HOTCOIN API实际响应格式）
@@ -108,22 +373,105 @@ type TickerData struct {
 
 // GeckoContract Gecko格式的合约信息
 type GeckoContract struct {
-	Ticker         string  `json:"ticker"`          // 交易对标识
-	BaseCurrency   string  `json:"base_currency"`   // 基础货币
-	QuoteCurrency  string  `json:"quote_currency"`  // 计价货币
-	LastPrice      float64 `json:"last_price"`      // 最新价格
-	BaseVolume     float64 `json:"base_volume"`     // 基础货币成交量
-	QuoteVolume    float64 `json:"quote_volume"`    // 计价货币成交量
-	Bid            float64 `json:"bid"`             // 买一价
-	Ask            float64 `json:"ask"`             // 卖一价
-	High           float64 `json:"high"`            // 最高价
-	Low            float64 `json:"low"`             // 最低价
-	ProductType    string  `json:"product_type"`    // 产品类型
-	OpenInterest   float64 `json:"open_interest"`   // 持仓量
-	IndexPrice     float64 `json:"index_price"`     // 指数价格
-	IndexCurrency  string  `json:"index_currency"`  // 指数货币
-	StartTimestamp int64   `json:"start_timestamp"` // 开始时间戳
-	EndTimestamp   int64   `json:"end_timestamp"`   // 结束时间戳
-	FundingRate    float64 `json:"funding_rate"`    // 资金费率
-	ContractType   string  `json:"contract_type"`   // 合约类型
+	Ticker         string    `json:"ticker"`          // 交易对标识
+	BaseCurrency   string    `json:"base_currency"`   // 基础货币
+	QuoteCurrency  string    `json:"quote_currency"`  // 计价货币
+	LastPrice      float64   `json:"last_price"`      // 最新价格
+	BaseVolume     float64   `json:"base_volume"`     // 基础货币成交量
+	QuoteVolume    float64   `json:"quote_volume"`    // 计价货币成交量
+	Bid            float64   `json:"bid"`             // 买一价
+	Ask            float64   `json:"ask"`             // 卖一价
+	High           float64   `json:"high"`            // 最高价
+	Low            float64   `json:"low"`             // 最低价
+	ProductType    string    `json:"product_type"`    // 产品类型
+	OpenInterest   float64   `json:"open_interest"`   // 持仓量
+	IndexPrice     float64   `json:"index_price"`     // 指数价格
+	IndexCurrency  string    `json:"index_currency"`  // 指数货币
+	StartTimestamp Timestamp `json:"start_timestamp"` // 开始时间戳
+	EndTimestamp   Timestamp `json:"end_timestamp"`   // 结束时间戳
+	FundingRate    float64   `json:"funding_rate"`    // 资金费率
+	ContractType   string    `json:"contract_type"`   // 合约类型
+}
+
+// ContractInfo 合约注册表中的合约元数据
+type ContractInfo struct {
+	Code         string           // 规范合约代码，如btcusdt
+	Symbol       string           // 展示用交易对，如BTC-USDT
+	Base         string           // 基础货币
+	Quote        string           // 计价货币
+	PriceTick    Decimal          // 最小变动价位
+	PriceDigits  int              // 价格小数位
+	AmountDigits int              // 数量小数位
+	MinTradeUnit Decimal          // 最小交易单位
+	UnitAmount   Decimal          // 一张合约对应的quote面值
+	ContractSize Decimal          // 合约面值，来自合约要素
+	MaxLever     int              // 最大杠杆
+	Inverse      bool             // 是否为反向合约
+	Contract     Contract         // 原始合约信息
+	Element      *ContractElement // 原始合约要素，未加载时为nil
+}
+
+// ContractRegistryConfig 合约注册表配置
+type ContractRegistryConfig struct {
+	RefreshInterval time.Duration                // 定时刷新间隔，0表示不自动刷新
+	LoadElements    bool                         // 是否同时加载合约要素
+	Tradable        func(info ContractInfo) bool // 判断合约是否可交易，下单前校验，nil表示不校验
+}
+
+// DefaultContractRegistryConfig 默认合约注册表配置
+func DefaultContractRegistryConfig() *ContractRegistryConfig {
+	return &ContractRegistryConfig{
+		RefreshInterval: 10 * time.Minute,
+		LoadElements:    true,
+		Tradable:        DefaultContractTradable,
+	}
 }
+
+// ContractEventType 合约变更事件类型
+type ContractEventType int
+
+const (
+	ContractListed        ContractEventType = 1 // 新上线合约
+	ContractDelisted      ContractEventType = 2 // 合约下线
+	ContractStatusChanged ContractEventType = 3 // 交易状态变化
+	ContractLeverChanged  ContractEventType = 4 // 最大杠杆变化
+	ContractParamsChanged ContractEventType = 5 // 价格步长、交易单位等参数变化
+)
+
+// String 返回事件类型名称
+func (t ContractEventType) String() string {
+	switch t {
+	case ContractListed:
+		return "listed"
+	case ContractDelisted:
+		return "delisted"
+	case ContractStatusChanged:
+		return "status_changed"
+	case ContractLeverChanged:
+		return "lever_changed"
+	case ContractParamsChanged:
+		return "params_changed"
+	default:
+		return fmt.Sprintf("ContractEventType(%d)", int(t))
+	}
+}
+
+// ContractFieldChange 合约字段变化
+type ContractFieldChange struct {
+	Field string // 字段名
+	Old   string // 变化前的值
+	New   string // 变化后的值
+}
+
+// ContractEvent 合约变更事件
+type ContractEvent struct {
+	Type    ContractEventType     // 事件类型
+	Code    string                // 规范合约代码
+	Old     *ContractInfo         // 变化前的合约，新上线时为nil
+	New     *ContractInfo         // 变化后的合约，下线时为nil
+	Changes []ContractFieldChange // 变化的字段，上线和下线事件为空
+	Time    time.Time             // 检测到变化的时间
+}
+
+// ContractEventHandler 合约变更事件处理函数
+type ContractEventHandler func(event ContractEvent)
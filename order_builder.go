@@ -0,0 +1,247 @@
+package hotcoin
+
+import "fmt"
+
+// OrderBuilder 链式构建下单、计划委托和止盈止损请求，价格和数量使用Decimal
+//
+//	req, err := hotcoin.NewOrder("btcusdt").Buy().Open().Limit(price).Volume(volume).Leverage(10).Build()
+type OrderBuilder struct {
+	order        OrderPlaceRequest
+	triggerType  TriggerType
+	triggerPrice string
+	tp           stopLeg
+	sl           stopLeg
+}
+
+// stopLeg 止盈或止损设置
+type stopLeg struct {
+	triggerPrice string
+	orderPrice   string
+	priceType    OrderPriceType
+}
+
+// NewOrder 创建订单构建器
+func NewOrder(symbol string) *OrderBuilder {
+	return &OrderBuilder{order: OrderPlaceRequest{Symbol: symbol}}
+}
+
+// NewMarketClose 市价平仓预设：按持仓方向反向平仓
+func NewMarketClose(symbol string, position PositionSide, volume Decimal) *OrderBuilder {
+	return NewOrder(symbol).CloseSide(position).Market().Volume(volume)
+}
+
+// NewReduceOnlyClose 只减仓平仓预设：限价平仓，offset为close，成交只会减少持仓
+func NewReduceOnlyClose(symbol string, position PositionSide, price, volume Decimal) *OrderBuilder {
+	return NewOrder(symbol).CloseSide(position).Limit(price).Volume(volume)
+}
+
+// NewPostOnlyEntry 只做maker开仓预设：以post_only挂单开仓，会立即成交时由交易所撤单
+func NewPostOnlyEntry(symbol string, side OrderSide, price, volume Decimal) *OrderBuilder {
+	return NewOrder(symbol).Side(side).Open().PostOnly(price).Volume(volume)
+}
+
+// Buy 买入
+func (b *OrderBuilder) Buy() *OrderBuilder {
+	b.order.Direction = OrderSideBuy
+	return b
+}
+
+// Sell 卖出
+func (b *OrderBuilder) Sell() *OrderBuilder {
+	b.order.Direction = OrderSideSell
+	return b
+}
+
+// Side 设置买卖方向
+func (b *OrderBuilder) Side(side OrderSide) *OrderBuilder {
+	b.order.Direction = side
+	return b
+}
+
+// Open 开仓
+func (b *OrderBuilder) Open() *OrderBuilder {
+	b.order.Offset = OrderOffsetOpen
+	return b
+}
+
+// Close 平仓
+func (b *OrderBuilder) Close() *OrderBuilder {
+	b.order.Offset = OrderOffsetClose
+	return b
+}
+
+// CloseSide 平掉指定方向的持仓，多头持仓卖出平仓，空头持仓买入平仓
+func (b *OrderBuilder) CloseSide(position PositionSide) *OrderBuilder {
+	b.order.Offset = OrderOffsetClose
+	if position == PositionSideShort {
+		b.order.Direction = OrderSideBuy
+	} else {
+		b.order.Direction = OrderSideSell
+	}
+	return b
+}
+
+// Limit 限价
+func (b *OrderBuilder) Limit(price Decimal) *OrderBuilder {
+	return b.priced(OrderPriceTypeLimit, price)
+}
+
+// PostOnly 只做maker
+func (b *OrderBuilder) PostOnly(price Decimal) *OrderBuilder {
+	return b.priced(OrderPriceTypePostOnly, price)
+}
+
+// IOC 立即成交并撤销剩余
+func (b *OrderBuilder) IOC(price Decimal) *OrderBuilder {
+	return b.priced(OrderPriceTypeIOC, price)
+}
+
+// FOK 全部成交或立即撤销
+func (b *OrderBuilder) FOK(price Decimal) *OrderBuilder {
+	return b.priced(OrderPriceTypeFOK, price)
+}
+
+// Market 市价
+func (b *OrderBuilder) Market() *OrderBuilder {
+	b.order.OrderPriceType = OrderPriceTypeMarket
+	b.order.Price = ""
+	return b
+}
+
+// Opponent 对手价
+func (b *OrderBuilder) Opponent() *OrderBuilder {
+	b.order.OrderPriceType = OrderPriceTypeOpponent
+	b.order.Price = ""
+	return b
+}
+
+// priced 设置需要委托价格的价格类型
+func (b *OrderBuilder) priced(priceType OrderPriceType, price Decimal) *OrderBuilder {
+	b.order.OrderPriceType = priceType
+	b.order.SetPrice(price)
+	return b
+}
+
+// Volume 设置数量
+func (b *OrderBuilder) Volume(volume Decimal) *OrderBuilder {
+	b.order.SetVolume(volume)
+	return b
+}
+
+// Leverage 设置杠杆倍数
+func (b *OrderBuilder) Leverage(leverRate int) *OrderBuilder {
+	b.order.LeverRate = leverRate
+	return b
+}
+
+// ClientID 设置客户自定义订单ID
+func (b *OrderBuilder) ClientID(clientOrderID string) *OrderBuilder {
+	b.order.ClientOrderID = clientOrderID
+	return b
+}
+
+// ContractType 设置合约类型
+func (b *OrderBuilder) ContractType(contractType string) *OrderBuilder {
+	b.order.ContractType = contractType
+	return b
+}
+
+// ContractCode 设置合约代码
+func (b *OrderBuilder) ContractCode(contractCode string) *OrderBuilder {
+	b.order.ContractCode = contractCode
+	return b
+}
+
+// Trigger 设置计划委托的触发条件，用于BuildPlan
+func (b *OrderBuilder) Trigger(triggerType TriggerType, price Decimal) *OrderBuilder {
+	b.triggerType = triggerType
+	b.triggerPrice = price.String()
+	return b
+}
+
+// TakeProfit 设置止盈，priceType为空时按限价，市价和对手价时price被忽略，用于BuildStop
+func (b *OrderBuilder) TakeProfit(trigger, price Decimal, priceType OrderPriceType) *OrderBuilder {
+	b.tp = newStopLeg(trigger, price, priceType)
+	return b
+}
+
+// StopLoss 设置止损，priceType为空时按限价，市价和对手价时price被忽略，用于BuildStop
+func (b *OrderBuilder) StopLoss(trigger, price Decimal, priceType OrderPriceType) *OrderBuilder {
+	b.sl = newStopLeg(trigger, price, priceType)
+	return b
+}
+
+// newStopLeg 创建止盈止损设置
+func newStopLeg(trigger, price Decimal, priceType OrderPriceType) stopLeg {
+	leg := stopLeg{triggerPrice: trigger.String(), priceType: priceTypeOrLimit(priceType)}
+	if leg.priceType.RequiresPrice() {
+		leg.orderPrice = price.String()
+	}
+	return leg
+}
+
+// Build 生成并校验下单请求
+func (b *OrderBuilder) Build() (*OrderPlaceRequest, error) {
+	req := b.order
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	return &req, nil
+}
+
+// BuildFor 按合约规则取整价格和数量后生成下单请求
+func (b *OrderBuilder) BuildFor(info ContractInfo) (*OrderPlaceRequest, error) {
+	req := b.order
+	if err := info.NormalizeOrder(&req); err != nil {
+		return nil, err
+	}
+	if err := info.ValidateOrder(&req); err != nil {
+		return nil, err
+	}
+	return &req, nil
+}
+
+// BuildPlan 生成并校验计划委托请求，需先调用Trigger
+func (b *OrderBuilder) BuildPlan() (*PlanOrderRequest, error) {
+	if b.triggerPrice == "" {
+		return nil, fmt.Errorf("trigger is required for plan order")
+	}
+	req := &PlanOrderRequest{
+		Symbol:         b.order.Symbol,
+		ContractType:   b.order.ContractType,
+		ContractCode:   b.order.ContractCode,
+		TriggerType:    b.triggerType,
+		TriggerPrice:   b.triggerPrice,
+		OrderPrice:     b.order.Price,
+		OrderPriceType: b.order.OrderPriceType,
+		Volume:         b.order.Volume,
+		Direction:      b.order.Direction,
+		Offset:         b.order.Offset,
+		LeverRate:      b.order.LeverRate,
+	}
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// BuildStop 生成并校验止盈止损请求，方向为平仓委托的买卖方向，需先调用TakeProfit或StopLoss
+func (b *OrderBuilder) BuildStop() (*StopOrderRequest, error) {
+	req := &StopOrderRequest{
+		Symbol:           b.order.Symbol,
+		ContractCode:     b.order.ContractCode,
+		ContractType:     b.order.ContractType,
+		Direction:        b.order.Direction,
+		Volume:           b.order.Volume,
+		TpTriggerPrice:   b.tp.triggerPrice,
+		TpOrderPrice:     b.tp.orderPrice,
+		TpOrderPriceType: b.tp.priceType,
+		SlTriggerPrice:   b.sl.triggerPrice,
+		SlOrderPrice:     b.sl.orderPrice,
+		SlOrderPriceType: b.sl.priceType,
+	}
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
@@ -0,0 +1,90 @@
+package hotcoin
+
+import (
+	"testing"
+)
+
+func TestOrderBuilder(t *testing.T) {
+	req, err := NewOrder("btcusdt").Buy().Open().Limit(MustDecimal("37000.50")).Volume(MustDecimal("2")).
+		Leverage(10).ClientID("my-1").Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := OrderPlaceRequest{Symbol: "btcusdt", Direction: OrderSideBuy, Offset: OrderOffsetOpen, Price: "37000.50",
+		Volume: "2", LeverRate: 10, ClientOrderID: "my-1", OrderPriceType: OrderPriceTypeLimit}
+	if *req != want {
+		t.Errorf("got %+v, want %+v", *req, want)
+	}
+
+	if _, err := NewOrder("btcusdt").Buy().Open().Volume(MustDecimal("1")).Build(); err == nil {
+		t.Error("expected missing price error for default limit order")
+	}
+	if _, err := NewOrder("btcusdt").Open().Market().Volume(MustDecimal("1")).Build(); err == nil {
+		t.Error("expected missing direction error")
+	}
+
+	builder := NewOrder("btcusdt").Sell().Limit(MustDecimal("100")).Market().Volume(MustDecimal("1"))
+	if req, err := builder.Build(); err != nil || req.Price != "" || req.OrderPriceType != OrderPriceTypeMarket {
+		t.Errorf("market should clear price: %+v, %v", req, err)
+	}
+}
+
+func TestOrderPresets(t *testing.T) {
+	closeLong, err := NewMarketClose("btcusdt", PositionSideLong, MustDecimal("3")).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if closeLong.Direction != OrderSideSell || closeLong.Offset != OrderOffsetClose || closeLong.OrderPriceType != OrderPriceTypeMarket {
+		t.Errorf("unexpected market close: %+v", closeLong)
+	}
+
+	closeShort, err := NewReduceOnlyClose("btcusdt", PositionSideShort, MustDecimal("36000"), MustDecimal("1")).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if closeShort.Direction != OrderSideBuy || closeShort.Offset != OrderOffsetClose || closeShort.Price != "36000" {
+		t.Errorf("unexpected reduce-only close: %+v", closeShort)
+	}
+
+	entry, err := NewPostOnlyEntry("btcusdt", OrderSideSell, MustDecimal("38000"), MustDecimal("1")).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry.Offset != OrderOffsetOpen || entry.OrderPriceType != OrderPriceTypePostOnly {
+		t.Errorf("unexpected post-only entry: %+v", entry)
+	}
+
+	normalized, err := NewPostOnlyEntry("btcusdt", OrderSideBuy, MustDecimal("37000.37"), MustDecimal("2.9")).
+		BuildFor(testContractInfo())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if normalized.Price != "37000" || normalized.Volume != "2" {
+		t.Errorf("unexpected normalized entry: %+v", normalized)
+	}
+}
+
+func TestOrderBuilderPlanAndStop(t *testing.T) {
+	builder := NewOrder("btcusdt").Buy().Open().Market().Volume(MustDecimal("1"))
+	if _, err := builder.BuildPlan(); err == nil {
+		t.Error("expected missing trigger error")
+	}
+	plan, err := builder.Trigger(TriggerTypeGE, MustDecimal("38000")).BuildPlan()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plan.TriggerType != TriggerTypeGE || plan.TriggerPrice != "38000" || plan.OrderPriceType != OrderPriceTypeMarket {
+		t.Errorf("unexpected plan order: %+v", plan)
+	}
+
+	stop, err := NewOrder("btcusdt").CloseSide(PositionSideLong).Volume(MustDecimal("1")).
+		TakeProfit(MustDecimal("40000"), MustDecimal("39990"), "").
+		StopLoss(MustDecimal("35000"), Decimal{}, OrderPriceTypeOpponent).BuildStop()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stop.Direction != OrderSideSell || stop.TpOrderPrice != "39990" || stop.TpOrderPriceType != OrderPriceTypeLimit ||
+		stop.SlOrderPrice != "" || stop.SlOrderPriceType != OrderPriceTypeOpponent {
+		t.Errorf("unexpected stop order: %+v", stop)
+	}
+}
@@ -0,0 +1,184 @@
+package hotcoin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrOrderOutcomeUnknown 下单结果未知且无法通过查询确认，需人工或对账程序处理，不会自动重试
+var ErrOrderOutcomeUnknown = errors.New("order outcome unknown")
+
+// ClientOrderIDGenerator 客户订单ID生成器
+// ID为前缀加严格递增的数字（毫秒时间戳×1000+序号），同一进程内不重复，重启后按时间继续递增
+type ClientOrderIDGenerator struct {
+	prefix string
+	mutex  sync.Mutex
+	last   int64
+}
+
+// NewClientOrderIDGenerator 创建客户订单ID生成器
+func NewClientOrderIDGenerator(prefix string) *ClientOrderIDGenerator {
+	return &ClientOrderIDGenerator{prefix: prefix}
+}
+
+// Next 生成下一个客户订单ID
+func (g *ClientOrderIDGenerator) Next() string {
+	g.mutex.Lock()
+	next := time.Now().UnixMilli() * 1000
+	if next <= g.last {
+		next = g.last + 1
+	}
+	g.last = next
+	g.mutex.Unlock()
+
+	return g.prefix + strconv.FormatInt(next, 10)
+}
+
+// OrderSubmitter 幂等下单
+// 每笔订单都分配客户订单ID；下单结果未知（超时、连接中断、响应无法解析或服务端5xx错误）时先按客户订单ID查询，
+// 确认订单不存在后才使用同一ID重试，无法确认时返回ErrOrderOutcomeUnknown，保证最多下单一次
+type OrderSubmitter struct {
+	config OrderSubmitterConfig
+	ids    *ClientOrderIDGenerator
+	place  func(req *OrderPlaceRequest) (*OrderPlaceResponse, error)
+	lookup func(symbol, clientOrderID string) ([]Order, error)
+}
+
+// NewOrderSubmitter 创建幂等下单器
+func NewOrderSubmitter(client *Client, config *OrderSubmitterConfig) *OrderSubmitter {
+	if config == nil {
+		config = DefaultOrderSubmitterConfig()
+	}
+
+	s := &OrderSubmitter{
+		config: *config,
+		ids:    NewClientOrderIDGenerator(config.Prefix),
+	}
+	if s.config.MaxAttempts <= 0 {
+		s.config.MaxAttempts = 1
+	}
+	if s.config.LookupAttempts <= 0 {
+		s.config.LookupAttempts = 1
+	}
+	if client != nil {
+		s.place = client.Trading.PlaceOrder
+		s.lookup = func(symbol, clientOrderID string) ([]Order, error) {
+			return client.Trading.GetOrderInfo(symbol, "", clientOrderID)
+		}
+	}
+	return s
+}
+
+// NextClientOrderID 生成带前缀的客户订单ID
+func (s *OrderSubmitter) NextClientOrderID() string {
+	return s.ids.Next()
+}
+
+// Submit 下单，请求未设置客户订单ID时自动分配，不修改传入的请求
+// 交易所明确拒绝或本地校验失败时直接返回错误；结果未知且无法确认时返回ErrOrderOutcomeUnknown
+func (s *OrderSubmitter) Submit(ctx context.Context, req *OrderPlaceRequest) (*OrderSubmitResult, error) {
+	if req == nil {
+		return nil, fmt.Errorf("request is required")
+	}
+	if s.place == nil {
+		return nil, fmt.Errorf("client is required")
+	}
+
+	order := *req
+	if order.ClientOrderID == "" {
+		order.ClientOrderID = s.ids.Next()
+	}
+	result := &OrderSubmitResult{ClientOrderID: order.ClientOrderID}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+
+		result.Attempts++
+		resp, err := s.place(&order)
+		if err == nil {
+			result.Response = resp
+			result.OrderID = resp.OrderID
+			if result.OrderID == "" {
+				result.OrderID = resp.OrderIDStr
+			}
+			return result, nil
+		}
+		if !IsOutcomeUnknown(err) {
+			return result, err
+		}
+
+		// 结果未知，确认订单是否已存在
+		found, lookupErr := s.resolve(ctx, order.Symbol, order.ClientOrderID)
+		if lookupErr != nil {
+			return result, fmt.Errorf("%w: client_order_id=%s: place: %v; lookup: %v",
+				ErrOrderOutcomeUnknown, order.ClientOrderID, err, lookupErr)
+		}
+		if found != nil {
+			result.Order = found
+			result.OrderID = found.OrderID
+			if result.OrderID == "" {
+				result.OrderID = found.OrderIDStr
+			}
+			result.Recovered = true
+			return result, nil
+		}
+
+		// 已确认订单不存在，可以安全重试
+		if result.Attempts >= s.config.MaxAttempts {
+			return result, fmt.Errorf("place order failed after %d attempts: %w", result.Attempts, err)
+		}
+		if err := sleepContext(ctx, s.config.RetryDelay); err != nil {
+			return result, err
+		}
+	}
+}
+
+// resolve 按客户订单ID查询订单，返回nil表示已确认不存在，返回错误表示无法确认
+// 订单可能在查询间隙才落库，因此全部LookupAttempts次查询都正常返回空结果才视为不存在，
+// 任意一次查询失败都保留其错误
+func (s *OrderSubmitter) resolve(ctx context.Context, symbol, clientOrderID string) (*Order, error) {
+	if s.lookup == nil {
+		return nil, fmt.Errorf("lookup is not available")
+	}
+
+	var lookupErr error
+	for i := 0; i < s.config.LookupAttempts; i++ {
+		if err := sleepContext(ctx, s.config.LookupDelay); err != nil {
+			return nil, err
+		}
+
+		orders, err := s.lookup(symbol, clientOrderID)
+		if err != nil {
+			if s.config.IsNotFound == nil || !s.config.IsNotFound(err) {
+				lookupErr = err
+			}
+			continue
+		}
+		if len(orders) > 0 {
+			return &orders[0], nil
+		}
+	}
+	return nil, lookupErr
+}
+
+// sleepContext 等待d或ctx结束
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
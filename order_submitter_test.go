@@ -0,0 +1,241 @@
+package hotcoin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func testSubmitter(place func(req *OrderPlaceRequest) (*OrderPlaceResponse, error),
+	lookup func(symbol, clientOrderID string) ([]Order, error)) *OrderSubmitter {
+	s := NewOrderSubmitter(nil, &OrderSubmitterConfig{Prefix: "7", MaxAttempts: 3, LookupAttempts: 2})
+	s.place = place
+	s.lookup = lookup
+	return s
+}
+
+func timeoutError() error {
+	return fmt.Errorf("send request: %w", &outcomeUnknownError{err: context.DeadlineExceeded})
+}
+
+func testSubmitRequest() *OrderPlaceRequest {
+	return &OrderPlaceRequest{Symbol: "btcusdt", Direction: OrderSideBuy, Offset: OrderOffsetOpen, Volume: "1", Price: "37000"}
+}
+
+func TestClientOrderIDGenerator(t *testing.T) {
+	ids := NewClientOrderIDGenerator("12")
+	seen := make(map[string]bool)
+	prev := ""
+	for i := 0; i < 5000; i++ {
+		id := ids.Next()
+		if seen[id] || !strings.HasPrefix(id, "12") || (prev != "" && id <= prev) {
+			t.Fatalf("unexpected id %s after %s", id, prev)
+		}
+		seen[id] = true
+		prev = id
+	}
+	if len(prev) > 18 {
+		t.Errorf("numeric id %s does not fit int64", prev)
+	}
+}
+
+func TestOrderSubmitterSuccess(t *testing.T) {
+	var placed []string
+	s := testSubmitter(func(req *OrderPlaceRequest) (*OrderPlaceResponse, error) {
+		placed = append(placed, req.ClientOrderID)
+		return &OrderPlaceResponse{OrderID: "1001", ClientOrderID: req.ClientOrderID}, nil
+	}, nil)
+
+	req := testSubmitRequest()
+	result, err := s.Submit(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.OrderID != "1001" || result.Attempts != 1 || result.Recovered || !strings.HasPrefix(result.ClientOrderID, "7") {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	if req.ClientOrderID != "" || len(placed) != 1 || placed[0] != result.ClientOrderID {
+		t.Errorf("request should not be modified and id should be sent: %v", placed)
+	}
+}
+
+func TestOrderSubmitterRecoversExistingOrder(t *testing.T) {
+	placeCalls := 0
+	s := testSubmitter(func(req *OrderPlaceRequest) (*OrderPlaceResponse, error) {
+		placeCalls++
+		return nil, timeoutError()
+	}, func(symbol, clientOrderID string) ([]Order, error) {
+		return []Order{{OrderID: "2002", Symbol: symbol}}, nil
+	})
+
+	result, err := s.Submit(context.Background(), testSubmitRequest())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if placeCalls != 1 || !result.Recovered || result.OrderID != "2002" || result.Order == nil {
+		t.Errorf("expected recovered order without retry, got %+v after %d calls", result, placeCalls)
+	}
+}
+
+func TestOrderSubmitterRetriesWhenNotFound(t *testing.T) {
+	var ids []string
+	s := testSubmitter(func(req *OrderPlaceRequest) (*OrderPlaceResponse, error) {
+		ids = append(ids, req.ClientOrderID)
+		if len(ids) == 1 {
+			return nil, timeoutError()
+		}
+		return &OrderPlaceResponse{OrderIDStr: "3003"}, nil
+	}, func(symbol, clientOrderID string) ([]Order, error) {
+		return nil, nil
+	})
+
+	result, err := s.Submit(context.Background(), testSubmitRequest())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Attempts != 2 || result.OrderID != "3003" || len(ids) != 2 || ids[0] != ids[1] {
+		t.Errorf("expected retry with same client order id, got %+v %v", result, ids)
+	}
+}
+
+func TestOrderSubmitterStopsWhenOutcomeUnknown(t *testing.T) {
+	placeCalls := 0
+	s := testSubmitter(func(req *OrderPlaceRequest) (*OrderPlaceResponse, error) {
+		placeCalls++
+		return nil, timeoutError()
+	}, func(symbol, clientOrderID string) ([]Order, error) {
+		return nil, timeoutError()
+	})
+
+	_, err := s.Submit(context.Background(), testSubmitRequest())
+	if !errors.Is(err, ErrOrderOutcomeUnknown) || placeCalls != 1 {
+		t.Errorf("expected ErrOrderOutcomeUnknown after 1 call, got %v after %d calls", err, placeCalls)
+	}
+
+	// 交易所明确拒绝时不查询也不重试
+	lookups := 0
+	s = testSubmitter(func(req *OrderPlaceRequest) (*OrderPlaceResponse, error) {
+		return nil, &ErrorResponse{Code: 1001, Msg: "insufficient margin"}
+	}, func(symbol, clientOrderID string) ([]Order, error) {
+		lookups++
+		return nil, nil
+	})
+	_, err = s.Submit(context.Background(), testSubmitRequest())
+	var apiErr *ErrorResponse
+	if !errors.As(err, &apiErr) || lookups != 0 {
+		t.Errorf("expected API error without lookup, got %v (%d lookups)", err, lookups)
+	}
+}
+
+func TestOrderSubmitterLookupMustBeCleanEveryTime(t *testing.T) {
+	// 任意一次查询失败都不能视为订单不存在，无论失败发生在空结果之前还是之后
+	sequences := [][]error{
+		{timeoutError(), nil},
+		{nil, timeoutError()},
+	}
+	for _, sequence := range sequences {
+		placeCalls, lookups := 0, 0
+		s := testSubmitter(func(req *OrderPlaceRequest) (*OrderPlaceResponse, error) {
+			placeCalls++
+			return nil, timeoutError()
+		}, func(symbol, clientOrderID string) ([]Order, error) {
+			err := sequence[lookups]
+			lookups++
+			return nil, err
+		})
+
+		_, err := s.Submit(context.Background(), testSubmitRequest())
+		if !errors.Is(err, ErrOrderOutcomeUnknown) || placeCalls != 1 || lookups != 2 {
+			t.Errorf("lookup errors %v: expected ErrOrderOutcomeUnknown after 1 call and 2 lookups, got %v after %d calls, %d lookups",
+				sequence, err, placeCalls, lookups)
+		}
+	}
+}
+
+func TestIsOutcomeUnknown(t *testing.T) {
+	config := DefaultConfig()
+	config.BaseURL = "http://127.0.0.1:1"
+	client := NewClientWithConfig(config)
+
+	_, err := client.Trading.PlaceOrder(testSubmitRequest())
+	if err == nil || !IsOutcomeUnknown(err) {
+		t.Errorf("expected outcome unknown error, got %v", err)
+	}
+	if IsOutcomeUnknown(&ErrorResponse{Code: 1001}) || IsOutcomeUnknown((&OrderPlaceRequest{}).Validate()) {
+		t.Error("API and validation errors are not outcome unknown")
+	}
+}
+
+// newTestRESTClient 创建指向测试服务端的客户端，placeBody为下单接口的HTTP状态码和响应
+func newTestRESTClient(t *testing.T, status int, placeBody string, orders string) *Client {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/perpetual/orders":
+			w.WriteHeader(status)
+			fmt.Fprint(w, placeBody)
+		case "/api/v1/perpetual/orders/info":
+			fmt.Fprintf(w, `{"code":200,"data":{"status":"ok","data":%s}}`, orders)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	config := DefaultConfig()
+	config.BaseURL = server.URL
+	return NewClientWithConfig(config)
+}
+
+func TestPlaceOrderOutcomeUnknownResponses(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		unknown bool
+	}{
+		{"gateway error", http.StatusBadGateway, `{"code":502,"msg":"bad gateway"}`, true},
+		{"server error code", http.StatusOK, `{"code":503,"msg":"service unavailable"}`, true},
+		{"html gateway page", http.StatusGatewayTimeout, `<html>timeout</html>`, true},
+		{"undecodable data", http.StatusOK, `{"code":200,"data":{"status":"ok","data":{"order_id":[1]}}}`, true},
+		{"rejected", http.StatusBadRequest, `{"code":1001,"msg":"insufficient margin"}`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestRESTClient(t, tt.status, tt.body, "[]")
+			_, err := client.Trading.PlaceOrder(testSubmitRequest())
+			if err == nil || IsOutcomeUnknown(err) != tt.unknown {
+				t.Errorf("IsOutcomeUnknown(%v) = %v, want %v", err, IsOutcomeUnknown(err), tt.unknown)
+			}
+		})
+	}
+
+	client := newTestRESTClient(t, http.StatusBadGateway, `{"code":502,"msg":"bad gateway"}`, "[]")
+	_, err := client.Trading.PlaceOrder(testSubmitRequest())
+	var apiErr *ErrorResponse
+	if !errors.As(err, &apiErr) || apiErr.Code != 502 {
+		t.Errorf("expected wrapped API error, got %v", err)
+	}
+}
+
+func TestOrderSubmitterRecoversUnknownResponses(t *testing.T) {
+	for _, body := range []string{
+		`{"code":502,"msg":"bad gateway"}`,
+		`{"code":200,"data":{"status":"ok","data":{"order_id":[1]}}}`,
+	} {
+		client := newTestRESTClient(t, http.StatusOK, body, `[{"order_id":"1001","client_order_id":"1"}]`)
+		s := NewOrderSubmitter(client, &OrderSubmitterConfig{Prefix: "7", MaxAttempts: 3})
+
+		result, err := s.Submit(context.Background(), testSubmitRequest())
+		if err != nil {
+			t.Fatalf("submit %s: %v", body, err)
+		}
+		if !result.Recovered || result.OrderID != "1001" || result.Attempts != 1 {
+			t.Errorf("unexpected result for %s: %+v", body, result)
+		}
+	}
+}
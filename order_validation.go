@@ -0,0 +1,131 @@
+package hotcoin
+
+import "fmt"
+
+// validateVolume 校验数量为正数
+func validateVolume(value string) error {
+	if value == "" {
+		return fmt.Errorf("volume is required")
+	}
+	volume, err := decimalField("volume", value)
+	if err != nil {
+		return err
+	}
+	if volume.Sign() <= 0 {
+		return fmt.Errorf("volume must be positive")
+	}
+	return nil
+}
+
+// validateOrderPrice 校验价格类型及其对应的委托价格，价格类型为空时视为限价
+func validateOrderPrice(name string, priceType OrderPriceType, price string) error {
+	if priceType != "" && !priceType.Valid() {
+		return fmt.Errorf("invalid %s_type %q", name, priceType)
+	}
+	if priceType == "" || priceType.RequiresPrice() {
+		if price == "" {
+			return fmt.Errorf("%s is required for %s order", name, priceTypeOrLimit(priceType))
+		}
+	}
+	if price == "" {
+		return nil
+	}
+	d, err := decimalField(name, price)
+	if err != nil {
+		return err
+	}
+	if d.Sign() <= 0 {
+		return fmt.Errorf("%s must be positive", name)
+	}
+	return nil
+}
+
+// priceTypeOrLimit 价格类型为空时按限价处理
+func priceTypeOrLimit(priceType OrderPriceType) OrderPriceType {
+	if priceType == "" {
+		return OrderPriceTypeLimit
+	}
+	return priceType
+}
+
+// Validate 校验下单请求
+// 不校验买卖方向与开平方向的组合：合约为双向持仓，buy-open开多、sell-open开空、sell-close平多、buy-close平空，
+// 四种组合都合法；平仓单是否有足够持仓取决于账户状态，无法仅凭请求判断，由交易所校验
+func (r *OrderPlaceRequest) Validate() error {
+	if r.Symbol == "" {
+		return fmt.Errorf("symbol is required")
+	}
+	if r.Direction == "" {
+		return fmt.Errorf("direction is required")
+	}
+	if !r.Direction.Valid() {
+		return fmt.Errorf("invalid direction %q", r.Direction)
+	}
+	if r.Offset != "" && !r.Offset.Valid() {
+		return fmt.Errorf("invalid offset %q", r.Offset)
+	}
+	if err := validateVolume(r.Volume); err != nil {
+		return err
+	}
+	if r.LeverRate < 0 {
+		return fmt.Errorf("lever_rate cannot be negative")
+	}
+	return validateOrderPrice("price", r.OrderPriceType, r.Price)
+}
+
+// Validate 校验计划委托请求
+func (r *PlanOrderRequest) Validate() error {
+	if r.Symbol == "" {
+		return fmt.Errorf("symbol is required")
+	}
+	if r.TriggerType != "" && !r.TriggerType.Valid() {
+		return fmt.Errorf("invalid trigger_type %q", r.TriggerType)
+	}
+	if r.TriggerPrice == "" {
+		return fmt.Errorf("trigger_price is required")
+	}
+	trigger, err := decimalField("trigger_price", r.TriggerPrice)
+	if err != nil {
+		return err
+	}
+	if trigger.Sign() <= 0 {
+		return fmt.Errorf("trigger_price must be positive")
+	}
+	if r.Direction != "" && !r.Direction.Valid() {
+		return fmt.Errorf("invalid direction %q", r.Direction)
+	}
+	if r.Offset != "" && !r.Offset.Valid() {
+		return fmt.Errorf("invalid offset %q", r.Offset)
+	}
+	if err := validateVolume(r.Volume); err != nil {
+		return err
+	}
+	return validateOrderPrice("order_price", r.OrderPriceType, r.OrderPrice)
+}
+
+// Validate 校验止盈止损请求，止盈和止损至少设置一个
+func (r *StopOrderRequest) Validate() error {
+	if r.Symbol == "" {
+		return fmt.Errorf("symbol is required")
+	}
+	if !r.Direction.Valid() {
+		return fmt.Errorf("invalid direction %q", r.Direction)
+	}
+	if err := validateVolume(r.Volume); err != nil {
+		return err
+	}
+	if r.TpTriggerPrice == "" && r.SlTriggerPrice == "" {
+		return fmt.Errorf("tp_trigger_price or sl_trigger_price is required")
+	}
+	if r.TpTriggerPrice != "" {
+		if err := validateOrderPrice("tp_order_price", r.TpOrderPriceType, r.TpOrderPrice); err != nil {
+			return err
+		}
+	}
+	if r.SlTriggerPrice != "" {
+		if err := validateOrderPrice("sl_order_price", r.SlOrderPriceType, r.SlOrderPrice); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package hotcoin
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestOrderEnums(t *testing.T) {
+	if !OrderSideBuy.Valid() || OrderSide("long").Valid() || OrderSideBuy.Opposite() != OrderSideSell {
+		t.Error("unexpected order side")
+	}
+	if !OrderOffsetClose.Valid() || OrderOffset("").Valid() {
+		t.Error("unexpected order offset")
+	}
+	for _, pt := range []OrderPriceType{OrderPriceTypeLimit, OrderPriceTypeMarket, OrderPriceTypePostOnly,
+		OrderPriceTypeIOC, OrderPriceTypeFOK, OrderPriceTypeOpponent} {
+		if !pt.Valid() {
+			t.Errorf("%s should be valid", pt)
+		}
+	}
+	if OrderPriceTypeMarket.RequiresPrice() || !OrderPriceTypePostOnly.RequiresPrice() {
+		t.Error("unexpected RequiresPrice")
+	}
+
+	if OrderStatusFilled.String() != "filled" || OrderStatus(9).String() != "OrderStatus(9)" {
+		t.Errorf("unexpected status string: %s %s", OrderStatusFilled, OrderStatus(9))
+	}
+	if !OrderStatusPartialFilled.IsActive() || OrderStatusPartialFilled.IsFinal() {
+		t.Error("partial filled should be active")
+	}
+	if !OrderStatusCanceled.IsFinal() || OrderStatusCanceling.IsFinal() || OrderStatus(9).IsActive() {
+		t.Error("unexpected final status")
+	}
+	if OrderTypeLiquidation.String() != "liquidation" || OrderType(0).Valid() {
+		t.Error("unexpected order type")
+	}
+
+	var order Order
+	data := `{"direction":"sell","offset":"close","order_price_type":"post_only","order_type":3,"status":5}`
+	if err := json.Unmarshal([]byte(data), &order); err != nil {
+		t.Fatal(err)
+	}
+	if order.Direction != OrderSideSell || order.Offset != OrderOffsetClose ||
+		order.OrderPriceType != OrderPriceTypePostOnly || order.OrderType != OrderTypeLiquidation ||
+		order.Status != OrderStatusPartialCanceled {
+		t.Errorf("unexpected order: %+v", order)
+	}
+
+	var wsOrder WSOrderData
+	if err := json.Unmarshal([]byte(data), &wsOrder); err != nil {
+		t.Fatal(err)
+	}
+	if wsOrder.Direction != OrderSideSell || wsOrder.Offset != OrderOffsetClose ||
+		wsOrder.OrderPriceType != OrderPriceTypePostOnly || wsOrder.OrderType != OrderTypeLiquidation ||
+		!wsOrder.Status.IsFinal() {
+		t.Errorf("unexpected websocket order: %+v", wsOrder)
+	}
+
+	var plan PlanOrder
+	if err := json.Unmarshal([]byte(`{"order_type":1,"order_orig_type":2,"status":4}`), &plan); err != nil {
+		t.Fatal(err)
+	}
+	if plan.OrderType != OrderTypePlace || plan.OrderOrigType != OrderTypeCancel || !plan.Status.IsFinal() {
+		t.Errorf("unexpected plan order: %+v", plan)
+	}
+}
+
+func TestOrderPlaceRequestValidate(t *testing.T) {
+	valid := OrderPlaceRequest{
+		Symbol:         "BTC-USDT",
+		Direction:      OrderSideBuy,
+		Offset:         OrderOffsetOpen,
+		Volume:         "1",
+		Price:          "50000",
+		OrderPriceType: OrderPriceTypeLimit,
+	}
+	if err := valid.Validate(); err != nil {
+		t.Fatalf("valid request: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(r *OrderPlaceRequest)
+		want   string
+	}{
+		{"missing direction", func(r *OrderPlaceRequest) { r.Direction = "" }, "direction is required"},
+		{"invalid direction", func(r *OrderPlaceRequest) { r.Direction = "long" }, "invalid direction"},
+		{"invalid offset", func(r *OrderPlaceRequest) { r.Offset = "both" }, "invalid offset"},
+		{"invalid price type", func(r *OrderPlaceRequest) { r.OrderPriceType = "gtc" }, "invalid price_type"},
+		{"ioc without price", func(r *OrderPlaceRequest) { r.OrderPriceType = OrderPriceTypeIOC; r.Price = "" }, "price is required for ioc"},
+		{"default without price", func(r *OrderPlaceRequest) { r.OrderPriceType = ""; r.Price = "" }, "price is required for limit"},
+		{"zero volume", func(r *OrderPlaceRequest) { r.Volume = "0" }, "volume must be positive"},
+		{"bad volume", func(r *OrderPlaceRequest) { r.Volume = "1.2.3" }, "volume:"},
+	}
+	for _, tt := range tests {
+		req := valid
+		tt.modify(&req)
+		err := req.Validate()
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: got %v, want %q", tt.name, err, tt.want)
+		}
+	}
+
+	market := valid
+	market.OrderPriceType = OrderPriceTypeOpponent
+	market.Price = ""
+	if err := market.Validate(); err != nil {
+		t.Errorf("opponent order without price: %v", err)
+	}
+}
+
+func TestPlanAndStopOrderValidate(t *testing.T) {
+	plan := PlanOrderRequest{
+		Symbol:         "BTC-USDT",
+		TriggerType:    TriggerTypeGE,
+		TriggerPrice:   "51000",
+		OrderPriceType: OrderPriceTypeMarket,
+		Volume:         "2",
+		Direction:      OrderSideBuy,
+		Offset:         OrderOffsetOpen,
+	}
+	if err := plan.Validate(); err != nil {
+		t.Fatalf("valid plan order: %v", err)
+	}
+	plan.TriggerType = "gt"
+	if err := plan.Validate(); err == nil {
+		t.Error("expected invalid trigger type error")
+	}
+
+	stop := StopOrderRequest{Symbol: "BTC-USDT", Direction: OrderSideSell, Volume: "1"}
+	if err := stop.Validate(); err == nil {
+		t.Error("expected missing trigger price error")
+	}
+	stop.SlTriggerPrice = "45000"
+	stop.SlOrderPriceType = OrderPriceTypeOpponent
+	if err := stop.Validate(); err != nil {
+		t.Errorf("valid stop order: %v", err)
+	}
+
+	batch := &BatchOrderRequest{Orders: []OrderPlaceRequest{{Symbol: "BTC-USDT", Direction: OrderSideBuy, Volume: "-1"}}}
+	if _, err := (&TradingService{}).PlaceBatchOrders(batch); err == nil || !strings.Contains(err.Error(), "orders[0]") {
+		t.Errorf("expected indexed batch error, got %v", err)
+	}
+}
@@ -0,0 +1,568 @@
+package hotcoin
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// maxResyncBackoff 后台重新同步的最长重试间隔
+const maxResyncBackoff = 30 * time.Second
+
+// OrderBook 本地订单簿，由REST快照初始化并应用WebSocket深度推送
+// 增量模式下检测到版本缺口、或出现买卖盘交叉时自动重新同步，失败时按退避间隔重试直到一致，期间IsSynced为false；
+// 全量推送模式下版本缺口不影响订单簿，通过Gaps计数并在变化通知中标记。所有读取方法并发安全
+type OrderBook struct {
+	client *Client
+	symbol string
+	config *OrderBookConfig
+
+	mutex   sync.RWMutex
+	bids    []PriceLevel // 按价格从高到低
+	asks    []PriceLevel // 按价格从低到高
+	version int64
+	ts      int64
+	synced  bool
+	gaps    uint64
+	gap     bool // 最近一次推送之前有版本缺失
+
+	// 重新同步期间缓存的推送
+	syncing  bool
+	buffered []*WSDepthData
+
+	sub  *Subscription
+	stop chan struct{}
+
+	onUpdate OrderBookHandler
+	onResync ErrorHandler
+}
+
+// NewOrderBook 创建本地订单簿
+func NewOrderBook(client *Client, symbol string, config *OrderBookConfig) *OrderBook {
+	if config == nil {
+		config = DefaultOrderBookConfig()
+	}
+	if config.DepthType == "" {
+		config.DepthType = "step0"
+	}
+	if config.ResyncTimeout <= 0 {
+		config.ResyncTimeout = DefaultOrderBookConfig().ResyncTimeout
+	}
+	if config.ResyncBackoff <= 0 {
+		config.ResyncBackoff = DefaultOrderBookConfig().ResyncBackoff
+	}
+
+	ob := &OrderBook{
+		client: client,
+		symbol: symbol,
+		config: config,
+	}
+	if config.Snapshot == nil {
+		config.Snapshot = ob.restSnapshot
+	}
+	return ob
+}
+
+// OnUpdate 设置订单簿变化回调
+func (ob *OrderBook) OnUpdate(handler OrderBookHandler) {
+	ob.onUpdate = handler
+}
+
+// OnResync 设置重新同步回调，参数为触发重新同步的原因
+func (ob *OrderBook) OnResync(handler ErrorHandler) {
+	ob.onResync = handler
+}
+
+// Topic 获取订单簿对应的深度推送主题
+func (ob *OrderBook) Topic() string {
+	return fmt.Sprintf("market.%s.depth.%s", ob.symbol, ob.config.DepthType)
+}
+
+// Start 加载快照并开始接收WebSocket深度推送，WebSocket需已连接
+func (ob *OrderBook) Start(ctx context.Context) error {
+	ws := ob.client.WebSocket
+	if !ws.IsConnected() {
+		return fmt.Errorf("not connected")
+	}
+
+	ob.mutex.Lock()
+	if ob.sub != nil {
+		ob.mutex.Unlock()
+		return fmt.Errorf("order book already started")
+	}
+	// 快照加载完成前先缓存推送
+	ob.syncing = true
+	ob.sub = ws.Bus().Subscribe(ob.Topic(), ob.handleMessage)
+	ob.stop = make(chan struct{})
+	ob.mutex.Unlock()
+
+	subscribed := false
+	for _, topic := range ws.Topics() {
+		if topic == ob.Topic() {
+			subscribed = true
+			break
+		}
+	}
+	if !subscribed {
+		if err := ws.Subscribe(ob.Topic()); err != nil {
+			ob.Stop()
+			return err
+		}
+	}
+
+	if err := ob.sync(ctx); err != nil {
+		ob.Stop()
+		return err
+	}
+	return nil
+}
+
+// Stop 停止接收推送和后台重新同步，不取消WebSocket订阅
+func (ob *OrderBook) Stop() {
+	ob.mutex.Lock()
+	sub := ob.sub
+	ob.sub = nil
+	if ob.stop != nil {
+		close(ob.stop)
+		ob.stop = nil
+	}
+	ob.syncing = false
+	ob.buffered = nil
+	ob.mutex.Unlock()
+
+	if sub != nil {
+		sub.Unsubscribe()
+	}
+}
+
+// Apply 应用一次深度推送，可用于手动驱动订单簿
+func (ob *OrderBook) Apply(update *WSDepthData) error {
+	ob.mutex.Lock()
+	if ob.syncing {
+		ob.buffered = append(ob.buffered, update)
+		ob.mutex.Unlock()
+		return nil
+	}
+
+	err := ob.applyLocked(update)
+	snapshot := ob.updateLocked(false)
+	ob.mutex.Unlock()
+
+	if err != nil {
+		ob.resync(err)
+		return err
+	}
+	if ob.onUpdate != nil {
+		ob.onUpdate(snapshot)
+	}
+	return nil
+}
+
+// Resync 立即重新加载快照，失败时订单簿保持未同步，已启动的订单簿在后台按退避间隔继续重试
+func (ob *OrderBook) Resync(ctx context.Context) error {
+	ob.mutex.Lock()
+	if ob.syncing {
+		ob.mutex.Unlock()
+		return nil
+	}
+	ob.syncing = true
+	ob.synced = false
+	ob.mutex.Unlock()
+
+	err := ob.sync(ctx)
+	if err != nil {
+		ob.retry(1)
+	}
+	return err
+}
+
+// IsSynced 订单簿是否已同步
+func (ob *OrderBook) IsSynced() bool {
+	ob.mutex.RLock()
+	defer ob.mutex.RUnlock()
+	return ob.synced && !ob.syncing
+}
+
+// Gaps 获取检测到的版本缺口次数
+func (ob *OrderBook) Gaps() uint64 {
+	ob.mutex.RLock()
+	defer ob.mutex.RUnlock()
+	return ob.gaps
+}
+
+// Version 获取当前版本号
+func (ob *OrderBook) Version() int64 {
+	ob.mutex.RLock()
+	defer ob.mutex.RUnlock()
+	return ob.version
+}
+
+// BestBid 获取买一
+func (ob *OrderBook) BestBid() (PriceLevel, bool) {
+	ob.mutex.RLock()
+	defer ob.mutex.RUnlock()
+
+	if len(ob.bids) == 0 {
+		return PriceLevel{}, false
+	}
+	return ob.bids[0], true
+}
+
+// BestAsk 获取卖一
+func (ob *OrderBook) BestAsk() (PriceLevel, bool) {
+	ob.mutex.RLock()
+	defer ob.mutex.RUnlock()
+
+	if len(ob.asks) == 0 {
+		return PriceLevel{}, false
+	}
+	return ob.asks[0], true
+}
+
+// Top 获取前n档买卖盘
+func (ob *OrderBook) Top(n int) (bids, asks []PriceLevel) {
+	ob.mutex.RLock()
+	defer ob.mutex.RUnlock()
+
+	return copyLevels(ob.bids, n), copyLevels(ob.asks, n)
+}
+
+// Depth 获取完整深度，格式与GetDepth一致
+func (ob *OrderBook) Depth() *DepthData {
+	ob.mutex.RLock()
+	defer ob.mutex.RUnlock()
+
+	return &DepthData{
+		Bids: levelsToStrings(ob.bids),
+		Asks: levelsToStrings(ob.asks),
+	}
+}
+
+// Snapshot 获取带版本号的完整深度
+func (ob *OrderBook) Snapshot() *WSDepthData {
+	ob.mutex.RLock()
+	defer ob.mutex.RUnlock()
+
+	return &WSDepthData{
+		Bids:    levelsToStrings(ob.bids),
+		Asks:    levelsToStrings(ob.asks),
+		Version: ob.version,
+		Ts:      ob.ts,
+	}
+}
+
+// Analytics 基于当前深度创建分析
+func (ob *OrderBook) Analytics() (*DepthAnalytics, error) {
+	return AnalyzeWSDepth(ob.Snapshot())
+}
+
+// handleMessage 处理深度推送消息
+func (ob *OrderBook) handleMessage(message *WebSocketMessage) {
+	var update WSDepthData
+	if err := message.DecodeTick(&update); err != nil {
+		if ob.onResync != nil {
+			ob.onResync(fmt.Errorf("decode depth update: %w", err))
+		}
+		return
+	}
+	if update.Ts == 0 {
+		update.Ts = message.Ts
+	}
+
+	ob.Apply(&update)
+}
+
+// sync 加载快照并应用缓存的推送，调用前需将syncing置为true
+// 失败时保持syncing，之后的推送继续缓存，由调用方重试或停止
+func (ob *OrderBook) sync(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, ob.config.ResyncTimeout)
+	defer cancel()
+
+	snapshot, err := ob.config.Snapshot(ctx)
+
+	ob.mutex.Lock()
+	if err != nil {
+		ob.synced = false
+		ob.mutex.Unlock()
+		return fmt.Errorf("load depth snapshot: %w", err)
+	}
+
+	ob.bids = parseLevels(snapshot.Bids, true)
+	ob.asks = parseLevels(snapshot.Asks, false)
+	ob.version = snapshot.Version
+	ob.ts = snapshot.Ts
+
+	// 快照本身买卖盘交叉时不应用推送，等待下一次快照
+	// 应用快照之后的推送，无版本号的快照按时间过滤，版本基准取自首个应用的推送
+	applyErr := ob.crossedLocked()
+	for _, update := range ob.buffered {
+		if applyErr != nil {
+			break
+		}
+		if snapshot.Version > 0 {
+			if update.Version > 0 && update.Version <= snapshot.Version {
+				continue
+			}
+		} else if snapshot.Ts > 0 && update.Ts > 0 && update.Ts < snapshot.Ts {
+			continue
+		}
+		applyErr = ob.applyLocked(update)
+	}
+	ob.buffered = nil
+	if applyErr != nil {
+		// 快照与缓存的推送不一致，等待下一次快照
+		ob.synced = false
+		ob.mutex.Unlock()
+		return applyErr
+	}
+	ob.syncing = false
+	ob.synced = true
+	result := ob.updateLocked(true)
+	ob.mutex.Unlock()
+
+	if ob.onUpdate != nil {
+		ob.onUpdate(result)
+	}
+	return nil
+}
+
+// resync 在后台重新同步
+func (ob *OrderBook) resync(reason error) {
+	ob.mutex.Lock()
+	if ob.syncing || ob.sub == nil {
+		ob.mutex.Unlock()
+		return
+	}
+	ob.syncing = true
+	ob.synced = false
+	ob.mutex.Unlock()
+
+	if ob.onResync != nil {
+		ob.onResync(reason)
+	}
+	ob.retry(0)
+}
+
+// retry 在后台重新同步直到成功或订单簿停止，第attempt次尝试前按退避间隔等待，未启动时结束同步状态
+func (ob *OrderBook) retry(attempt int) {
+	ob.mutex.Lock()
+	stop := ob.stop
+	if stop == nil {
+		ob.syncing = false
+		ob.buffered = nil
+		ob.mutex.Unlock()
+		return
+	}
+	ob.mutex.Unlock()
+
+	go func() {
+		for ; ; attempt++ {
+			select {
+			case <-stop:
+				return
+			case <-time.After(ob.backoff(attempt)):
+			}
+
+			err := ob.sync(context.Background())
+			if err == nil {
+				return
+			}
+			if ob.onResync != nil {
+				ob.onResync(err)
+			}
+		}
+	}()
+}
+
+// backoff 第attempt次重试前的等待时间，首次尝试不等待
+func (ob *OrderBook) backoff(attempt int) time.Duration {
+	delay := ob.config.ResyncBackoff * time.Duration(attempt)
+	if delay > maxResyncBackoff {
+		delay = maxResyncBackoff
+	}
+	return delay
+}
+
+// applyLocked 应用推送，调用方需持有写锁
+func (ob *OrderBook) applyLocked(update *WSDepthData) error {
+	ob.gap = ob.version > 0 && update.Version > ob.version+1
+	if ob.gap {
+		ob.gaps++
+	}
+
+	if ob.config.Incremental {
+		if ob.version > 0 && update.Version > 0 {
+			if update.Version <= ob.version {
+				return nil
+			}
+			if ob.gap {
+				return fmt.Errorf("depth version gap: expected %d, got %d", ob.version+1, update.Version)
+			}
+		}
+		ob.bids = mergeLevels(ob.bids, parseLevels(update.Bids, true), true)
+		ob.asks = mergeLevels(ob.asks, parseLevels(update.Asks, false), false)
+	} else {
+		// 全量推送，忽略过期版本
+		if ob.version > 0 && update.Version > 0 && update.Version < ob.version {
+			return nil
+		}
+		ob.bids = parseLevels(update.Bids, true)
+		ob.asks = parseLevels(update.Asks, false)
+	}
+
+	if update.Version > 0 {
+		ob.version = update.Version
+	}
+	if update.Ts > 0 {
+		ob.ts = update.Ts
+	}
+
+	if ob.config.MaxLevels > 0 {
+		if len(ob.bids) > ob.config.MaxLevels {
+			ob.bids = ob.bids[:ob.config.MaxLevels]
+		}
+		if len(ob.asks) > ob.config.MaxLevels {
+			ob.asks = ob.asks[:ob.config.MaxLevels]
+		}
+	}
+
+	return ob.crossedLocked()
+}
+
+// crossedLocked 检查买一是否不低于卖一，调用方需持有锁
+func (ob *OrderBook) crossedLocked() error {
+	if len(ob.bids) > 0 && len(ob.asks) > 0 && !ob.bids[0].price.LessThan(ob.asks[0].price) {
+		return fmt.Errorf("crossed book: bid %s >= ask %s", ob.bids[0].Price, ob.asks[0].Price)
+	}
+	return nil
+}
+
+// updateLocked 构建变化通知，调用方需持有锁
+func (ob *OrderBook) updateLocked(resynced bool) OrderBookUpdate {
+	update := OrderBookUpdate{
+		Symbol:   ob.symbol,
+		Version:  ob.version,
+		Ts:       ob.ts,
+		Resynced: resynced,
+		Gap:      ob.gap && !resynced,
+	}
+	if len(ob.bids) > 0 {
+		update.BestBid = ob.bids[0]
+	}
+	if len(ob.asks) > 0 {
+		update.BestAsk = ob.asks[0]
+	}
+	return update
+}
+
+// restSnapshot 通过REST接口获取深度快照
+// REST快照没有版本号，Ts取请求发出的时间，之后收到的推送都会在快照之上重新应用
+func (ob *OrderBook) restSnapshot(ctx context.Context) (*WSDepthData, error) {
+	requested := time.Now().UnixMilli()
+	depth, err := ob.client.Market.getDepth(ctx, ob.symbol, ob.config.DepthType)
+	if err != nil {
+		return nil, err
+	}
+	return &WSDepthData{
+		Bids: depth.Bids,
+		Asks: depth.Asks,
+		Ts:   requested,
+	}, nil
+}
+
+// WSDepthSnapshot 通过WebSocket请求获取带版本号的深度快照，可作为OrderBookConfig.Snapshot
+func WSDepthSnapshot(ws *WebSocketService, symbol, depthType string) DepthSnapshotFunc {
+	return func(ctx context.Context) (*WSDepthData, error) {
+		return ws.ReqDepth(ctx, symbol, depthType)
+	}
+}
+
+// parseLevels 解析价格档位并排序，买盘从高到低，卖盘从低到高
+// 档位按规范化的价格区分，同一价格出现多次时保留最后一个
+func parseLevels(raw [][]string, desc bool) []PriceLevel {
+	levels := make([]PriceLevel, 0, len(raw))
+	for _, item := range raw {
+		if len(item) < 2 {
+			continue
+		}
+		price, err := NewDecimalFromString(item[0])
+		if err != nil {
+			continue
+		}
+		amount, err := NewDecimalFromString(item[1])
+		if err != nil {
+			continue
+		}
+		levels = append(levels, PriceLevel{
+			Price:  item[0],
+			Amount: item[1],
+			price:  price,
+			amount: amount,
+			key:    price.Normalize().String(),
+		})
+	}
+
+	sort.SliceStable(levels, func(i, j int) bool {
+		if desc {
+			return levels[i].price.GreaterThan(levels[j].price)
+		}
+		return levels[i].price.LessThan(levels[j].price)
+	})
+
+	unique := levels[:0]
+	for _, level := range levels {
+		if n := len(unique); n > 0 && unique[n-1].key == level.key {
+			unique[n-1] = level
+			continue
+		}
+		unique = append(unique, level)
+	}
+	return unique
+}
+
+// mergeLevels 将增量档位合并到有序档位中，数量为0表示删除该档位
+func mergeLevels(levels, updates []PriceLevel, desc bool) []PriceLevel {
+	for _, update := range updates {
+		index := sort.Search(len(levels), func(i int) bool {
+			if desc {
+				return !levels[i].price.GreaterThan(update.price)
+			}
+			return !levels[i].price.LessThan(update.price)
+		})
+
+		exists := index < len(levels) && levels[index].key == update.key
+		switch {
+		case update.amount.IsZero() && exists:
+			levels = append(levels[:index], levels[index+1:]...)
+		case update.amount.IsZero():
+		case exists:
+			levels[index] = update
+		default:
+			levels = append(levels, PriceLevel{})
+			copy(levels[index+1:], levels[index:])
+			levels[index] = update
+		}
+	}
+	return levels
+}
+
+// copyLevels 复制前n档，n<=0时复制全部
+func copyLevels(levels []PriceLevel, n int) []PriceLevel {
+	if n <= 0 || n > len(levels) {
+		n = len(levels)
+	}
+	result := make([]PriceLevel, n)
+	copy(result, levels[:n])
+	return result
+}
+
+// levelsToStrings 转换为[价格, 数量]格式
+func levelsToStrings(levels []PriceLevel) [][]string {
+	result := make([][]string, len(levels))
+	for i, level := range levels {
+		result[i] = []string{level.Price, level.Amount}
+	}
+	return result
+}
@@ -0,0 +1,238 @@
+package hotcoin
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newTestOrderBook(incremental bool) *OrderBook {
+	config := DefaultOrderBookConfig()
+	config.Incremental = incremental
+	config.Snapshot = func(ctx context.Context) (*WSDepthData, error) {
+		return &WSDepthData{
+			Bids:    [][]string{{"100.0", "1"}, {"99.5", "2"}},
+			Asks:    [][]string{{"100.5", "3"}, {"101", "4"}},
+			Version: 10,
+		}, nil
+	}
+	ob := NewOrderBook(nil, "btcusdt", config)
+	ob.syncing = true
+	if err := ob.sync(context.Background()); err != nil {
+		panic(err)
+	}
+	return ob
+}
+
+func TestOrderBookIncremental(t *testing.T) {
+	ob := newTestOrderBook(true)
+
+	err := ob.Apply(&WSDepthData{
+		Bids:    [][]string{{"100.0", "0"}, {"99.8", "5"}},
+		Asks:    [][]string{{"100.2", "1"}},
+		Version: 11,
+	})
+	if err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+
+	bid, _ := ob.BestBid()
+	ask, _ := ob.BestAsk()
+	if bid.Price != "99.8" || ask.Price != "100.2" {
+		t.Errorf("unexpected best bid/ask: %s/%s", bid.Price, ask.Price)
+	}
+
+	bids, asks := ob.Top(5)
+	if len(bids) != 2 || len(asks) != 3 {
+		t.Errorf("unexpected depth: %d bids, %d asks", len(bids), len(asks))
+	}
+
+	// 过期版本被忽略
+	if err := ob.Apply(&WSDepthData{Bids: [][]string{{"105", "1"}}, Version: 11}); err != nil {
+		t.Errorf("stale update should be ignored: %v", err)
+	}
+
+	// 版本缺口
+	if err := ob.Apply(&WSDepthData{Version: 13}); err == nil {
+		t.Error("expected version gap error")
+	}
+}
+
+func TestOrderBookCrossed(t *testing.T) {
+	ob := newTestOrderBook(true)
+
+	err := ob.Apply(&WSDepthData{
+		Bids:    [][]string{{"100.6", "1"}},
+		Version: 11,
+	})
+	if err == nil {
+		t.Error("expected crossed book error")
+	}
+}
+
+func TestOrderBookSnapshotMode(t *testing.T) {
+	ob := newTestOrderBook(false)
+
+	err := ob.Apply(&WSDepthData{
+		Bids:    [][]string{{"99", "1"}},
+		Asks:    [][]string{{"99.5", "1"}},
+		Version: 20,
+	})
+	if err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+
+	depth := ob.Depth()
+	if len(depth.Bids) != 1 || depth.Asks[0][0] != "99.5" {
+		t.Errorf("snapshot should replace book: %+v", depth)
+	}
+	if ob.Version() != 20 {
+		t.Errorf("expected version 20, got %d", ob.Version())
+	}
+}
+
+func TestOrderBookNormalizedPrices(t *testing.T) {
+	ob := newTestOrderBook(true)
+
+	// 同一价格的不同写法为同一档位
+	err := ob.Apply(&WSDepthData{
+		Bids:    [][]string{{"100", "0"}, {"99.50", "7"}},
+		Asks:    [][]string{{"1.005e2", "6"}},
+		Version: 11,
+	})
+	if err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	bids, asks := ob.Top(0)
+	if len(bids) != 1 || bids[0].Amount != "7" || len(asks) != 2 || asks[0].Amount != "6" {
+		t.Errorf("unexpected depth: bids %v, asks %v", bids, asks)
+	}
+
+	levels := parseLevels([][]string{{"10", "1"}, {"10.00", "2"}, {"9", "1"}}, true)
+	if len(levels) != 2 || levels[0].Amount != "2" {
+		t.Errorf("duplicate snapshot levels not merged: %v", levels)
+	}
+}
+
+func TestOrderBookUnversionedSnapshot(t *testing.T) {
+	config := DefaultOrderBookConfig()
+	config.Incremental = true
+	config.Snapshot = func(ctx context.Context) (*WSDepthData, error) {
+		return &WSDepthData{
+			Bids: [][]string{{"100", "1"}},
+			Asks: [][]string{{"101", "1"}},
+			Ts:   1000,
+		}, nil
+	}
+	ob := NewOrderBook(nil, "btcusdt", config)
+	ob.syncing = true
+	ob.buffered = []*WSDepthData{
+		{Bids: [][]string{{"100", "5"}}, Version: 50, Ts: 900},
+		{Bids: [][]string{{"100", "2"}}, Version: 52, Ts: 1000},
+		{Bids: [][]string{{"99", "3"}}, Version: 53, Ts: 1100},
+	}
+	if err := ob.sync(context.Background()); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+
+	// 早于快照的推送被丢弃，版本基准取自首个应用的推送
+	bid, _ := ob.BestBid()
+	if bid.Amount != "2" || ob.Version() != 53 {
+		t.Errorf("unexpected book: best bid %+v, version %d", bid, ob.Version())
+	}
+}
+
+func TestOrderBookRESTSnapshotTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	clientConfig := DefaultConfig()
+	clientConfig.BaseURL = server.URL
+	config := DefaultOrderBookConfig()
+	config.ResyncTimeout = 50 * time.Millisecond
+	ob := NewOrderBook(NewClientWithConfig(clientConfig), "btcusdt", config)
+
+	start := time.Now()
+	ob.syncing = true
+	if err := ob.sync(context.Background()); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("snapshot ignored ResyncTimeout, took %v", elapsed)
+	}
+}
+
+func TestOrderBookSnapshotModeGap(t *testing.T) {
+	ob := newTestOrderBook(false)
+	var updates []OrderBookUpdate
+	ob.OnUpdate(func(update OrderBookUpdate) { updates = append(updates, update) })
+
+	// 全量推送模式下缺失版本只计数，订单簿以最新推送为准
+	ob.Apply(&WSDepthData{Bids: [][]string{{"99", "1"}}, Asks: [][]string{{"100", "1"}}, Version: 13})
+	ob.Apply(&WSDepthData{Bids: [][]string{{"99", "2"}}, Asks: [][]string{{"100", "1"}}, Version: 14})
+	if ob.Gaps() != 1 || !ob.IsSynced() {
+		t.Errorf("gaps = %d, synced %v", ob.Gaps(), ob.IsSynced())
+	}
+	if len(updates) != 2 || !updates[0].Gap || updates[1].Gap {
+		t.Errorf("unexpected updates: %+v", updates)
+	}
+}
+
+func TestOrderBookResyncRetries(t *testing.T) {
+	var mutex sync.Mutex
+	attempts := 0
+	config := DefaultOrderBookConfig()
+	config.Incremental = true
+	config.ResyncBackoff = 10 * time.Millisecond
+	config.Snapshot = func(ctx context.Context) (*WSDepthData, error) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		attempts++
+		switch attempts {
+		case 1:
+			return &WSDepthData{Bids: [][]string{{"100", "1"}}, Asks: [][]string{{"101", "1"}}, Version: 10}, nil
+		case 2:
+			return nil, errors.New("snapshot unavailable")
+		case 3:
+			// 买卖盘交叉的快照同样需要重试
+			return &WSDepthData{Bids: [][]string{{"102", "1"}}, Asks: [][]string{{"101", "1"}}, Version: 20}, nil
+		}
+		return &WSDepthData{Bids: [][]string{{"100", "1"}}, Asks: [][]string{{"101", "1"}}, Version: 30}, nil
+	}
+	ob := NewOrderBook(nil, "btcusdt", config)
+	ob.sub = NewEventBus().Subscribe(ob.Topic(), func(*WebSocketMessage) {})
+	ob.stop = make(chan struct{})
+	defer ob.Stop()
+	ob.syncing = true
+	if err := ob.sync(context.Background()); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+
+	var resyncs []error
+	ob.OnResync(func(err error) {
+		mutex.Lock()
+		resyncs = append(resyncs, err)
+		mutex.Unlock()
+	})
+	if err := ob.Apply(&WSDepthData{Version: 12}); err == nil {
+		t.Fatal("expected version gap error")
+	}
+	if ob.IsSynced() {
+		t.Error("book should not be ready while resyncing")
+	}
+
+	waitFor(t, 2*time.Second, ob.IsSynced)
+	mutex.Lock()
+	defer mutex.Unlock()
+	if attempts != 4 || len(resyncs) != 3 || ob.Version() != 30 {
+		t.Errorf("attempts %d, resync reasons %v, version %d", attempts, resyncs, ob.Version())
+	}
+}
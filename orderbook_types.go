@@ -0,0 +1,54 @@
+package hotcoin
+
+import (
+	"context"
+	"time"
+)
+
+// PriceLevel 订单簿价格档位
+type PriceLevel struct {
+	Price  string // 价格
+	Amount string // 数量
+
+	price  Decimal
+	amount Decimal
+	key    string // 规范化的价格，如"100.0"和"100"为同一档位
+}
+
+// OrderBookUpdate 订单簿变化通知
+type OrderBookUpdate struct {
+	Symbol   string     // 交易对
+	BestBid  PriceLevel // 买一
+	BestAsk  PriceLevel // 卖一
+	Version  int64      // 版本号
+	Ts       int64      // 时间戳
+	Resynced bool       // 是否由重新同步触发
+	Gap      bool       // 本次推送与上一版本之间有缺失，全量推送模式下订单簿仍以本次推送为准
+}
+
+// OrderBookHandler 订单簿变化处理器
+type OrderBookHandler func(update OrderBookUpdate)
+
+// DepthSnapshotFunc 深度快照加载函数
+type DepthSnapshotFunc func(ctx context.Context) (*WSDepthData, error)
+
+// OrderBookConfig 本地订单簿配置
+type OrderBookConfig struct {
+	DepthType     string            // 深度类型，step0-step5
+	Incremental   bool              // 推送是否为增量数据，增量模式下校验版本连续性
+	MaxLevels     int               // 保留的最大档位数，0表示不限制
+	ResyncTimeout time.Duration     // 加载快照超时时间
+	ResyncBackoff time.Duration     // 后台重新同步失败后的重试退避基数，按失败次数递增，最长30秒
+	Snapshot      DepthSnapshotFunc // 自定义快照来源，默认使用MarketService.GetDepth
+}
+
+// DefaultOrderBookConfig 默认订单簿配置
+func DefaultOrderBookConfig() *OrderBookConfig {
+	return &OrderBookConfig{
+		DepthType:     "step0",
+		Incremental:   false,
+		MaxLevels:     0,
+		ResyncTimeout: 10 * time.Second,
+		ResyncBackoff: time.Second,
+	}
+}
@@ -169,9 +169,13 @@ func (p *PositionService) ClosePosition(symbol, direction string) error {
 	if symbol == "" {
 		return fmt.Errorf("symbol is required")
 	}
+	code, err := p.client.contractCode(symbol)
+	if err != nil {
+		return err
+	}
 
 	body := map[string]interface{}{
-		"symbol": symbol,
+		"symbol": code,
 	}
 	if direction != "" {
 		body["direction"] = direction
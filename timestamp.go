@@ -0,0 +1,149 @@
+package hotcoin
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Timestamp 接口返回的时间，兼容毫秒/秒数字、数字字符串和日期字符串，统一解析为UTC时间
+// 0、空字符串和null解析为零值；JSON和文本均编码为毫秒时间戳，零值编码为0
+type Timestamp struct {
+	time.Time
+}
+
+// timestampLayouts 支持的日期字符串格式，不带时区的按UTC处理
+var timestampLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.000",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+}
+
+// NewTimestamp 由time.Time创建
+func NewTimestamp(t time.Time) Timestamp {
+	if t.IsZero() {
+		return Timestamp{}
+	}
+	return Timestamp{Time: t.UTC()}
+}
+
+// TimestampFromUnix 由数字时间戳创建，按数值大小自动识别秒、毫秒、微秒和纳秒，0返回零值
+func TimestampFromUnix(ts int64) Timestamp {
+	abs := ts
+	if abs < 0 {
+		abs = -abs
+	}
+	switch {
+	case ts == 0:
+		return Timestamp{}
+	case abs >= 1e17:
+		return Timestamp{Time: time.Unix(0, ts).UTC()}
+	case abs >= 1e14:
+		return Timestamp{Time: time.UnixMicro(ts).UTC()}
+	case abs > 1e12:
+		return Timestamp{Time: time.UnixMilli(ts).UTC()}
+	default:
+		return Timestamp{Time: time.Unix(ts, 0).UTC()}
+	}
+}
+
+// ParseTimestamp 解析数字或日期字符串，空字符串和"0"返回零值
+// 纯数字一律按时间戳解析，不识别YYYYMMDD格式
+func ParseTimestamp(s string) (Timestamp, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return Timestamp{}, nil
+	}
+	if ts, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return TimestampFromUnix(ts), nil
+	}
+	for _, layout := range timestampLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return NewTimestamp(t), nil
+		}
+	}
+	return Timestamp{}, fmt.Errorf("invalid timestamp %q", s)
+}
+
+// UnixMilli 毫秒时间戳，零值返回0
+func (t Timestamp) UnixMilli() int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.Time.UnixMilli()
+}
+
+// String 返回RFC3339格式，零值返回空字符串
+func (t Timestamp) String() string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339Nano)
+}
+
+// MarshalJSON 编码为毫秒时间戳
+func (t Timestamp) MarshalJSON() ([]byte, error) {
+	return strconv.AppendInt(nil, t.UnixMilli(), 10), nil
+}
+
+// UnmarshalJSON 解析数字、数字字符串或日期字符串
+func (t *Timestamp) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if string(data) == "null" {
+		return nil
+	}
+	if len(data) >= 2 && data[0] == '"' && data[len(data)-1] == '"' {
+		data = data[1 : len(data)-1]
+	}
+
+	parsed, err := ParseTimestamp(string(data))
+	if err != nil {
+		return err
+	}
+	*t = parsed
+	return nil
+}
+
+// MarshalText 编码为毫秒时间戳文本，与MarshalJSON一致
+func (t Timestamp) MarshalText() ([]byte, error) {
+	return t.AppendText(nil)
+}
+
+// AppendText 追加毫秒时间戳文本，覆盖内嵌time.Time的RFC3339实现
+func (t Timestamp) AppendText(b []byte) ([]byte, error) {
+	return strconv.AppendInt(b, t.UnixMilli(), 10), nil
+}
+
+// UnmarshalText 解析文本
+func (t *Timestamp) UnmarshalText(text []byte) error {
+	parsed, err := ParseTimestamp(string(text))
+	if err != nil {
+		return err
+	}
+	*t = parsed
+	return nil
+}
+
+// unixMilliParam 将时间转换为毫秒时间戳请求参数
+func unixMilliParam(t time.Time) string {
+	return strconv.FormatInt(t.UnixMilli(), 10)
+}
+
+// Time K线开始时间
+func (k *KlineData) Time() time.Time {
+	return KlineTime(k.Timestamp)
+}
+
+// Time 成交时间
+func (t *TradeData) Time() time.Time {
+	return TimestampFromUnix(t.Timestamp).Time
+}
+
+// Time 消息时间，无时间戳时返回零值
+func (m *WebSocketMessage) Time() time.Time {
+	return TimestampFromUnix(m.Ts).Time
+}
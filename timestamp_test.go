@@ -0,0 +1,153 @@
+package hotcoin
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseTimestamp(t *testing.T) {
+	want := time.Date(2023, 11, 14, 22, 13, 20, 0, time.UTC)
+	tests := []struct {
+		input string
+		want  time.Time
+	}{
+		{"1700000000", want},
+		{"1700000000000", want},
+		{"1700000000000000", want},
+		{"1700000000000000000", want},
+		{"2023-11-14 22:13:20", want},
+		{"2023-11-14T22:13:20Z", want},
+		{"2023-11-15T06:13:20+08:00", want},
+		{"2023-11-14", time.Date(2023, 11, 14, 0, 0, 0, 0, time.UTC)},
+		// 8位数字按秒时间戳解析，不当作日期
+		{"20231114", time.Unix(20231114, 0)},
+		{"86400", time.Unix(86400, 0)},
+		{"", time.Time{}},
+		{"0", time.Time{}},
+	}
+	for _, tt := range tests {
+		got, err := ParseTimestamp(tt.input)
+		if err != nil {
+			t.Errorf("%q: %v", tt.input, err)
+			continue
+		}
+		if !got.Time.Equal(tt.want) {
+			t.Errorf("%q: got %v, want %v", tt.input, got, tt.want)
+		}
+	}
+	if _, err := ParseTimestamp("tomorrow"); err == nil {
+		t.Error("expected error for invalid timestamp")
+	}
+}
+
+func TestTimestampJSON(t *testing.T) {
+	var order Order
+	if err := json.Unmarshal([]byte(`{"create_date":1700000000123,"canceled_at":0}`), &order); err != nil {
+		t.Fatal(err)
+	}
+	if order.CreateDate.UnixMilli() != 1700000000123 || !order.CanceledAt.IsZero() {
+		t.Errorf("unexpected order times: %v %v", order.CreateDate, order.CanceledAt)
+	}
+
+	var element ContractElement
+	data := `{"delivery_time":"2024-03-29 08:00:00","create_date":"","settlement_time":"1711699200000"}`
+	if err := json.Unmarshal([]byte(data), &element); err != nil {
+		t.Fatal(err)
+	}
+	if element.DeliveryTime.UnixMilli() != 1711699200000 || !element.CreateDate.IsZero() ||
+		!element.SettlementTime.Equal(element.DeliveryTime.Time) {
+		t.Errorf("unexpected element times: %+v", element)
+	}
+
+	out, err := json.Marshal(order)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded Order
+	if err := json.Unmarshal(out, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !decoded.CreateDate.Equal(order.CreateDate.Time) || !decoded.CanceledAt.IsZero() {
+		t.Errorf("round trip mismatch: %v", decoded.CreateDate)
+	}
+
+	kline := KlineData{Timestamp: 1700000000}
+	trade := TradeData{Timestamp: 1700000000123}
+	if !kline.Time().Equal(time.Unix(1700000000, 0)) || trade.Time().UnixMilli() != 1700000000123 {
+		t.Errorf("unexpected market data times: %v %v", kline.Time(), trade.Time())
+	}
+}
+
+func TestTimestampText(t *testing.T) {
+	ts := TimestampFromUnix(1700000000123)
+	text, err := ts.MarshalText()
+	if err != nil || string(text) != "1700000000123" {
+		t.Fatalf("MarshalText = %s, %v", text, err)
+	}
+	var decoded Timestamp
+	if err := decoded.UnmarshalText(text); err != nil || !decoded.Equal(ts.Time) {
+		t.Errorf("UnmarshalText = %v, %v", decoded, err)
+	}
+
+	// 作为map键时与JSON数值编码一致
+	out, _ := json.Marshal(map[Timestamp]int{ts: 1})
+	if string(out) != `{"1700000000123":1}` {
+		t.Errorf("map key encoding = %s", out)
+	}
+}
+
+func TestTimeRangeParamUnits(t *testing.T) {
+	queries := make(map[string]url.Values)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries[r.URL.Path] = r.URL.Query()
+		fmt.Fprint(w, `{"code":200,"data":[]}`)
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.BaseURL = server.URL
+	config.APIKey, config.SecretKey = "key", "secret"
+	client := NewClientWithConfig(config)
+
+	start := time.Unix(1700000000, 123e6)
+	end := start.Add(time.Hour)
+	client.Market.GetHistoricalKline("btcusdt", "1min", start, end)
+	client.Account.GetFinancialRecord("USDT", 0, start, end, 10)
+	client.Common.GetMasterSubTransferRecord("USDT", "", start, end, 0, 10)
+
+	// K线历史接口按秒，财务记录和划转记录按毫秒
+	tests := []struct {
+		path, startKey, endKey, start, end string
+	}{
+		{"/api/v1/perpetual/public/btcusdt/candles/history", "from", "to", "1700000000", "1700003600"},
+		{"/api/v1/perpetual/account/financial-record", "start_time", "end_time", "1700000000123", "1700003600123"},
+	}
+	for _, tt := range tests {
+		query, ok := queries[tt.path]
+		if !ok {
+			t.Errorf("%s not requested, got %v", tt.path, queries)
+			continue
+		}
+		if query.Get(tt.startKey) != tt.start || query.Get(tt.endKey) != tt.end {
+			t.Errorf("%s: %s=%s %s=%s", tt.path, tt.startKey, query.Get(tt.startKey), tt.endKey, query.Get(tt.endKey))
+		}
+	}
+	transfers := 0
+	for path, query := range queries {
+		if strings.Contains(path, "transfer") {
+			transfers++
+			if query.Get("start_time") != "1700000000123" || query.Get("end_time") != "1700003600123" {
+				t.Errorf("%s: start_time=%s end_time=%s", path, query.Get("start_time"), query.Get("end_time"))
+			}
+		}
+	}
+	if transfers != 1 {
+		t.Errorf("transfer record not requested: %v", queries)
+	}
+}
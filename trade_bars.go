@@ -0,0 +1,467 @@
+package hotcoin
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TradeBarBuilder 由逐笔成交构建时间、笔数、成交量、成交额和不平衡K线
+// 成交按ID去重，可同时接收GetTrades和market.X.trade.detail推送，成交不跨K线拆分；
+// 时间K线收盘后到达的属于该K线或更早时段的迟到成交被丢弃并计入LateTrades，回调按完成顺序串行触发
+type TradeBarBuilder struct {
+	client *Client
+	symbol string
+	config *TradeBarConfig
+
+	mutex   sync.Mutex
+	current *TradeBar
+	seen    *recentIDs
+	lastPx  Decimal
+	lastDir int
+
+	// 已收盘时间K线的结束时间，早于该时间的成交为迟到成交
+	closedEnd time.Time
+	late      uint64
+
+	// 待触发的K线，由deliver串行投递
+	pending    []TradeBar
+	delivering bool
+
+	sub    *Subscription
+	ticker *time.Ticker
+	stop   chan struct{}
+
+	onBar TradeBarHandler
+}
+
+// NewTradeBarBuilder 创建成交K线构建器，仅手动调用Add时client可为nil
+func NewTradeBarBuilder(client *Client, symbol string, config *TradeBarConfig) (*TradeBarBuilder, error) {
+	if config == nil {
+		return nil, fmt.Errorf("config is required")
+	}
+	switch config.Type {
+	case TradeBarTime:
+		if config.Interval <= 0 {
+			return nil, fmt.Errorf("interval is required for time bars")
+		}
+	case TradeBarTick, TradeBarVolume, TradeBarNotional, TradeBarImbalance:
+		if config.Threshold.Sign() <= 0 {
+			return nil, fmt.Errorf("threshold must be positive for %s bars", config.Type)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported bar type %d", config.Type)
+	}
+	if config.DedupSize <= 0 {
+		config.DedupSize = DefaultTradeDedupSize
+	}
+
+	return &TradeBarBuilder{
+		client: client,
+		symbol: symbol,
+		config: config,
+		seen:   newRecentIDs(config.DedupSize),
+	}, nil
+}
+
+// OnBar 设置K线完成回调
+func (b *TradeBarBuilder) OnBar(handler TradeBarHandler) {
+	b.onBar = handler
+}
+
+// Topic 获取成交推送主题
+func (b *TradeBarBuilder) Topic() string {
+	return fmt.Sprintf("market.%s.trade.detail", b.symbol)
+}
+
+// Start 通过GetTrades加载最近size笔成交并开始接收WebSocket成交推送，WebSocket需已连接
+func (b *TradeBarBuilder) Start(ctx context.Context, size int) error {
+	ws := b.client.WebSocket
+	if !ws.IsConnected() {
+		return fmt.Errorf("not connected")
+	}
+
+	b.mutex.Lock()
+	if b.sub != nil {
+		b.mutex.Unlock()
+		return fmt.Errorf("trade bar builder already started")
+	}
+	b.sub = ws.Bus().Subscribe(b.Topic(), b.handleMessage)
+	b.mutex.Unlock()
+
+	subscribed := false
+	for _, topic := range ws.Topics() {
+		if topic == b.Topic() {
+			subscribed = true
+			break
+		}
+	}
+	if !subscribed {
+		if err := ws.Subscribe(b.Topic()); err != nil {
+			b.Stop()
+			return err
+		}
+	}
+
+	if size > 0 {
+		trades, err := b.client.Market.GetTrades(b.symbol, size)
+		if err != nil {
+			b.Stop()
+			return fmt.Errorf("load trades: %w", err)
+		}
+		if _, err := b.AddTradeData(trades); err != nil {
+			b.Stop()
+			return err
+		}
+	}
+
+	// 时间K线在无成交时也按时收盘
+	if b.config.Type == TradeBarTime {
+		b.mutex.Lock()
+		b.ticker = time.NewTicker(minDuration(b.config.Interval, time.Second))
+		b.stop = make(chan struct{})
+		go b.closeLoop(b.ticker, b.stop)
+		b.mutex.Unlock()
+	}
+	return nil
+}
+
+// Stop 停止接收推送，不取消WebSocket订阅，未完成的K线保留
+func (b *TradeBarBuilder) Stop() {
+	b.mutex.Lock()
+	sub := b.sub
+	b.sub = nil
+	if b.ticker != nil {
+		b.ticker.Stop()
+		close(b.stop)
+		b.ticker = nil
+	}
+	b.mutex.Unlock()
+
+	if sub != nil {
+		sub.Unsubscribe()
+	}
+}
+
+// Add 添加成交，返回因此完成的K线，重复ID的成交被忽略
+func (b *TradeBarBuilder) Add(trades ...Trade) []TradeBar {
+	b.mutex.Lock()
+	var bars []TradeBar
+	for _, trade := range trades {
+		if b.duplicateLocked(trade.ID) {
+			continue
+		}
+		if b.lateLocked(trade) {
+			b.late++
+			continue
+		}
+		bars = append(bars, b.addLocked(trade)...)
+	}
+	b.pending = append(b.pending, bars...)
+	b.mutex.Unlock()
+
+	b.deliver()
+	return bars
+}
+
+// LateTrades 获取因所属时间K线已收盘而丢弃的成交数量
+func (b *TradeBarBuilder) LateTrades() uint64 {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.late
+}
+
+// AddTradeData 添加REST成交记录
+func (b *TradeBarBuilder) AddTradeData(data []TradeData) ([]TradeBar, error) {
+	trades := make([]Trade, 0, len(data))
+	for _, item := range data {
+		trade, err := NewTrade(item.ID, item.Price, item.Amount, item.Side, item.Timestamp)
+		if err != nil {
+			return nil, err
+		}
+		trades = append(trades, trade)
+	}
+	sortTrades(trades)
+	return b.Add(trades...), nil
+}
+
+// AddWSTrade 添加WebSocket成交推送
+func (b *TradeBarBuilder) AddWSTrade(data *WSTradeData) ([]TradeBar, error) {
+	trades := make([]Trade, 0, len(data.Data))
+	for _, item := range data.Data {
+		ts := item.Ts
+		if ts == 0 {
+			ts = data.Ts
+		}
+		trade, err := NewTrade(item.ID, item.Price, item.Amount, item.Direction, ts)
+		if err != nil {
+			return nil, err
+		}
+		trades = append(trades, trade)
+	}
+	sortTrades(trades)
+	return b.Add(trades...), nil
+}
+
+// CloseExpired 收盘now之前已结束的时间K线，仅对时间K线有效
+func (b *TradeBarBuilder) CloseExpired(now time.Time) []TradeBar {
+	b.mutex.Lock()
+	var bars []TradeBar
+	if b.config.Type == TradeBarTime && b.current != nil && !now.Before(b.current.End) {
+		bars = append(bars, b.closeLocked())
+	}
+	b.pending = append(b.pending, bars...)
+	b.mutex.Unlock()
+
+	b.deliver()
+	return bars
+}
+
+// Flush 立即完成当前K线
+func (b *TradeBarBuilder) Flush() (TradeBar, bool) {
+	b.mutex.Lock()
+	if b.current == nil {
+		b.mutex.Unlock()
+		return TradeBar{}, false
+	}
+	bar := b.closeLocked()
+	b.pending = append(b.pending, bar)
+	b.mutex.Unlock()
+
+	b.deliver()
+	return bar, true
+}
+
+// Current 获取未完成的K线
+func (b *TradeBarBuilder) Current() (TradeBar, bool) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.current == nil {
+		return TradeBar{}, false
+	}
+	return *b.current, true
+}
+
+// handleMessage 处理成交推送消息
+func (b *TradeBarBuilder) handleMessage(message *WebSocketMessage) {
+	var data WSTradeData
+	if err := message.DecodeTick(&data); err != nil {
+		return
+	}
+	b.AddWSTrade(&data)
+}
+
+// closeLoop 定时收盘时间K线
+func (b *TradeBarBuilder) closeLoop(ticker *time.Ticker, stop chan struct{}) {
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-ticker.C:
+			b.CloseExpired(now)
+		}
+	}
+}
+
+// duplicateLocked 记录成交ID，已出现过时返回true，调用方需持有锁
+func (b *TradeBarBuilder) duplicateLocked(id int64) bool {
+	return id != 0 && !b.seen.add(id)
+}
+
+// lateLocked 成交是否早于当前或已收盘的时间K线，调用方需持有锁
+func (b *TradeBarBuilder) lateLocked(trade Trade) bool {
+	if b.config.Type != TradeBarTime {
+		return false
+	}
+	if b.current != nil {
+		return trade.Time.Before(b.current.Start)
+	}
+	return trade.Time.Before(b.closedEnd)
+}
+
+// addLocked 将成交计入K线，调用方需持有锁
+func (b *TradeBarBuilder) addLocked(trade Trade) []TradeBar {
+	var bars []TradeBar
+
+	if b.config.Type == TradeBarTime {
+		start := FixedBuckets(b.config.Interval, time.Unix(0, 0).UTC())
+		if b.current != nil && !trade.Time.Before(b.current.End) {
+			bars = append(bars, b.closeLocked())
+		}
+		if b.current == nil {
+			bucketStart, bucketEnd := start(trade.Time)
+			b.current = b.newBarLocked(trade)
+			b.current.Start, b.current.End = bucketStart, bucketEnd
+		}
+	} else if b.current == nil {
+		b.current = b.newBarLocked(trade)
+	}
+
+	bar := b.current
+	price := trade.Price
+	if bar.Trades == 0 {
+		bar.Open, bar.High, bar.Low = price, price, price
+		bar.FirstID = trade.ID
+	}
+	bar.High = bar.High.Max(price)
+	bar.Low = bar.Low.Min(price)
+	bar.Close = price
+	bar.LastID = trade.ID
+	bar.Trades++
+	bar.Volume = bar.Volume.Add(trade.Amount)
+	bar.priceVolume = bar.priceVolume.Add(trade.Price.Mul(trade.Amount))
+	bar.Notional = bar.Notional.Add(b.notional(trade))
+
+	switch b.direction(trade) {
+	case 1:
+		bar.BuyVolume = bar.BuyVolume.Add(trade.Amount)
+		bar.Imbalance = bar.Imbalance.Add(trade.Amount)
+	case -1:
+		bar.SellVolume = bar.SellVolume.Add(trade.Amount)
+		bar.Imbalance = bar.Imbalance.Sub(trade.Amount)
+	}
+	if b.config.Type != TradeBarTime {
+		bar.End = trade.Time
+	}
+
+	if b.thresholdReached(bar) {
+		bars = append(bars, b.closeLocked())
+	}
+	return bars
+}
+
+// newBarLocked 以成交开始新K线
+func (b *TradeBarBuilder) newBarLocked(trade Trade) *TradeBar {
+	return &TradeBar{
+		Symbol: b.symbol,
+		Type:   b.config.Type,
+		Start:  trade.Time,
+		End:    trade.Time,
+	}
+}
+
+// closeLocked 完成当前K线，调用方需持有锁
+func (b *TradeBarBuilder) closeLocked() TradeBar {
+	bar := *b.current
+	b.current = nil
+	if b.config.Type == TradeBarTime && bar.End.After(b.closedEnd) {
+		b.closedEnd = bar.End
+	}
+	return bar
+}
+
+// thresholdReached K线是否达到完成阈值
+func (b *TradeBarBuilder) thresholdReached(bar *TradeBar) bool {
+	threshold := b.config.Threshold
+	switch b.config.Type {
+	case TradeBarTick:
+		return !NewDecimalFromInt(int64(bar.Trades)).LessThan(threshold)
+	case TradeBarVolume:
+		return !bar.Volume.LessThan(threshold)
+	case TradeBarNotional:
+		return !bar.Notional.LessThan(threshold)
+	case TradeBarImbalance:
+		return !bar.Imbalance.Abs().LessThan(threshold)
+	}
+	return false
+}
+
+// notional 计算成交额，按配置乘以合约面值
+func (b *TradeBarBuilder) notional(trade Trade) Decimal {
+	notional := trade.Price.Mul(trade.Amount)
+	if !b.config.ContractSize.IsZero() {
+		notional = notional.Mul(b.config.ContractSize)
+	}
+	return notional
+}
+
+// direction 获取主动成交方向，无方向时按价格变动判断（tick rule）
+func (b *TradeBarBuilder) direction(trade Trade) int {
+	dir := 0
+	switch trade.Side {
+	case OrderSideBuy:
+		dir = 1
+	case OrderSideSell:
+		dir = -1
+	default:
+		if !b.lastPx.IsZero() {
+			dir = trade.Price.Cmp(b.lastPx)
+		}
+		if dir == 0 {
+			dir = b.lastDir
+		}
+	}
+	b.lastPx = trade.Price
+	b.lastDir = dir
+	return dir
+}
+
+// deliver 串行触发待投递的K线，已有调用在投递时由其继续处理
+func (b *TradeBarBuilder) deliver() {
+	b.mutex.Lock()
+	if b.delivering {
+		b.mutex.Unlock()
+		return
+	}
+	b.delivering = true
+	for len(b.pending) > 0 {
+		bars := b.pending
+		b.pending = nil
+		b.mutex.Unlock()
+		b.emit(bars)
+		b.mutex.Lock()
+	}
+	b.delivering = false
+	b.mutex.Unlock()
+}
+
+// emit 按顺序触发K线完成回调
+func (b *TradeBarBuilder) emit(bars []TradeBar) {
+	if b.onBar == nil {
+		return
+	}
+	for _, bar := range bars {
+		b.onBar(bar)
+	}
+}
+
+// NewTrade 由成交记录字段创建成交，ts自动识别秒和毫秒
+func NewTrade(id int64, price, amount, side string, ts int64) (Trade, error) {
+	p, err := NewDecimalFromString(price)
+	if err != nil {
+		return Trade{}, fmt.Errorf("trade %d price: %w", id, err)
+	}
+	a, err := NewDecimalFromString(amount)
+	if err != nil {
+		return Trade{}, fmt.Errorf("trade %d amount: %w", id, err)
+	}
+	return Trade{
+		ID:     id,
+		Price:  p,
+		Amount: a,
+		Side:   OrderSide(strings.ToLower(side)),
+		Time:   KlineTime(ts),
+	}, nil
+}
+
+// sortTrades 按成交时间和ID排序
+func sortTrades(trades []Trade) {
+	sort.SliceStable(trades, func(i, j int) bool {
+		if !trades[i].Time.Equal(trades[j].Time) {
+			return trades[i].Time.Before(trades[j].Time)
+		}
+		return trades[i].ID < trades[j].ID
+	})
+}
+
+// minDuration 返回较小的时长
+func minDuration(a, b time.Duration) time.Duration {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,146 @@
+package hotcoin
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTradeBarsVolume(t *testing.T) {
+	builder, err := NewTradeBarBuilder(nil, "btcusdt", &TradeBarConfig{
+		Type:      TradeBarVolume,
+		Threshold: NewDecimalFromInt(3),
+	})
+	if err != nil {
+		t.Fatalf("new builder: %v", err)
+	}
+
+	var completed []TradeBar
+	builder.OnBar(func(bar TradeBar) { completed = append(completed, bar) })
+
+	builder.AddTradeData([]TradeData{
+		{ID: 2, Price: "101", Amount: "1", Side: "sell", Timestamp: 1700000001000},
+		{ID: 1, Price: "100", Amount: "1", Side: "buy", Timestamp: 1700000000000},
+	})
+	// 重复的成交被忽略
+	builder.AddWSTrade(&WSTradeData{Data: []struct {
+		Amount    string `json:"amount"`
+		Ts        int64  `json:"ts"`
+		ID        int64  `json:"id"`
+		Price     string `json:"price"`
+		Direction string `json:"direction"`
+	}{
+		{Amount: "1", Ts: 1700000001000, ID: 2, Price: "101", Direction: "sell"},
+		{Amount: "2", Ts: 1700000002000, ID: 3, Price: "99", Direction: "buy"},
+	}})
+
+	if len(completed) != 1 {
+		t.Fatalf("got %d bars, want 1", len(completed))
+	}
+	bar := completed[0]
+	if bar.Trades != 3 || bar.FirstID != 1 || bar.LastID != 3 {
+		t.Errorf("unexpected bar: %+v", bar)
+	}
+	if !bar.Open.Equal(MustDecimal("100")) || !bar.High.Equal(MustDecimal("101")) ||
+		!bar.Low.Equal(MustDecimal("99")) || !bar.Close.Equal(MustDecimal("99")) {
+		t.Errorf("unexpected OHLC: %s %s %s %s", bar.Open, bar.High, bar.Low, bar.Close)
+	}
+	if !bar.Volume.Equal(MustDecimal("4")) || !bar.Imbalance.Equal(MustDecimal("2")) {
+		t.Errorf("volume = %s, imbalance = %s", bar.Volume, bar.Imbalance)
+	}
+	if got := bar.VWAP().StringFixed(2); got != "99.75" {
+		t.Errorf("vwap = %s", got)
+	}
+}
+
+func TestTradeBarsTimeAndImbalance(t *testing.T) {
+	base := time.Unix(1700000040, 0)
+	builder, _ := NewTradeBarBuilder(nil, "btcusdt", &TradeBarConfig{Type: TradeBarTime, Interval: time.Minute})
+
+	builder.Add(Trade{ID: 1, Price: MustDecimal("10"), Amount: MustDecimal("1"), Time: base})
+	bars := builder.Add(Trade{ID: 2, Price: MustDecimal("11"), Amount: MustDecimal("1"), Time: base.Add(time.Minute)})
+	if len(bars) != 1 || bars[0].Start.Unix() != 1700000040 || bars[0].End.Unix() != 1700000100 {
+		t.Fatalf("unexpected time bars: %+v", bars)
+	}
+	if bars := builder.CloseExpired(base.Add(2 * time.Minute)); len(bars) != 1 || bars[0].FirstID != 2 {
+		t.Errorf("unexpected expired bars: %+v", bars)
+	}
+
+	// 无方向的成交按价格变动判断
+	imbalance, _ := NewTradeBarBuilder(nil, "btcusdt", &TradeBarConfig{Type: TradeBarImbalance, Threshold: NewDecimalFromInt(2)})
+	imbalance.Add(Trade{ID: 1, Price: MustDecimal("10"), Amount: MustDecimal("1"), Side: OrderSideBuy, Time: base})
+	imbalance.Add(Trade{ID: 2, Price: MustDecimal("9"), Amount: MustDecimal("1"), Time: base})
+	bars = imbalance.Add(
+		Trade{ID: 3, Price: MustDecimal("9"), Amount: MustDecimal("1"), Time: base},
+		Trade{ID: 4, Price: MustDecimal("8"), Amount: MustDecimal("1"), Time: base},
+	)
+	if len(bars) != 1 || bars[0].Trades != 4 || !bars[0].Imbalance.Equal(MustDecimal("-2")) {
+		t.Errorf("unexpected imbalance bars: %+v", bars)
+	}
+}
+
+func TestTradeBarsDropLateTrades(t *testing.T) {
+	base := time.Unix(1700000040, 0)
+	builder, _ := NewTradeBarBuilder(nil, "btcusdt", &TradeBarConfig{Type: TradeBarTime, Interval: time.Minute})
+
+	var closed []TradeBar
+	builder.OnBar(func(bar TradeBar) { closed = append(closed, bar) })
+
+	builder.Add(Trade{ID: 1, Price: MustDecimal("10"), Amount: MustDecimal("1"), Time: base})
+	if bars := builder.CloseExpired(base.Add(time.Minute)); len(bars) != 1 {
+		t.Fatalf("unexpected expired bars: %+v", bars)
+	}
+
+	// 所属K线已收盘的成交不会重复开启同一时段的K线
+	builder.Add(Trade{ID: 2, Price: MustDecimal("11"), Amount: MustDecimal("1"), Time: base.Add(30 * time.Second)})
+	if _, ok := builder.Current(); ok || builder.LateTrades() != 1 {
+		t.Errorf("late trade not dropped: late %d", builder.LateTrades())
+	}
+
+	builder.Add(Trade{ID: 3, Price: MustDecimal("12"), Amount: MustDecimal("1"), Time: base.Add(90 * time.Second)})
+	builder.Add(Trade{ID: 4, Price: MustDecimal("13"), Amount: MustDecimal("1"), Time: base.Add(10 * time.Second)})
+	if bar, ok := builder.Current(); !ok || bar.Trades != 1 || builder.LateTrades() != 2 {
+		t.Errorf("unexpected current bar %+v, late %d", bar, builder.LateTrades())
+	}
+	builder.Flush()
+	if len(closed) != 2 || !closed[0].Start.Before(closed[1].Start) {
+		t.Errorf("unexpected closed bars: %+v", closed)
+	}
+}
+
+func TestTradeBarsSerializeCallbacks(t *testing.T) {
+	base := time.Unix(1700000040, 0)
+	builder, _ := NewTradeBarBuilder(nil, "btcusdt", &TradeBarConfig{Type: TradeBarTick, Threshold: NewDecimalFromInt(1)})
+
+	var active, overlaps int32
+	var mutex sync.Mutex
+	var ids []int64
+	builder.OnBar(func(bar TradeBar) {
+		if atomic.AddInt32(&active, 1) > 1 {
+			atomic.AddInt32(&overlaps, 1)
+		}
+		time.Sleep(time.Millisecond)
+		mutex.Lock()
+		ids = append(ids, bar.FirstID)
+		mutex.Unlock()
+		atomic.AddInt32(&active, -1)
+	})
+
+	var wg sync.WaitGroup
+	for i := 1; i <= 20; i++ {
+		wg.Add(1)
+		go func(id int64) {
+			defer wg.Done()
+			builder.Add(Trade{ID: id, Price: MustDecimal("10"), Amount: MustDecimal("1"), Time: base})
+		}(int64(i))
+	}
+	wg.Wait()
+
+	if overlaps != 0 {
+		t.Errorf("callbacks overlapped %d times", overlaps)
+	}
+	if len(ids) != 20 {
+		t.Errorf("delivered %d bars", len(ids))
+	}
+}
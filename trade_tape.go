@@ -0,0 +1,362 @@
+package hotcoin
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// TradeTape 有序逐笔成交流，按成交ID去重并检测ID不连续
+// 发现缺失时通过GetTrades回补，无法回补的缺失标注在缺失之后的第一笔成交上。
+// 成交ID需按笔连续递增
+type TradeTape struct {
+	client *Client
+	symbol string
+	config *TradeTapeConfig
+	recent func(ctx context.Context, symbol string, size int) ([]TradeData, error)
+
+	mutex sync.Mutex
+	seen  *recentIDs
+	last  int64
+	stats TradeTapeStats
+
+	// 回补期间缓存的成交
+	syncing  bool
+	buffered []Trade
+
+	// 待触发的成交，同一时刻只有一个goroutine触发回调
+	pending    []TapeEntry
+	delivering bool
+
+	sub *Subscription
+
+	onTrade TapeHandler
+	onError ErrorHandler
+}
+
+// NewTradeTape 创建逐笔成交流，client为nil时不回补缺失
+func NewTradeTape(client *Client, symbol string, config *TradeTapeConfig) *TradeTape {
+	if config == nil {
+		config = DefaultTradeTapeConfig()
+	}
+	if config.DedupSize <= 0 {
+		config.DedupSize = DefaultTradeDedupSize
+	}
+	if config.BackfillTimeout <= 0 {
+		config.BackfillTimeout = DefaultTradeTapeConfig().BackfillTimeout
+	}
+
+	tape := &TradeTape{
+		client: client,
+		symbol: symbol,
+		config: config,
+		seen:   newRecentIDs(config.DedupSize),
+	}
+	if client != nil && config.BackfillSize > 0 {
+		tape.recent = client.Market.getTrades
+	}
+	return tape
+}
+
+// OnTrade 设置成交回调，按成交ID顺序触发
+func (t *TradeTape) OnTrade(handler TapeHandler) {
+	t.onTrade = handler
+}
+
+// OnError 设置错误回调
+func (t *TradeTape) OnError(handler ErrorHandler) {
+	t.onError = handler
+}
+
+// Topic 获取成交推送主题
+func (t *TradeTape) Topic() string {
+	return fmt.Sprintf("market.%s.trade.detail", t.symbol)
+}
+
+// Start 开始接收WebSocket成交推送，WebSocket需已连接
+func (t *TradeTape) Start(ctx context.Context) error {
+	ws := t.client.WebSocket
+	if !ws.IsConnected() {
+		return fmt.Errorf("not connected")
+	}
+
+	t.mutex.Lock()
+	if t.sub != nil {
+		t.mutex.Unlock()
+		return fmt.Errorf("trade tape already started")
+	}
+	t.sub = ws.Bus().Subscribe(t.Topic(), t.handleMessage)
+	t.mutex.Unlock()
+
+	for _, topic := range ws.Topics() {
+		if topic == t.Topic() {
+			return nil
+		}
+	}
+	if err := ws.Subscribe(t.Topic()); err != nil {
+		t.Stop()
+		return err
+	}
+	return nil
+}
+
+// Stop 停止接收推送，不取消WebSocket订阅
+func (t *TradeTape) Stop() {
+	t.mutex.Lock()
+	sub := t.sub
+	t.sub = nil
+	t.mutex.Unlock()
+
+	if sub != nil {
+		sub.Unsubscribe()
+	}
+}
+
+// Add 添加成交，可用于手动驱动成交流
+func (t *TradeTape) Add(trades ...Trade) {
+	sorted := make([]Trade, len(trades))
+	copy(sorted, trades)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].ID < sorted[j].ID })
+
+	t.mutex.Lock()
+	if t.syncing {
+		t.buffered = append(t.buffered, sorted...)
+		t.mutex.Unlock()
+		return
+	}
+
+	entries, pending := t.processLocked(sorted, nil)
+	if pending != nil {
+		t.syncing = true
+		t.buffered = pending
+	}
+	t.pending = append(t.pending, entries...)
+	t.mutex.Unlock()
+
+	t.deliver()
+	if pending != nil {
+		go t.backfill()
+	}
+}
+
+// AddWSTrade 添加WebSocket成交推送
+func (t *TradeTape) AddWSTrade(data *WSTradeData) error {
+	trades := make([]Trade, 0, len(data.Data))
+	for _, item := range data.Data {
+		ts := item.Ts
+		if ts == 0 {
+			ts = data.Ts
+		}
+		trade, err := NewTrade(item.ID, item.Price, item.Amount, item.Direction, ts)
+		if err != nil {
+			return err
+		}
+		trades = append(trades, trade)
+	}
+	t.Add(trades...)
+	return nil
+}
+
+// LastID 获取最后一笔已输出成交的ID
+func (t *TradeTape) LastID() int64 {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return t.last
+}
+
+// Stats 获取成交流统计
+func (t *TradeTape) Stats() TradeTapeStats {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return t.stats
+}
+
+// handleMessage 处理成交推送消息
+func (t *TradeTape) handleMessage(message *WebSocketMessage) {
+	var data WSTradeData
+	if err := message.DecodeTick(&data); err != nil {
+		t.fail(fmt.Errorf("decode trade update: %w", err))
+		return
+	}
+	if err := t.AddWSTrade(&data); err != nil {
+		t.fail(err)
+	}
+}
+
+// processLocked 按ID顺序输出成交，遇到缺失且可回补时返回包括缺失后成交在内的待处理成交，
+// recovered为回补得到的成交，调用方需持有锁
+func (t *TradeTape) processLocked(trades []Trade, recovered map[int64]Trade) ([]TapeEntry, []Trade) {
+	ids := sortedTradeIDs(recovered)
+
+	var entries []TapeEntry
+	for i, trade := range trades {
+		if t.last > 0 && trade.ID <= t.last {
+			if t.seen.contains(trade.ID) {
+				t.stats.Duplicates++
+			} else {
+				t.stats.Late++
+			}
+			continue
+		}
+		if t.seen.contains(trade.ID) {
+			t.stats.Duplicates++
+			continue
+		}
+
+		var gap *TradeGap
+		if t.last > 0 && trade.ID > t.last+1 {
+			if recovered == nil && t.recent != nil {
+				return entries, trades[i:]
+			}
+
+			// 按ID顺序输出缺失区间内回补的成交，每段无法回补的区间标注在其后的第一笔成交上
+			start := sort.Search(len(ids), func(k int) bool { return ids[k] > t.last })
+			for _, id := range ids[start:] {
+				if id >= trade.ID {
+					break
+				}
+				entries = append(entries, t.deliverLocked(recovered[id], true, newTradeGap(t.last+1, id)))
+			}
+			gap = newTradeGap(t.last+1, trade.ID)
+		}
+		entries = append(entries, t.deliverLocked(trade, false, gap))
+	}
+	return entries, nil
+}
+
+// sortedTradeIDs 回补成交的ID升序列表
+func sortedTradeIDs(recovered map[int64]Trade) []int64 {
+	ids := make([]int64, 0, len(recovered))
+	for id := range recovered {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// newTradeGap 创建[from, next)区间的缺失，区间为空时返回nil
+func newTradeGap(from, next int64) *TradeGap {
+	if next <= from {
+		return nil
+	}
+	return &TradeGap{FromID: from, ToID: next - 1, Missing: next - from}
+}
+
+// deliverLocked 记录并输出成交，调用方需持有锁
+func (t *TradeTape) deliverLocked(trade Trade, backfilled bool, gap *TradeGap) TapeEntry {
+	t.seen.add(trade.ID)
+	t.last = trade.ID
+	t.stats.Delivered++
+	if backfilled {
+		t.stats.Backfilled++
+	}
+	if gap != nil {
+		t.stats.Gaps++
+		t.stats.Missing += gap.Missing
+	}
+	return TapeEntry{Trade: trade, Backfilled: backfilled, Gap: gap}
+}
+
+// backfill 通过GetTrades回补缺失成交后处理缓存的成交，调用前需将syncing置为true
+// 回补受BackfillTimeout限制，避免请求挂起时缓存无限增长
+func (t *TradeTape) backfill() {
+	ctx, cancel := context.WithTimeout(context.Background(), t.config.BackfillTimeout)
+	defer cancel()
+
+	recovered := make(map[int64]Trade)
+	data, err := t.recent(ctx, t.symbol, t.config.BackfillSize)
+	if err != nil {
+		t.fail(fmt.Errorf("backfill trades: %w", err))
+	}
+	for _, item := range data {
+		trade, err := NewTrade(item.ID, item.Price, item.Amount, item.Side, item.Timestamp)
+		if err != nil {
+			continue
+		}
+		recovered[trade.ID] = trade
+	}
+
+	t.mutex.Lock()
+	buffered := t.buffered
+	t.buffered = nil
+	t.syncing = false
+	sort.SliceStable(buffered, func(i, j int) bool { return buffered[i].ID < buffered[j].ID })
+	entries, _ := t.processLocked(buffered, recovered)
+	t.pending = append(t.pending, entries...)
+	t.mutex.Unlock()
+
+	t.deliver()
+}
+
+// deliver 按成交ID顺序触发待触发的成交，其他goroutine正在触发时直接返回，
+// 由该goroutine继续送出新产生的成交，保证回调不会并发或乱序
+func (t *TradeTape) deliver() {
+	t.mutex.Lock()
+	if t.delivering {
+		t.mutex.Unlock()
+		return
+	}
+	t.delivering = true
+	for len(t.pending) > 0 {
+		entries := t.pending
+		t.pending = nil
+		t.mutex.Unlock()
+		t.emit(entries)
+		t.mutex.Lock()
+	}
+	t.delivering = false
+	t.mutex.Unlock()
+}
+
+// emit 按顺序触发成交回调
+func (t *TradeTape) emit(entries []TapeEntry) {
+	if t.onTrade == nil {
+		return
+	}
+	for _, entry := range entries {
+		t.onTrade(entry)
+	}
+}
+
+// fail 触发错误回调
+func (t *TradeTape) fail(err error) {
+	if t.onError != nil {
+		t.onError(err)
+	}
+}
+
+// recentIDs 固定容量的最近ID集合，超出容量时淘汰最早的ID
+type recentIDs struct {
+	set   map[int64]struct{}
+	order []int64
+	next  int
+}
+
+// newRecentIDs 创建最近ID集合
+func newRecentIDs(size int) *recentIDs {
+	return &recentIDs{
+		set:   make(map[int64]struct{}, size),
+		order: make([]int64, size),
+	}
+}
+
+// contains 是否包含id
+func (r *recentIDs) contains(id int64) bool {
+	_, ok := r.set[id]
+	return ok
+}
+
+// add 记录id，已存在时返回false
+func (r *recentIDs) add(id int64) bool {
+	if r.contains(id) {
+		return false
+	}
+	if old := r.order[r.next]; old != 0 {
+		delete(r.set, old)
+	}
+	r.order[r.next] = id
+	r.next = (r.next + 1) % len(r.order)
+	r.set[id] = struct{}{}
+	return true
+}
@@ -0,0 +1,183 @@
+package hotcoin
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newTapeTrade(id int64) Trade {
+	return Trade{ID: id, Price: MustDecimal("100"), Amount: MustDecimal("1"), Time: time.Unix(id, 0)}
+}
+
+func TestTradeTapeBackfill(t *testing.T) {
+	tape := NewTradeTape(nil, "btcusdt", nil)
+	done := make(chan struct{})
+	tape.recent = func(ctx context.Context, symbol string, size int) ([]TradeData, error) {
+		// 缺失3、4、5，只能回补4
+		return []TradeData{{ID: 4, Price: "100", Amount: "1", Timestamp: 4000}}, nil
+	}
+
+	var entries []TapeEntry
+	tape.OnTrade(func(entry TapeEntry) {
+		entries = append(entries, entry)
+		if entry.Trade.ID == 7 {
+			close(done)
+		}
+	})
+
+	tape.Add(newTapeTrade(2), newTapeTrade(1), newTapeTrade(2))
+	tape.Add(newTapeTrade(6), newTapeTrade(7))
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for backfill")
+	}
+
+	var ids []int64
+	for _, entry := range entries {
+		ids = append(ids, entry.Trade.ID)
+	}
+	if len(ids) != 5 || ids[0] != 1 || ids[1] != 2 || ids[2] != 4 || ids[3] != 6 || ids[4] != 7 {
+		t.Fatalf("unexpected order: %v", ids)
+	}
+
+	backfilled := entries[2]
+	if !backfilled.Backfilled || backfilled.Gap == nil || backfilled.Gap.FromID != 3 || backfilled.Gap.Missing != 1 {
+		t.Errorf("unexpected backfilled entry: %+v", backfilled)
+	}
+	if gap := entries[3].Gap; gap == nil || gap.FromID != 5 || gap.ToID != 5 {
+		t.Errorf("unexpected gap: %+v", gap)
+	}
+
+	stats := tape.Stats()
+	if stats.Delivered != 5 || stats.Duplicates != 1 || stats.Backfilled != 1 || stats.Missing != 2 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+
+	tape.Add(newTapeTrade(5))
+	if stats := tape.Stats(); stats.Late != 1 {
+		t.Errorf("late = %d", stats.Late)
+	}
+}
+
+func TestTradeTapeWithoutBackfill(t *testing.T) {
+	tape := NewTradeTape(nil, "btcusdt", nil)
+
+	var entries []TapeEntry
+	tape.OnTrade(func(entry TapeEntry) { entries = append(entries, entry) })
+	tape.Add(newTapeTrade(1), newTapeTrade(4))
+
+	if len(entries) != 2 || entries[1].Gap == nil || entries[1].Gap.Missing != 2 {
+		t.Fatalf("unexpected entries: %+v", entries)
+	}
+}
+
+func TestTradeTapeLargeGap(t *testing.T) {
+	tape := NewTradeTape(nil, "btcusdt", nil)
+	tape.recent = func(ctx context.Context, symbol string, size int) ([]TradeData, error) {
+		return []TradeData{{ID: 1_000_000_000, Price: "100", Amount: "1", Timestamp: 4000}}, nil
+	}
+
+	entries := make(chan TapeEntry, 4)
+	tape.OnTrade(func(entry TapeEntry) { entries <- entry })
+
+	start := time.Now()
+	tape.Add(newTapeTrade(1), newTapeTrade(2_000_000_000))
+
+	var got []TapeEntry
+	for len(got) < 3 {
+		select {
+		case entry := <-entries:
+			got = append(got, entry)
+		case <-time.After(2 * time.Second):
+			t.Fatalf("timeout waiting for entries, got %+v", got)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("large gap took %v", elapsed)
+	}
+
+	if gap := got[1].Gap; !got[1].Backfilled || gap == nil || gap.FromID != 2 || gap.ToID != 999_999_999 || gap.Missing != 999_999_998 {
+		t.Errorf("unexpected backfilled entry: %+v %+v", got[1], gap)
+	}
+	if gap := got[2].Gap; gap == nil || gap.FromID != 1_000_000_001 || gap.ToID != 1_999_999_999 || gap.Missing != 999_999_999 {
+		t.Errorf("unexpected gap: %+v", gap)
+	}
+	if stats := tape.Stats(); stats.Gaps != 2 || stats.Missing != 1_999_999_997 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestTradeTapeBackfillTimeout(t *testing.T) {
+	tape := NewTradeTape(nil, "btcusdt", &TradeTapeConfig{BackfillTimeout: 20 * time.Millisecond})
+	tape.recent = func(ctx context.Context, symbol string, size int) ([]TradeData, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+
+	failed := make(chan error, 1)
+	tape.OnError(func(err error) { failed <- err })
+	done := make(chan TapeEntry, 1)
+	tape.OnTrade(func(entry TapeEntry) {
+		if entry.Trade.ID == 5 {
+			done <- entry
+		}
+	})
+
+	tape.Add(newTapeTrade(1), newTapeTrade(5))
+	select {
+	case entry := <-done:
+		if entry.Gap == nil || entry.Gap.Missing != 3 {
+			t.Errorf("unexpected entry: %+v", entry)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("backfill did not time out")
+	}
+	if err := <-failed; !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+}
+
+func TestTradeTapeSerializesDelivery(t *testing.T) {
+	tape := NewTradeTape(nil, "btcusdt", nil)
+
+	var mutex sync.Mutex
+	var ids []int64
+	active := 0
+	added := make(chan struct{})
+	tape.OnTrade(func(entry TapeEntry) {
+		mutex.Lock()
+		active++
+		if active > 1 {
+			t.Error("callbacks run concurrently")
+		}
+		ids = append(ids, entry.Trade.ID)
+		first := len(ids) == 1
+		mutex.Unlock()
+
+		if first {
+			// 回调期间其他goroutine添加的成交排在当前成交之后触发
+			go func() {
+				tape.Add(newTapeTrade(2))
+				close(added)
+			}()
+			<-added
+		}
+
+		mutex.Lock()
+		active--
+		mutex.Unlock()
+	})
+
+	tape.Add(newTapeTrade(1))
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	if len(ids) != 2 || ids[0] != 1 || ids[1] != 2 {
+		t.Errorf("unexpected delivery order: %v", ids)
+	}
+}
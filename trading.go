@@ -14,17 +14,22 @@ func (t *TradingService) PlaceOrder(req *OrderPlaceRequest) (*OrderPlaceResponse
 	if req == nil {
 		return nil, fmt.Errorf("request is required")
 	}
-	if req.Symbol == "" {
-		return nil, fmt.Errorf("symbol is required")
+	if err := req.Validate(); err != nil {
+		return nil, err
 	}
-	if req.Direction == "" {
-		return nil, fmt.Errorf("direction is required")
+	if err := t.client.validateOrder(req); err != nil {
+		return nil, err
 	}
-	if req.Volume == "" {
-		return nil, fmt.Errorf("volume is required")
+
+	// 交易对按规范合约代码发送，不修改传入的请求
+	order := *req
+	code, err := t.client.contractCode(req.Symbol)
+	if err != nil {
+		return nil, err
 	}
+	order.Symbol = code
 
-	resp, err := t.client.post(context.Background(), "/api/v1/perpetual/orders", req, true)
+	resp, err := t.client.post(context.Background(), "/api/v1/perpetual/orders", &order, true)
 	if err != nil {
 		return nil, err
 	}
@@ -35,14 +40,14 @@ func (t *TradingService) PlaceOrder(req *OrderPlaceRequest) (*OrderPlaceResponse
 		Ts     int64              `json:"ts"`
 	}
 
-	// 直接解析到目标结构体
+	// 直接解析到目标结构体，请求已被处理，解析失败时下单结果未知
 	dataBytes, err := json.Marshal(resp.Data)
 	if err != nil {
-		return nil, fmt.Errorf("marshal response data: %w", err)
+		return nil, fmt.Errorf("marshal response data: %w", &outcomeUnknownError{err: err})
 	}
 	err = json.Unmarshal(dataBytes, &response)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("decode response data: %w", &outcomeUnknownError{err: err})
 	}
 
 	if response.Status != "ok" {
@@ -63,8 +68,24 @@ func (t *TradingService) PlaceBatchOrders(req *BatchOrderRequest) (*BatchOrderRe
 	if len(req.Orders) > 10 {
 		return nil, fmt.Errorf("orders count cannot exceed 10")
 	}
-
-	resp, err := t.client.post(context.Background(), "/api/v1/perpetual/orders/batch", req, true)
+	batch := *req
+	batch.Orders = make([]OrderPlaceRequest, len(req.Orders))
+	for i := range req.Orders {
+		if err := req.Orders[i].Validate(); err != nil {
+			return nil, fmt.Errorf("orders[%d]: %w", i, err)
+		}
+		if err := t.client.validateOrder(&req.Orders[i]); err != nil {
+			return nil, fmt.Errorf("orders[%d]: %w", i, err)
+		}
+		code, err := t.client.contractCode(req.Orders[i].Symbol)
+		if err != nil {
+			return nil, fmt.Errorf("orders[%d]: %w", i, err)
+		}
+		batch.Orders[i] = req.Orders[i]
+		batch.Orders[i].Symbol = code
+	}
+
+	resp, err := t.client.post(context.Background(), "/api/v1/perpetual/orders/batch", &batch, true)
 	if err != nil {
 		return nil, err
 	}
@@ -75,14 +96,14 @@ func (t *TradingService) PlaceBatchOrders(req *BatchOrderRequest) (*BatchOrderRe
 		Ts     int64              `json:"ts"`
 	}
 
-	// 直接解析到目标结构体
+	// 直接解析到目标结构体，请求已被处理，解析失败时下单结果未知
 	dataBytes, err := json.Marshal(resp.Data)
 	if err != nil {
-		return nil, fmt.Errorf("marshal response data: %w", err)
+		return nil, fmt.Errorf("marshal response data: %w", &outcomeUnknownError{err: err})
 	}
 	err = json.Unmarshal(dataBytes, &response)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("decode response data: %w", &outcomeUnknownError{err: err})
 	}
 
 	if response.Status != "ok" {
@@ -322,10 +343,10 @@ func (t *TradingService) GetOrderHistory(req *OrderQueryRequest) ([]Order, error
 		"symbol": req.Symbol,
 	}
 	if req.OrderType > 0 {
-		params["order_type"] = strconv.Itoa(req.OrderType)
+		params["order_type"] = strconv.Itoa(int(req.OrderType))
 	}
 	if req.Status > 0 {
-		params["status"] = strconv.Itoa(req.Status)
+		params["status"] = strconv.Itoa(int(req.Status))
 	}
 	if req.CreateDate > 0 {
 		params["create_date"] = strconv.Itoa(req.CreateDate)
@@ -420,17 +441,22 @@ func (t *TradingService) PlacePlanOrder(req *PlanOrderRequest) (*OrderPlaceRespo
 	if req == nil {
 		return nil, fmt.Errorf("request is required")
 	}
-	if req.Symbol == "" {
-		return nil, fmt.Errorf("symbol is required")
+	if err := req.Validate(); err != nil {
+		return nil, err
 	}
-	if req.TriggerPrice == "" {
-		return nil, fmt.Errorf("trigger_price is required")
+	if err := t.client.validatePlanOrder(req); err != nil {
+		return nil, err
 	}
-	if req.Volume == "" {
-		return nil, fmt.Errorf("volume is required")
+
+	// 交易对按规范合约代码发送，不修改传入的请求
+	order := *req
+	code, err := t.client.contractCode(req.Symbol)
+	if err != nil {
+		return nil, err
 	}
+	order.Symbol = code
 
-	resp, err := t.client.post(context.Background(), "/api/v1/perpetual/orders/trigger", req, true)
+	resp, err := t.client.post(context.Background(), "/api/v1/perpetual/orders/trigger", &order, true)
 	if err != nil {
 		return nil, err
 	}
@@ -441,14 +467,14 @@ func (t *TradingService) PlacePlanOrder(req *PlanOrderRequest) (*OrderPlaceRespo
 		Ts     int64              `json:"ts"`
 	}
 
-	// 直接解析到目标结构体
+	// 直接解析到目标结构体，请求已被处理，解析失败时下单结果未知
 	dataBytes, err := json.Marshal(resp.Data)
 	if err != nil {
-		return nil, fmt.Errorf("marshal response data: %w", err)
+		return nil, fmt.Errorf("marshal response data: %w", &outcomeUnknownError{err: err})
 	}
 	err = json.Unmarshal(dataBytes, &response)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("decode response data: %w", &outcomeUnknownError{err: err})
 	}
 
 	if response.Status != "ok" {
@@ -1,45 +1,47 @@
 package hotcoin
 
+import "time"
+
 // Order 订单信息
 type Order struct {
-	OrderID        string `json:"order_id"`         // 订单ID
-	OrderIDStr     string `json:"order_id_str"`     // 订单ID字符串
-	Symbol         string `json:"symbol"`           // 交易对
-	ContractCode   string `json:"contract_code"`    // 合约代码
-	ContractType   string `json:"contract_type"`    // 合约类型
-	Direction      string `json:"direction"`        // 买卖方向 buy-买 sell-卖
-	Offset         string `json:"offset"`           // 开平方向 open-开 close-平
-	Volume         string `json:"volume"`           // 委托数量
-	Price          string `json:"price"`            // 委托价格
-	CreateDate     int64  `json:"create_date"`      // 创建时间
-	OrderSource    string `json:"order_source"`     // 订单来源
-	OrderPriceType string `json:"order_price_type"` // 订单类型
-	MarginFrozen   string `json:"margin_frozen"`    // 冻结保证金
-	Profit         string `json:"profit"`           // 收益
-	Instrument     string `json:"instrument"`       // 合约标识
-	OrderType      int    `json:"order_type"`       // 订单类型 1-报单 2-撤单 3-强平 4-交割
-	Status         int    `json:"status"`           // 订单状态
-	LeverRate      int    `json:"lever_rate"`       // 杠杆倍数
-	Fee            string `json:"fee"`              // 手续费
-	FeeAsset       string `json:"fee_asset"`        // 手续费币种
-	CanceledAt     int64  `json:"canceled_at"`      // 撤销时间
-	TradeVolume    string `json:"trade_volume"`     // 成交数量
-	TradeTurnover  string `json:"trade_turnover"`   // 成交金额
-	TradeAvgPrice  string `json:"trade_avg_price"`  // 成交均价
+	OrderID        string         `json:"order_id"`         // 订单ID
+	OrderIDStr     string         `json:"order_id_str"`     // 订单ID字符串
+	Symbol         string         `json:"symbol"`           // 交易对
+	ContractCode   string         `json:"contract_code"`    // 合约代码
+	ContractType   string         `json:"contract_type"`    // 合约类型
+	Direction      OrderSide      `json:"direction"`        // 买卖方向 buy-买 sell-卖
+	Offset         OrderOffset    `json:"offset"`           // 开平方向 open-开 close-平
+	Volume         string         `json:"volume"`           // 委托数量
+	Price          string         `json:"price"`            // 委托价格
+	CreateDate     Timestamp      `json:"create_date"`      // 创建时间
+	OrderSource    string         `json:"order_source"`     // 订单来源
+	OrderPriceType OrderPriceType `json:"order_price_type"` // 订单类型
+	MarginFrozen   string         `json:"margin_frozen"`    // 冻结保证金
+	Profit         string         `json:"profit"`           // 收益
+	Instrument     string         `json:"instrument"`       // 合约标识
+	OrderType      OrderType      `json:"order_type"`       // 订单类型 1-报单 2-撤单 3-强平 4-交割
+	Status         OrderStatus    `json:"status"`           // 订单状态
+	LeverRate      int            `json:"lever_rate"`       // 杠杆倍数
+	Fee            string         `json:"fee"`              // 手续费
+	FeeAsset       string         `json:"fee_asset"`        // 手续费币种
+	CanceledAt     Timestamp      `json:"canceled_at"`      // 撤销时间
+	TradeVolume    string         `json:"trade_volume"`     // 成交数量
+	TradeTurnover  string         `json:"trade_turnover"`   // 成交金额
+	TradeAvgPrice  string         `json:"trade_avg_price"`  // 成交均价
 }
 
 // OrderPlaceRequest 下单请求
 type OrderPlaceRequest struct {
-	Symbol         string `json:"symbol"`           // 交易对
-	ContractType   string `json:"contract_type"`    // 合约类型
-	ContractCode   string `json:"contract_code"`    // 合约代码
-	ClientOrderID  string `json:"client_order_id"`  // 客户自定义订单ID
-	Price          string `json:"price"`            // 价格
-	Volume         string `json:"volume"`           // 数量
-	Direction      string `json:"direction"`        // 买卖方向
-	Offset         string `json:"offset"`           // 开平方向
-	LeverRate      int    `json:"lever_rate"`       // 杠杆倍数
-	OrderPriceType string `json:"order_price_type"` // 订单类型
+	Symbol         string         `json:"symbol"`           // 交易对
+	ContractType   string         `json:"contract_type"`    // 合约类型
+	ContractCode   string         `json:"contract_code"`    // 合约代码
+	ClientOrderID  string         `json:"client_order_id"`  // 客户自定义订单ID
+	Price          string         `json:"price"`            // 价格
+	Volume         string         `json:"volume"`           // 数量
+	Direction      OrderSide      `json:"direction"`        // 买卖方向
+	Offset         OrderOffset    `json:"offset"`           // 开平方向
+	LeverRate      int            `json:"lever_rate"`       // 杠杆倍数
+	OrderPriceType OrderPriceType `json:"order_price_type"` // 订单类型
 }
 
 // OrderPlaceResponse 下单响应
@@ -74,13 +76,13 @@ type OrderDetail struct {
 
 // TradeDetail 成交明细
 type TradeDetail struct {
-	TradeID       string `json:"trade_id"`       // 成交ID
-	TradeVolume   string `json:"trade_volume"`   // 成交数量
-	TradePrice    string `json:"trade_price"`    // 成交价格
-	TradeFee      string `json:"trade_fee"`      // 成交手续费
-	TradeTurnover string `json:"trade_turnover"` // 成交金额
-	CreatedAt     int64  `json:"created_at"`     // 成交时间
-	Role          string `json:"role"`           // 成交角色 maker/taker
+	TradeID       string    `json:"trade_id"`       // 成交ID
+	TradeVolume   string    `json:"trade_volume"`   // 成交数量
+	TradePrice    string    `json:"trade_price"`    // 成交价格
+	TradeFee      string    `json:"trade_fee"`      // 成交手续费
+	TradeTurnover string    `json:"trade_turnover"` // 成交金额
+	CreatedAt     Timestamp `json:"created_at"`     // 成交时间
+	Role          string    `json:"role"`           // 成交角色 maker/taker
 }
 
 // BatchOrderRequest 批量下单请求
@@ -100,74 +102,104 @@ type BatchOrderResponse struct {
 
 // OrderQueryRequest 查询订单请求
 type OrderQueryRequest struct {
-	Symbol        string `json:"symbol"`          // 交易对
-	OrderID       string `json:"order_id"`        // 订单ID
-	ClientOrderID string `json:"client_order_id"` // 客户订单ID
-	OrderType     int    `json:"order_type"`      // 订单类型
-	Status        int    `json:"status"`          // 订单状态
-	CreateDate    int    `json:"create_date"`     // 创建日期
-	PageIndex     int    `json:"page_index"`      // 页码
-	PageSize      int    `json:"page_size"`       // 每页大小
+	Symbol        string      `json:"symbol"`          // 交易对
+	OrderID       string      `json:"order_id"`        // 订单ID
+	ClientOrderID string      `json:"client_order_id"` // 客户订单ID
+	OrderType     OrderType   `json:"order_type"`      // 订单类型
+	Status        OrderStatus `json:"status"`          // 订单状态
+	CreateDate    int         `json:"create_date"`     // 创建日期
+	PageIndex     int         `json:"page_index"`      // 页码
+	PageSize      int         `json:"page_size"`       // 每页大小
 }
 
 // PlanOrder 计划委托订单
 type PlanOrder struct {
-	OrderID        string `json:"order_id"`         // 订单ID
-	OrderIDStr     string `json:"order_id_str"`     // 订单ID字符串
-	Symbol         string `json:"symbol"`           // 交易对
-	ContractCode   string `json:"contract_code"`    // 合约代码
-	ContractType   string `json:"contract_type"`    // 合约类型
-	TriggerType    string `json:"trigger_type"`     // 触发类型
-	Volume         string `json:"volume"`           // 委托数量
-	OrderType      int    `json:"order_type"`       // 订单类型
-	Direction      string `json:"direction"`        // 买卖方向
-	Offset         string `json:"offset"`           // 开平方向
-	LeverRate      int    `json:"lever_rate"`       // 杠杆倍数
-	OrderPrice     string `json:"order_price"`      // 委托价格
-	OrderPriceType string `json:"order_price_type"` // 订单价格类型
-	TriggerPrice   string `json:"trigger_price"`    // 触发价格
-	CreatedAt      int64  `json:"created_at"`       // 创建时间
-	OrderSource    string `json:"order_source"`     // 订单来源
-	Status         int    `json:"status"`           // 订单状态
-	OrderOrigType  int    `json:"order_orig_type"`  // 订单原始类型
+	OrderID        string          `json:"order_id"`         // 订单ID
+	OrderIDStr     string          `json:"order_id_str"`     // 订单ID字符串
+	Symbol         string          `json:"symbol"`           // 交易对
+	ContractCode   string          `json:"contract_code"`    // 合约代码
+	ContractType   string          `json:"contract_type"`    // 合约类型
+	TriggerType    TriggerType     `json:"trigger_type"`     // 触发类型
+	Volume         string          `json:"volume"`           // 委托数量
+	OrderType      OrderType       `json:"order_type"`       // 订单类型
+	Direction      OrderSide       `json:"direction"`        // 买卖方向
+	Offset         OrderOffset     `json:"offset"`           // 开平方向
+	LeverRate      int             `json:"lever_rate"`       // 杠杆倍数
+	OrderPrice     string          `json:"order_price"`      // 委托价格
+	OrderPriceType OrderPriceType  `json:"order_price_type"` // 订单价格类型
+	TriggerPrice   string          `json:"trigger_price"`    // 触发价格
+	CreatedAt      Timestamp       `json:"created_at"`       // 创建时间
+	OrderSource    string          `json:"order_source"`     // 订单来源
+	Status         PlanOrderStatus `json:"status"`           // 订单状态
+	OrderOrigType  OrderType       `json:"order_orig_type"`  // 订单原始类型
 }
 
 // PlanOrderRequest 计划委托下单请求
 type PlanOrderRequest struct {
-	Symbol         string `json:"symbol"`           // 交易对
-	ContractType   string `json:"contract_type"`    // 合约类型
-	ContractCode   string `json:"contract_code"`    // 合约代码
-	TriggerType    string `json:"trigger_type"`     // 触发类型 ge-大于等于 le-小于等于
-	TriggerPrice   string `json:"trigger_price"`    // 触发价格
-	OrderPrice     string `json:"order_price"`      // 委托价格
-	OrderPriceType string `json:"order_price_type"` // 订单类型
-	Volume         string `json:"volume"`           // 数量
-	Direction      string `json:"direction"`        // 买卖方向
-	Offset         string `json:"offset"`           // 开平方向
-	LeverRate      int    `json:"lever_rate"`       // 杠杆倍数
+	Symbol         string         `json:"symbol"`           // 交易对
+	ContractType   string         `json:"contract_type"`    // 合约类型
+	ContractCode   string         `json:"contract_code"`    // 合约代码
+	TriggerType    TriggerType    `json:"trigger_type"`     // 触发类型 ge-大于等于 le-小于等于
+	TriggerPrice   string         `json:"trigger_price"`    // 触发价格
+	OrderPrice     string         `json:"order_price"`      // 委托价格
+	OrderPriceType OrderPriceType `json:"order_price_type"` // 订单类型
+	Volume         string         `json:"volume"`           // 数量
+	Direction      OrderSide      `json:"direction"`        // 买卖方向
+	Offset         OrderOffset    `json:"offset"`           // 开平方向
+	LeverRate      int            `json:"lever_rate"`       // 杠杆倍数
 }
 
 // StopOrderRequest 止盈止损订单请求
 type StopOrderRequest struct {
-	Symbol           string `json:"symbol"`              // 交易对
-	ContractCode     string `json:"contract_code"`       // 合约代码
-	ContractType     string `json:"contract_type"`       // 合约类型
-	Direction        string `json:"direction"`           // 买卖方向
-	Volume           string `json:"volume"`              // 数量
-	TpTriggerPrice   string `json:"tp_trigger_price"`    // 止盈触发价格
-	TpOrderPrice     string `json:"tp_order_price"`      // 止盈委托价格
-	TpOrderPriceType string `json:"tp_order_price_type"` // 止盈订单类型
-	SlTriggerPrice   string `json:"sl_trigger_price"`    // 止损触发价格
-	SlOrderPrice     string `json:"sl_order_price"`      // 止损委托价格
-	SlOrderPriceType string `json:"sl_order_price_type"` // 止损订单类型
+	Symbol           string         `json:"symbol"`              // 交易对
+	ContractCode     string         `json:"contract_code"`       // 合约代码
+	ContractType     string         `json:"contract_type"`       // 合约类型
+	Direction        OrderSide      `json:"direction"`           // 买卖方向
+	Volume           string         `json:"volume"`              // 数量
+	TpTriggerPrice   string         `json:"tp_trigger_price"`    // 止盈触发价格
+	TpOrderPrice     string         `json:"tp_order_price"`      // 止盈委托价格
+	TpOrderPriceType OrderPriceType `json:"tp_order_price_type"` // 止盈订单类型
+	SlTriggerPrice   string         `json:"sl_trigger_price"`    // 止损触发价格
+	SlOrderPrice     string         `json:"sl_order_price"`      // 止损委托价格
+	SlOrderPriceType OrderPriceType `json:"sl_order_price_type"` // 止损订单类型
 }
 
 // MatchResult 撮合结果
 type MatchResult struct {
-	Symbol    string `json:"symbol"`    // 交易对
-	OrderID   string `json:"order_id"`  // 订单ID
-	TradeID   string `json:"trade_id"`  // 成交ID
-	Volume    string `json:"volume"`    // 成交数量
-	Price     string `json:"price"`     // 成交价格
-	Timestamp int64  `json:"timestamp"` // 成交时间
+	Symbol    string    `json:"symbol"`    // 交易对
+	OrderID   string    `json:"order_id"`  // 订单ID
+	TradeID   string    `json:"trade_id"`  // 成交ID
+	Volume    string    `json:"volume"`    // 成交数量
+	Price     string    `json:"price"`     // 成交价格
+	Timestamp Timestamp `json:"timestamp"` // 成交时间
+}
+
+// OrderSubmitterConfig 幂等下单配置
+type OrderSubmitterConfig struct {
+	Prefix         string               // 客户订单ID前缀，用于标记策略；仅含数字且不超过2位时ID可解析为int64
+	MaxAttempts    int                  // 最大下单次数，仅在确认订单不存在后重试
+	RetryDelay     time.Duration        // 重试间隔
+	LookupAttempts int                  // 结果未知时按客户订单ID查询的次数，每次都返回空结果才确认订单不存在
+	LookupDelay    time.Duration        // 查询前的等待时间，给交易所留出落单时间
+	IsNotFound     func(err error) bool // 判断查询错误是否表示订单不存在，nil表示仅以空结果判断
+}
+
+// DefaultOrderSubmitterConfig 默认幂等下单配置
+func DefaultOrderSubmitterConfig() *OrderSubmitterConfig {
+	return &OrderSubmitterConfig{
+		MaxAttempts:    3,
+		RetryDelay:     500 * time.Millisecond,
+		LookupAttempts: 3,
+		LookupDelay:    time.Second,
+	}
+}
+
+// OrderSubmitResult 幂等下单结果
+type OrderSubmitResult struct {
+	ClientOrderID string              // 客户订单ID
+	OrderID       string              // 订单ID
+	Response      *OrderPlaceResponse // 下单响应，通过查询恢复时为nil
+	Order         *Order              // 通过查询恢复的订单
+	Attempts      int                 // 下单请求次数
+	Recovered     bool                // 下单结果未知后通过查询确认订单已存在
 }
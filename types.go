@@ -1,6 +1,7 @@
 package hotcoin
 
 import (
+	"fmt"
 	"time"
 )
 
@@ -47,24 +48,220 @@ const (
 	OrderSideSell OrderSide = "sell"
 )
 
+// String 返回方向字符串
+func (s OrderSide) String() string {
+	return string(s)
+}
+
+// Valid 是否为有效的订单方向
+func (s OrderSide) Valid() bool {
+	return s == OrderSideBuy || s == OrderSideSell
+}
+
+// Opposite 返回相反方向
+func (s OrderSide) Opposite() OrderSide {
+	if s == OrderSideBuy {
+		return OrderSideSell
+	}
+	return OrderSideBuy
+}
+
+// OrderOffset 开平方向
+type OrderOffset string
+
+const (
+	OrderOffsetOpen  OrderOffset = "open"  // 开仓
+	OrderOffsetClose OrderOffset = "close" // 平仓
+)
+
+// String 返回开平方向字符串
+func (o OrderOffset) String() string {
+	return string(o)
+}
+
+// Valid 是否为有效的开平方向
+func (o OrderOffset) Valid() bool {
+	return o == OrderOffsetOpen || o == OrderOffsetClose
+}
+
+// OrderPriceType 订单价格类型
+type OrderPriceType string
+
+const (
+	OrderPriceTypeLimit    OrderPriceType = "limit"     // 限价
+	OrderPriceTypeMarket   OrderPriceType = "market"    // 市价
+	OrderPriceTypePostOnly OrderPriceType = "post_only" // 只做maker
+	OrderPriceTypeIOC      OrderPriceType = "ioc"       // 立即成交并撤销剩余
+	OrderPriceTypeFOK      OrderPriceType = "fok"       // 全部成交或立即撤销
+	OrderPriceTypeOpponent OrderPriceType = "opponent"  // 对手价
+)
+
+// String 返回价格类型字符串
+func (t OrderPriceType) String() string {
+	return string(t)
+}
+
+// Valid 是否为有效的价格类型
+func (t OrderPriceType) Valid() bool {
+	switch t {
+	case OrderPriceTypeLimit, OrderPriceTypeMarket, OrderPriceTypePostOnly,
+		OrderPriceTypeIOC, OrderPriceTypeFOK, OrderPriceTypeOpponent:
+		return true
+	}
+	return false
+}
+
+// RequiresPrice 该价格类型是否需要指定委托价格
+func (t OrderPriceType) RequiresPrice() bool {
+	switch t {
+	case OrderPriceTypeLimit, OrderPriceTypePostOnly, OrderPriceTypeIOC, OrderPriceTypeFOK:
+		return true
+	}
+	return false
+}
+
 // OrderType 订单类型
-type OrderType string
+type OrderType int
 
 const (
-	OrderTypeMarket OrderType = "market"
-	OrderTypeLimit  OrderType = "limit"
+	OrderTypePlace       OrderType = 1 // 报单
+	OrderTypeCancel      OrderType = 2 // 撤单
+	OrderTypeLiquidation OrderType = 3 // 强平
+	OrderTypeDelivery    OrderType = 4 // 交割
 )
 
+// String 返回订单类型名称
+func (t OrderType) String() string {
+	switch t {
+	case OrderTypePlace:
+		return "place"
+	case OrderTypeCancel:
+		return "cancel"
+	case OrderTypeLiquidation:
+		return "liquidation"
+	case OrderTypeDelivery:
+		return "delivery"
+	default:
+		return fmt.Sprintf("OrderType(%d)", int(t))
+	}
+}
+
+// Valid 是否为有效的订单类型
+func (t OrderType) Valid() bool {
+	return t >= OrderTypePlace && t <= OrderTypeDelivery
+}
+
 // OrderStatus 订单状态
-type OrderStatus string
+type OrderStatus int
+
+const (
+	OrderStatusPreparing       OrderStatus = 1  // 准备提交
+	OrderStatusPreparing2      OrderStatus = 2  // 准备提交（已进入撮合队列）
+	OrderStatusSubmitted       OrderStatus = 3  // 已提交
+	OrderStatusPartialFilled   OrderStatus = 4  // 部分成交
+	OrderStatusPartialCanceled OrderStatus = 5  // 部分成交已撤单
+	OrderStatusFilled          OrderStatus = 6  // 全部成交
+	OrderStatusCanceled        OrderStatus = 7  // 已撤单
+	OrderStatusCanceling       OrderStatus = 11 // 撤单中
+)
+
+// String 返回订单状态名称
+func (s OrderStatus) String() string {
+	switch s {
+	case OrderStatusPreparing, OrderStatusPreparing2:
+		return "preparing"
+	case OrderStatusSubmitted:
+		return "submitted"
+	case OrderStatusPartialFilled:
+		return "partial_filled"
+	case OrderStatusPartialCanceled:
+		return "partial_canceled"
+	case OrderStatusFilled:
+		return "filled"
+	case OrderStatusCanceled:
+		return "canceled"
+	case OrderStatusCanceling:
+		return "canceling"
+	default:
+		return fmt.Sprintf("OrderStatus(%d)", int(s))
+	}
+}
+
+// Valid 是否为已知的订单状态
+func (s OrderStatus) Valid() bool {
+	return (s >= OrderStatusPreparing && s <= OrderStatusCanceled) || s == OrderStatusCanceling
+}
+
+// IsFinal 订单是否已结束，不会再有成交
+func (s OrderStatus) IsFinal() bool {
+	return s == OrderStatusPartialCanceled || s == OrderStatusFilled || s == OrderStatusCanceled
+}
+
+// IsActive 订单是否仍在挂单或处理中
+func (s OrderStatus) IsActive() bool {
+	return s.Valid() && !s.IsFinal()
+}
+
+// TriggerType 计划委托触发类型
+type TriggerType string
 
 const (
-	OrderStatusPending   OrderStatus = "pending"
-	OrderStatusFilled    OrderStatus = "filled"
-	OrderStatusCancelled OrderStatus = "cancelled"
-	OrderStatusPartial   OrderStatus = "partial"
+	TriggerTypeGE TriggerType = "ge" // 最新价大于等于触发价时触发
+	TriggerTypeLE TriggerType = "le" // 最新价小于等于触发价时触发
 )
 
+// String 返回触发类型字符串
+func (t TriggerType) String() string {
+	return string(t)
+}
+
+// Valid 是否为有效的触发类型
+func (t TriggerType) Valid() bool {
+	return t == TriggerTypeGE || t == TriggerTypeLE
+}
+
+// PlanOrderStatus 计划委托状态
+type PlanOrderStatus int
+
+const (
+	PlanOrderStatusPreparing PlanOrderStatus = 1 // 准备提交
+	PlanOrderStatusSubmitted PlanOrderStatus = 2 // 已提交
+	PlanOrderStatusOrdering  PlanOrderStatus = 3 // 报单中
+	PlanOrderStatusOrdered   PlanOrderStatus = 4 // 报单成功
+	PlanOrderStatusFailed    PlanOrderStatus = 5 // 报单失败
+	PlanOrderStatusCanceled  PlanOrderStatus = 6 // 已撤单
+)
+
+// String 返回计划委托状态名称
+func (s PlanOrderStatus) String() string {
+	switch s {
+	case PlanOrderStatusPreparing:
+		return "preparing"
+	case PlanOrderStatusSubmitted:
+		return "submitted"
+	case PlanOrderStatusOrdering:
+		return "ordering"
+	case PlanOrderStatusOrdered:
+		return "ordered"
+	case PlanOrderStatusFailed:
+		return "failed"
+	case PlanOrderStatusCanceled:
+		return "canceled"
+	default:
+		return fmt.Sprintf("PlanOrderStatus(%d)", int(s))
+	}
+}
+
+// Valid 是否为已知的计划委托状态
+func (s PlanOrderStatus) Valid() bool {
+	return s >= PlanOrderStatusPreparing && s <= PlanOrderStatusCanceled
+}
+
+// IsFinal 计划委托是否已结束
+func (s PlanOrderStatus) IsFinal() bool {
+	return s == PlanOrderStatusOrdered || s == PlanOrderStatusFailed || s == PlanOrderStatusCanceled
+}
+
 // PositionSide 持仓方向
 type PositionSide string
 
@@ -1,17 +1,16 @@
 package hotcoin
 
 import (
-	"compress/gzip"
+	"bytes"
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
-	"io"
 	"log"
 	"net/url"
-	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/websocket"
@@ -19,23 +18,44 @@ import (
 
 // WebSocketService WebSocket服务
 type WebSocketService struct {
-	client      *Client
-	conn        *websocket.Conn
-	config      *WSConfig
-	isAuth      bool
-	isConnected bool
-	mutex       sync.RWMutex
-
-	// 事件处理器
-	onConnected    ConnectionHandler
-	onDisconnected ConnectionHandler
-	onError        ErrorHandler
-	onMessage      EventHandler
+	client       *Client
+	conn         *websocket.Conn
+	config       *WSConfig
+	isAuth       bool
+	isConnected  bool
+	closedByUser bool
+	forceRetry   bool
+	mutex        sync.RWMutex
+	writeMutex   sync.Mutex
+
+	// OnConnected、OnDisconnected、OnError和OnMessage设置的处理器在事件总线上的订阅
+	handlers     map[eventKind]*Subscription
+	handlerMutex sync.Mutex
+
+	// 消息回调队列，与读取协程解耦，Disconnect时关闭
+	queue atomic.Pointer[EventQueue]
+
+	// 数据流监控
+	watchdog atomic.Pointer[FeedWatchdog]
+
+	// 延迟统计，SetConfig时替换
+	telemetry atomic.Pointer[Telemetry]
+
+	// 行情录制
+	recorder atomic.Pointer[Recorder]
+
+	// 多订阅者事件总线
+	bus     atomic.Pointer[EventBus]
+	busOnce sync.Once
 
 	// 订阅管理
 	subscriptions map[string]bool
 	subMutex      sync.RWMutex
 
+	// 请求-响应管理
+	pending      map[string]chan *WebSocketMessage
+	pendingMutex sync.Mutex
+
 	// 控制通道
 	stopChan chan struct{}
 	done     chan struct{}
@@ -43,46 +63,89 @@ type WebSocketService struct {
 
 // NewWebSocketService 创建WebSocket服务
 func NewWebSocketService(client *Client) *WebSocketService {
-	return &WebSocketService{
+	config := DefaultWSConfig()
+	ws := &WebSocketService{
 		client:        client,
-		config:        DefaultWSConfig(),
+		config:        config,
+		handlers:      make(map[eventKind]*Subscription),
 		subscriptions: make(map[string]bool),
+		pending:       make(map[string]chan *WebSocketMessage),
 		stopChan:      make(chan struct{}),
 		done:          make(chan struct{}),
 	}
+	ws.telemetry.Store(NewTelemetry(config.LatencyWindow))
+	return ws
 }
 
 // SetConfig 设置WebSocket配置
 func (ws *WebSocketService) SetConfig(config *WSConfig) {
 	ws.config = config
+	ws.telemetry.Store(NewTelemetry(config.LatencyWindow))
 }
 
-// OnConnected 设置连接成功回调
+// OnConnected 设置连接成功回调，替换之前设置的回调，需要多个处理器时使用Bus().SubscribeConnected
 func (ws *WebSocketService) OnConnected(handler ConnectionHandler) {
-	ws.onConnected = handler
+	ws.setHandler(eventConnected, handler == nil, func(bus *EventBus) *Subscription {
+		return bus.SubscribeConnected(handler)
+	})
 }
 
-// OnDisconnected 设置断开连接回调
+// OnDisconnected 设置断开连接回调，替换之前设置的回调，需要多个处理器时使用Bus().SubscribeDisconnected
 func (ws *WebSocketService) OnDisconnected(handler ConnectionHandler) {
-	ws.onDisconnected = handler
+	ws.setHandler(eventDisconnected, handler == nil, func(bus *EventBus) *Subscription {
+		return bus.SubscribeDisconnected(handler)
+	})
 }
 
-// OnError 设置错误回调
+// OnError 设置错误回调，替换之前设置的回调，需要多个处理器时使用Bus().SubscribeErrors
 func (ws *WebSocketService) OnError(handler ErrorHandler) {
-	ws.onError = handler
+	ws.setHandler(eventError, handler == nil, func(bus *EventBus) *Subscription {
+		return bus.SubscribeErrors(handler)
+	})
 }
 
-// OnMessage 设置消息回调
+// OnMessage 设置消息回调，替换之前设置的回调，需要多个处理器时使用Bus().Subscribe
+// 回调中的panic被捕获并作为错误事件报告
 func (ws *WebSocketService) OnMessage(handler EventHandler) {
-	ws.onMessage = handler
+	ws.setHandler(eventMessage, handler == nil, func(bus *EventBus) *Subscription {
+		return bus.Subscribe("", handler)
+	})
+}
+
+// setHandler 以事件总线订阅替换之前设置的回调，remove为true时只移除
+func (ws *WebSocketService) setHandler(kind eventKind, remove bool, subscribe func(bus *EventBus) *Subscription) {
+	ws.handlerMutex.Lock()
+	defer ws.handlerMutex.Unlock()
+
+	if previous := ws.handlers[kind]; previous != nil {
+		previous.Unsubscribe()
+		delete(ws.handlers, kind)
+	}
+	if !remove {
+		ws.handlers[kind] = subscribe(ws.Bus())
+	}
+}
+
+// notify 向事件总线发布连接成功或断开连接事件
+func (ws *WebSocketService) notify(kind eventKind) {
+	if bus := ws.bus.Load(); bus != nil {
+		bus.publishConnection(kind)
+	}
+}
+
+// reportError 向事件总线发布错误事件
+func (ws *WebSocketService) reportError(err error) {
+	if bus := ws.bus.Load(); bus != nil {
+		bus.publishError(err)
+	}
 }
 
 // Connect 连接WebSocket
 func (ws *WebSocketService) Connect() error {
 	ws.mutex.Lock()
-	defer ws.mutex.Unlock()
 
 	if ws.isConnected {
+		ws.mutex.Unlock()
 		return fmt.Errorf("already connected")
 	}
 
@@ -91,23 +154,37 @@ func (ws *WebSocketService) Connect() error {
 
 	conn, _, err := dialer.Dial(ws.config.URL, nil)
 	if err != nil {
+		ws.mutex.Unlock()
 		return fmt.Errorf("websocket dial failed: %w", err)
 	}
 
 	ws.conn = conn
 	ws.isConnected = true
+	ws.closedByUser = false
+	ws.stopChan = make(chan struct{})
+	ws.done = make(chan struct{})
+
+	if ws.config.QueueSize > 0 && ws.queue.Load() == nil {
+		queue := NewEventQueue(ws.deliver, ws.config.QueueSize, ws.config.OverflowPolicy)
+		queue.OnPanic(ws.reportError)
+		ws.queue.Store(queue)
+	}
+
+	// 重连后各主题重新开始计时
+	if watchdog := ws.watchdog.Load(); watchdog != nil {
+		watchdog.reset()
+	}
 
 	// 启动消息处理协程
-	go ws.readMessages()
+	go ws.readMessages(conn, ws.stopChan, ws.done)
 
 	// 启动心跳协程
 	if ws.config.EnableHeartbeat {
-		go ws.heartbeat()
+		go ws.heartbeat(ws.stopChan)
 	}
+	ws.mutex.Unlock()
 
-	if ws.onConnected != nil {
-		ws.onConnected()
-	}
+	ws.notify(eventConnected)
 
 	return nil
 }
@@ -115,32 +192,174 @@ func (ws *WebSocketService) Connect() error {
 // Disconnect 断开连接
 func (ws *WebSocketService) Disconnect() error {
 	ws.mutex.Lock()
-	defer ws.mutex.Unlock()
+	ws.closedByUser = true
 
 	if !ws.isConnected {
+		ws.mutex.Unlock()
 		return nil
 	}
 
 	close(ws.stopChan)
-	<-ws.done
-
-	err := ws.conn.Close()
+	conn := ws.conn
+	done := ws.done
 	ws.conn = nil
 	ws.isConnected = false
 	ws.isAuth = false
+	ws.mutex.Unlock()
+
+	err := conn.Close()
+	<-done
 
 	// 清空订阅
 	ws.subMutex.Lock()
 	ws.subscriptions = make(map[string]bool)
 	ws.subMutex.Unlock()
 
-	if ws.onDisconnected != nil {
-		ws.onDisconnected()
+	// 结束等待中的请求
+	ws.failPending()
+
+	// 关闭消息回调队列，已排队的消息处理完后队列协程退出；
+	// 异步关闭以允许在消息回调中调用Disconnect
+	if queue := ws.queue.Swap(nil); queue != nil {
+		go queue.Close()
 	}
 
+	ws.notify(eventDisconnected)
+
 	return err
 }
 
+// connectionLost 处理连接异常断开，保留订阅以便重连后恢复
+func (ws *WebSocketService) connectionLost(conn *websocket.Conn) {
+	ws.mutex.Lock()
+	if ws.conn != conn {
+		ws.mutex.Unlock()
+		return
+	}
+	close(ws.stopChan)
+	ws.conn = nil
+	ws.isConnected = false
+	wasAuth := ws.isAuth
+	ws.isAuth = false
+	forceRetry := ws.forceRetry
+	ws.forceRetry = false
+	ws.mutex.Unlock()
+
+	conn.Close()
+	ws.failPending()
+
+	ws.notify(eventDisconnected)
+
+	if ws.config.AutoReconnect || forceRetry {
+		go ws.reconnect(wasAuth)
+	}
+}
+
+// Reconnect 关闭当前连接并重新连接，重连后恢复认证和订阅
+func (ws *WebSocketService) Reconnect() error {
+	ws.mutex.Lock()
+	if !ws.isConnected || ws.conn == nil {
+		ws.mutex.Unlock()
+		return fmt.Errorf("not connected")
+	}
+	ws.forceRetry = true
+	conn := ws.conn
+	ws.mutex.Unlock()
+
+	// 关闭底层连接，由读取协程触发重连流程
+	return conn.Close()
+}
+
+// Resubscribe 重新订阅指定主题
+func (ws *WebSocketService) Resubscribe(topic string) error {
+	ws.subMutex.RLock()
+	subscribed := ws.subscriptions[topic]
+	ws.subMutex.RUnlock()
+	if !subscribed {
+		return fmt.Errorf("not subscribed to %s", topic)
+	}
+
+	unsub := UnsubscribeRequest{
+		Unsub: topic,
+		ID:    fmt.Sprintf("unsub_%d", time.Now().UnixNano()),
+	}
+	if err := ws.sendMessage(unsub); err != nil {
+		return err
+	}
+
+	sub := SubscribeRequest{
+		Sub: topic,
+		ID:  fmt.Sprintf("sub_%d", time.Now().UnixNano()),
+	}
+	return ws.sendMessage(sub)
+}
+
+// reconnect 自动重连，成功后恢复认证和订阅
+func (ws *WebSocketService) reconnect(auth bool) {
+	interval := time.Duration(ws.config.ReconnectInterval) * time.Second
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+
+	for attempt := 1; ws.config.MaxReconnectAttempts <= 0 || attempt <= ws.config.MaxReconnectAttempts; attempt++ {
+		time.Sleep(interval)
+
+		ws.mutex.RLock()
+		closedByUser := ws.closedByUser
+		ws.mutex.RUnlock()
+		if closedByUser {
+			return
+		}
+
+		err := ws.Connect()
+		if err != nil {
+			if ws.IsConnected() {
+				return
+			}
+			ws.reportError(fmt.Errorf("reconnect attempt %d failed: %w", attempt, err))
+			continue
+		}
+
+		if auth {
+			if err := ws.Auth(); err != nil {
+				ws.reportError(fmt.Errorf("re-auth failed: %w", err))
+			}
+		}
+		if err := ws.resubscribe(); err != nil {
+			ws.reportError(fmt.Errorf("resubscribe failed: %w", err))
+		}
+		return
+	}
+
+	ws.reportError(fmt.Errorf("reconnect gave up after %d attempts", ws.config.MaxReconnectAttempts))
+}
+
+// resubscribe 重新发送当前所有订阅
+func (ws *WebSocketService) resubscribe() error {
+	for _, topic := range ws.Topics() {
+		req := SubscribeRequest{
+			Sub: topic,
+			ID:  fmt.Sprintf("sub_%d", time.Now().UnixNano()),
+		}
+		if err := ws.sendMessage(req); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Topics 获取当前订阅的主题列表
+func (ws *WebSocketService) Topics() []string {
+	ws.subMutex.RLock()
+	defer ws.subMutex.RUnlock()
+
+	topics := make([]string, 0, len(ws.subscriptions))
+	for topic := range ws.subscriptions {
+		topics = append(topics, topic)
+	}
+	return topics
+}
+
 // IsConnected 检查是否已连接
 func (ws *WebSocketService) IsConnected() bool {
 	ws.mutex.RLock()
@@ -290,84 +509,80 @@ func (ws *WebSocketService) sendMessage(message interface{}) error {
 		return fmt.Errorf("connection not available")
 	}
 
+	// gorilla/websocket不支持并发写
+	ws.writeMutex.Lock()
+	defer ws.writeMutex.Unlock()
+
 	return ws.conn.WriteMessage(websocket.TextMessage, data)
 }
 
 // readMessages 读取消息
-func (ws *WebSocketService) readMessages() {
+func (ws *WebSocketService) readMessages(conn *websocket.Conn, stopChan <-chan struct{}, done chan<- struct{}) {
 	defer func() {
-		close(ws.done)
+		ws.failPending()
+		close(done)
 	}()
 
+	// 读取缓冲由本协程独占，在帧之间复用
+	var frame bytes.Buffer
+
 	for {
 		select {
-		case <-ws.stopChan:
+		case <-stopChan:
 			return
 		default:
-			_, data, err := ws.conn.ReadMessage()
+			data, err := readFrame(conn, &frame)
 			if err != nil {
-				if ws.onError != nil {
-					ws.onError(fmt.Errorf("read message failed: %w", err))
+				// 主动断开时不再报告错误
+				select {
+				case <-stopChan:
+					return
+				default:
 				}
+				ws.reportError(fmt.Errorf("read message failed: %w", err))
+				go ws.connectionLost(conn)
 				return
 			}
 
-			// 解压gzip数据
-			if len(data) > 2 && data[0] == 0x1f && data[1] == 0x8b {
-				reader, err := gzip.NewReader(strings.NewReader(string(data)))
-				if err != nil {
-					if ws.onError != nil {
-						ws.onError(fmt.Errorf("gzip decompress failed: %w", err))
-					}
-					continue
-				}
-
-				decompressed, err := io.ReadAll(reader)
-				reader.Close()
-				if err != nil {
-					if ws.onError != nil {
-						ws.onError(fmt.Errorf("read decompressed data failed: %w", err))
-					}
-					continue
-				}
-				data = decompressed
-			}
+			now := time.Now()
+			ws.record(data, now)
 
 			var message WebSocketMessage
-			if err := json.Unmarshal(data, &message); err != nil {
-				if ws.onError != nil {
-					ws.onError(fmt.Errorf("unmarshal message failed: %w", err))
-				}
+			if err := decodeFrame(data, &message); err != nil {
+				ws.reportError(err)
 				continue
 			}
 
-			ws.handleMessage(&message)
+			ws.handleMessage(&message, now)
 		}
 	}
 }
 
-// handleMessage 处理消息
-func (ws *WebSocketService) handleMessage(message *WebSocketMessage) {
+// handleMessage 处理消息，now为数据帧接收时间
+func (ws *WebSocketService) handleMessage(message *WebSocketMessage, now time.Time) {
 	// 处理ping消息
 	if message.Ping > 0 {
+		ws.telemetry.Load().recordServerTime(message.Ping, now)
 		pong := map[string]int64{"pong": message.Ping}
 		if err := ws.sendMessage(pong); err != nil {
-			if ws.onError != nil {
-				ws.onError(fmt.Errorf("send pong failed: %w", err))
-			}
+			ws.reportError(fmt.Errorf("send pong failed: %w", err))
 		}
 		return
 	}
 
+	// 处理心跳pong响应
+	if message.Pong > 0 {
+		ws.telemetry.Load().recordPong(message.Pong, now)
+		return
+	}
+
 	// 处理认证响应
 	if message.Op == "auth" {
 		if message.ErrCode == 0 {
 			ws.isAuth = true
 			log.Println("WebSocket authentication successful")
 		} else {
-			if ws.onError != nil {
-				ws.onError(fmt.Errorf("authentication failed: %s", message.ErrMsg))
-			}
+			ws.reportError(fmt.Errorf("authentication failed: %s", message.ErrMsg))
 		}
 		return
 	}
@@ -384,27 +599,56 @@ func (ws *WebSocketService) handleMessage(message *WebSocketMessage) {
 		return
 	}
 
-	// 回调用户处理器
-	if ws.onMessage != nil {
-		ws.onMessage(message)
+	// 处理req请求的响应
+	if ws.deliverResponse(message) {
+		return
+	}
+
+	// 记录消息延迟
+	ws.telemetry.Load().recordMessage(message.Ch, message.Ts, now)
+
+	// 记录主题存活状态
+	if watchdog := ws.watchdog.Load(); watchdog != nil {
+		watchdog.observe(message)
+	}
+
+	// 回调用户处理器，启用队列时由队列协程异步回调
+	if queue := ws.queue.Load(); queue != nil {
+		queue.Push(message)
+		return
 	}
+	ws.deliver(message)
+}
+
+// deliver 将消息发布到事件总线，OnMessage设置的回调也是总线订阅者
+func (ws *WebSocketService) deliver(message *WebSocketMessage) {
+	if bus := ws.bus.Load(); bus != nil {
+		bus.Publish(message)
+	}
+}
+
+// QueueStats 获取消息回调队列统计，未启用队列时返回零值
+func (ws *WebSocketService) QueueStats() QueueStats {
+	queue := ws.queue.Load()
+	if queue == nil {
+		return QueueStats{}
+	}
+	return queue.Stats()
 }
 
 // heartbeat 心跳
-func (ws *WebSocketService) heartbeat() {
+func (ws *WebSocketService) heartbeat(stopChan <-chan struct{}) {
 	ticker := time.NewTicker(time.Duration(ws.config.HeartbeatInterval) * time.Second)
 	defer ticker.Stop()
 
 	for {
 		select {
-		case <-ws.stopChan:
+		case <-stopChan:
 			return
 		case <-ticker.C:
 			ping := map[string]int64{"ping": time.Now().UnixMilli()}
 			if err := ws.sendMessage(ping); err != nil {
-				if ws.onError != nil {
-					ws.onError(fmt.Errorf("send ping failed: %w", err))
-				}
+				ws.reportError(fmt.Errorf("send ping failed: %w", err))
 				return
 			}
 		}
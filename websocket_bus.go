@@ -0,0 +1,303 @@
+package hotcoin
+
+import (
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// EventBus WebSocket消息事件总线，支持任意数量的订阅者按主题模式订阅
+// 主题模式按"."分段匹配："*"匹配一个分段，末尾的"#"匹配剩余任意分段，
+// 例如"market.*.trade.detail"、"orders.*"、"market.btcusdt.#"
+// 连接成功、断开连接和错误事件同样可以有多个订阅者；
+// 订阅者处理器中的panic会被捕获并通过OnPanic报告，不影响其他订阅者
+type EventBus struct {
+	mutex       sync.Mutex
+	subscribers atomic.Value // []*busSubscriber，写时复制
+	nextID      uint64
+
+	onPanic ErrorHandler
+}
+
+// eventKind 订阅的事件类型
+type eventKind int
+
+const (
+	eventMessage      eventKind = iota // 消息
+	eventConnected                     // 连接成功
+	eventDisconnected                  // 断开连接
+	eventError                         // 错误
+)
+
+// String 事件类型名称，用于panic报告
+func (k eventKind) String() string {
+	switch k {
+	case eventConnected:
+		return "connected"
+	case eventDisconnected:
+		return "disconnected"
+	case eventError:
+		return "error"
+	}
+	return "message"
+}
+
+// busSubscriber 事件总线订阅者
+type busSubscriber struct {
+	id       uint64
+	kind     eventKind
+	pattern  string
+	segments []string
+	handler  EventHandler
+	queue    *EventQueue
+
+	onConnection ConnectionHandler
+	onError      ErrorHandler
+}
+
+// Subscription 事件总线订阅句柄
+type Subscription struct {
+	bus     *EventBus
+	id      uint64
+	pattern string
+	queue   *EventQueue
+}
+
+// HandlerPanicError 订阅者处理器panic错误
+type HandlerPanicError struct {
+	Pattern string      // 订阅者的主题模式
+	Topic   string      // 触发panic的消息主题
+	Value   interface{} // panic值
+	Stack   []byte      // 调用栈
+}
+
+func (e *HandlerPanicError) Error() string {
+	if e.Pattern == "" {
+		return fmt.Sprintf("handler panicked on %s: %v", e.Topic, e.Value)
+	}
+	return fmt.Sprintf("handler for %s panicked on %s: %v", e.Pattern, e.Topic, e.Value)
+}
+
+// NewEventBus 创建事件总线
+func NewEventBus() *EventBus {
+	bus := &EventBus{}
+	bus.subscribers.Store([]*busSubscriber(nil))
+	return bus
+}
+
+// Bus 获取WebSocket服务的事件总线，首次调用时创建
+// 未设置OnPanic时，处理器panic作为错误事件发布
+func (ws *WebSocketService) Bus() *EventBus {
+	ws.busOnce.Do(func() {
+		bus := NewEventBus()
+		bus.OnPanic(bus.publishError)
+		ws.bus.Store(bus)
+	})
+	return ws.bus.Load()
+}
+
+// OnPanic 设置处理器panic回调
+func (b *EventBus) OnPanic(handler ErrorHandler) {
+	b.onPanic = handler
+}
+
+// Subscribe 按主题模式订阅，处理器在发布协程中同步调用
+func (b *EventBus) Subscribe(pattern string, handler EventHandler) *Subscription {
+	return b.add(&busSubscriber{pattern: pattern, handler: handler})
+}
+
+// SubscribeQueued 按主题模式订阅，处理器在独立的有界队列中异步调用
+func (b *EventBus) SubscribeQueued(pattern string, handler EventHandler, size int, policy OverflowPolicy) *Subscription {
+	owner := &busSubscriber{pattern: pattern}
+	queue := NewEventQueue(func(message *WebSocketMessage) {
+		b.invoke(owner, handler, message)
+	}, size, policy)
+	return b.add(&busSubscriber{pattern: pattern, handler: handler, queue: queue})
+}
+
+// SubscribeConnected 订阅连接成功事件，包括自动重连成功
+func (b *EventBus) SubscribeConnected(handler ConnectionHandler) *Subscription {
+	return b.add(&busSubscriber{kind: eventConnected, onConnection: handler})
+}
+
+// SubscribeDisconnected 订阅断开连接事件
+func (b *EventBus) SubscribeDisconnected(handler ConnectionHandler) *Subscription {
+	return b.add(&busSubscriber{kind: eventDisconnected, onConnection: handler})
+}
+
+// SubscribeErrors 订阅错误事件，包括其他订阅者处理器的panic
+func (b *EventBus) SubscribeErrors(handler ErrorHandler) *Subscription {
+	return b.add(&busSubscriber{kind: eventError, onError: handler})
+}
+
+// Publish 将消息分发给所有匹配的订阅者
+func (b *EventBus) Publish(message *WebSocketMessage) {
+	subscribers := b.subscribers.Load().([]*busSubscriber)
+	if len(subscribers) == 0 {
+		return
+	}
+
+	topic := splitTopic(message.Ch)
+	for _, sub := range subscribers {
+		if sub.kind != eventMessage || !matchTopic(sub.segments, topic) {
+			continue
+		}
+		if sub.queue != nil {
+			sub.queue.Push(message)
+			continue
+		}
+		b.invoke(sub, sub.handler, message)
+	}
+}
+
+// publishConnection 通知连接成功或断开连接的订阅者
+func (b *EventBus) publishConnection(kind eventKind) {
+	for _, sub := range b.subscribers.Load().([]*busSubscriber) {
+		if sub.kind == kind {
+			b.invokeEvent(sub, nil, sub.onConnection)
+		}
+	}
+}
+
+// publishError 通知错误订阅者
+func (b *EventBus) publishError(err error) {
+	for _, sub := range b.subscribers.Load().([]*busSubscriber) {
+		if sub.kind == eventError {
+			b.invokeEvent(sub, err, func() { sub.onError(err) })
+		}
+	}
+}
+
+// Len 获取订阅者数量
+func (b *EventBus) Len() int {
+	return len(b.subscribers.Load().([]*busSubscriber))
+}
+
+// Pattern 获取订阅的主题模式
+func (s *Subscription) Pattern() string {
+	return s.pattern
+}
+
+// Stats 获取异步订阅的队列统计，同步订阅返回零值
+func (s *Subscription) Stats() QueueStats {
+	if s.queue == nil {
+		return QueueStats{}
+	}
+	return s.queue.Stats()
+}
+
+// Unsubscribe 取消订阅，可重复调用
+func (s *Subscription) Unsubscribe() {
+	if !s.bus.remove(s.id) {
+		return
+	}
+	if s.queue != nil {
+		// 异步关闭，允许在处理器内部取消订阅
+		go s.queue.Close()
+	}
+}
+
+// add 添加订阅者
+func (b *EventBus) add(sub *busSubscriber) *Subscription {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.nextID++
+	sub.id = b.nextID
+	sub.segments = splitTopic(sub.pattern)
+
+	current := b.subscribers.Load().([]*busSubscriber)
+	next := make([]*busSubscriber, len(current), len(current)+1)
+	copy(next, current)
+	b.subscribers.Store(append(next, sub))
+
+	return &Subscription{bus: b, id: sub.id, pattern: sub.pattern, queue: sub.queue}
+}
+
+// remove 移除订阅者，返回是否存在
+func (b *EventBus) remove(id uint64) bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	current := b.subscribers.Load().([]*busSubscriber)
+	for i, sub := range current {
+		if sub.id != id {
+			continue
+		}
+		next := make([]*busSubscriber, 0, len(current)-1)
+		next = append(next, current[:i]...)
+		next = append(next, current[i+1:]...)
+		b.subscribers.Store(next)
+		return true
+	}
+	return false
+}
+
+// invoke 调用处理器并捕获panic
+func (b *EventBus) invoke(sub *busSubscriber, handler EventHandler, message *WebSocketMessage) {
+	defer func() {
+		if r := recover(); r != nil && b.onPanic != nil {
+			b.onPanic(&HandlerPanicError{
+				Pattern: sub.pattern,
+				Topic:   message.Ch,
+				Value:   r,
+				Stack:   debug.Stack(),
+			})
+		}
+	}()
+
+	handler(message)
+}
+
+// invokeEvent 调用连接或错误事件处理器并捕获panic
+// 处理的错误本身是panic报告时不再报告错误订阅者的panic，避免递归
+func (b *EventBus) invokeEvent(sub *busSubscriber, cause error, handler func()) {
+	defer func() {
+		r := recover()
+		if r == nil || b.onPanic == nil {
+			return
+		}
+		var nested *HandlerPanicError
+		if errors.As(cause, &nested) {
+			return
+		}
+		b.onPanic(&HandlerPanicError{
+			Topic: sub.kind.String(),
+			Value: r,
+			Stack: debug.Stack(),
+		})
+	}()
+
+	handler()
+}
+
+// splitTopic 将主题按"."分段
+func splitTopic(topic string) []string {
+	if topic == "" {
+		return nil
+	}
+	return strings.Split(topic, ".")
+}
+
+// matchTopic 判断主题是否匹配模式，空模式匹配所有主题
+func matchTopic(pattern, topic []string) bool {
+	if len(pattern) == 0 {
+		return true
+	}
+
+	for i, segment := range pattern {
+		if segment == "#" && i == len(pattern)-1 {
+			return true
+		}
+		if i >= len(topic) {
+			return false
+		}
+		if segment != "*" && segment != topic[i] {
+			return false
+		}
+	}
+	return len(pattern) == len(topic)
+}
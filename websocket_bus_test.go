@@ -0,0 +1,161 @@
+package hotcoin
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMatchTopic(t *testing.T) {
+	tests := []struct {
+		pattern string
+		topic   string
+		want    bool
+	}{
+		{"market.*.trade.detail", "market.btcusdt.trade.detail", true},
+		{"market.*.trade.detail", "market.btcusdt.depth.step0", false},
+		{"orders.*", "orders.btcusdt", true},
+		{"orders.*", "orders", false},
+		{"orders.*", "orders.btcusdt.extra", false},
+		{"market.btcusdt.#", "market.btcusdt.kline.1min", true},
+		{"market.btcusdt.#", "market.ethusdt.kline.1min", false},
+		{"", "accounts.usdt", true},
+		{"market.btcusdt.detail", "market.btcusdt.detail", true},
+	}
+
+	for _, tt := range tests {
+		got := matchTopic(splitTopic(tt.pattern), splitTopic(tt.topic))
+		if got != tt.want {
+			t.Errorf("matchTopic(%q, %q) = %v, want %v", tt.pattern, tt.topic, got, tt.want)
+		}
+	}
+}
+
+func TestEventBusPanicIsolation(t *testing.T) {
+	bus := NewEventBus()
+
+	var panicErr error
+	bus.OnPanic(func(err error) {
+		panicErr = err
+	})
+
+	received := 0
+	bus.Subscribe("orders.*", func(message *WebSocketMessage) {
+		panic("boom")
+	})
+	sub := bus.Subscribe("orders.*", func(message *WebSocketMessage) {
+		received++
+	})
+
+	bus.Publish(&WebSocketMessage{Ch: "orders.btcusdt"})
+	if received != 1 {
+		t.Errorf("expected 1 message, got %d", received)
+	}
+
+	var handlerPanic *HandlerPanicError
+	if !errors.As(panicErr, &handlerPanic) || handlerPanic.Value != "boom" {
+		t.Errorf("expected HandlerPanicError, got %v", panicErr)
+	}
+
+	sub.Unsubscribe()
+	sub.Unsubscribe()
+	bus.Publish(&WebSocketMessage{Ch: "orders.btcusdt"})
+	if received != 1 {
+		t.Errorf("unsubscribed handler should not be called, got %d", received)
+	}
+	if bus.Len() != 1 {
+		t.Errorf("expected 1 subscriber, got %d", bus.Len())
+	}
+}
+
+func TestEventBusErrorSubscribers(t *testing.T) {
+	bus := NewEventBus()
+	bus.OnPanic(bus.publishError)
+
+	var errs []error
+	bus.SubscribeErrors(func(err error) { panic("error handler") })
+	bus.SubscribeErrors(func(err error) { errs = append(errs, err) })
+	connected := 0
+	bus.SubscribeConnected(func() { panic("connected handler") })
+	bus.SubscribeConnected(func() { connected++ })
+
+	// 错误订阅者的panic只报告一次，不会递归
+	bus.publishError(errors.New("read failed"))
+	bus.publishConnection(eventConnected)
+	bus.publishConnection(eventDisconnected)
+
+	if connected != 1 {
+		t.Errorf("connected = %d", connected)
+	}
+	var got []string
+	for _, err := range errs {
+		got = append(got, err.Error())
+	}
+	want := []string{
+		"handler panicked on error: error handler",
+		"read failed",
+		"handler panicked on connected: connected handler",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("errors = %q, want %q", got, want)
+	}
+}
+
+func TestWebSocketEventsThroughBus(t *testing.T) {
+	server := newTestWSServer(t, nil)
+	ws := NewClient("", "").WebSocket
+	ws.SetConfig(testWSConfig(server))
+
+	var mutex sync.Mutex
+	events := make(map[string]int)
+	count := func(name string) func() {
+		return func() {
+			mutex.Lock()
+			events[name]++
+			mutex.Unlock()
+		}
+	}
+	ws.OnConnected(count("replaced"))
+	ws.OnConnected(count("legacy-connected"))
+	ws.Bus().SubscribeConnected(count("connected"))
+	ws.OnDisconnected(count("legacy-disconnected"))
+	ws.Bus().SubscribeDisconnected(count("disconnected"))
+
+	var errs []error
+	ws.OnError(func(err error) {
+		mutex.Lock()
+		errs = append(errs, err)
+		mutex.Unlock()
+	})
+	ws.Bus().SubscribeErrors(func(err error) { count("error")() })
+
+	// OnMessage回调的panic被捕获，不影响其他订阅者
+	ws.OnMessage(func(message *WebSocketMessage) { panic("legacy handler") })
+	received := make(chan struct{}, 1)
+	ws.Bus().Subscribe("market.*.detail", func(message *WebSocketMessage) { received <- struct{}{} })
+
+	if err := ws.Connect(); err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	waitFor(t, time.Second, func() bool { return len(server.connections()) == 1 })
+	server.broadcast(map[string]interface{}{"ch": "market.btcusdt.detail", "ts": 1})
+	select {
+	case <-received:
+	case <-time.After(time.Second):
+		t.Fatal("bus subscriber did not receive message")
+	}
+	ws.Disconnect()
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	want := map[string]int{"legacy-connected": 1, "connected": 1, "legacy-disconnected": 1, "disconnected": 1, "error": 1}
+	if !reflect.DeepEqual(events, want) {
+		t.Errorf("events = %v, want %v", events, want)
+	}
+	var handlerPanic *HandlerPanicError
+	if len(errs) != 1 || !errors.As(errs[0], &handlerPanic) || handlerPanic.Value != "legacy handler" {
+		t.Errorf("unexpected errors: %v", errs)
+	}
+}
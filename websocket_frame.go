@@ -0,0 +1,95 @@
+package hotcoin
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/gorilla/websocket"
+)
+
+// maxPooledBufferSize 放回缓冲池的最大缓冲大小，避免偶发大消息长期占用内存
+const maxPooledBufferSize = 1 << 20
+
+// gzipDecoder 可复用的gzip解压器
+type gzipDecoder struct {
+	source bytes.Reader
+	reader *gzip.Reader
+}
+
+var (
+	gzipDecoderPool = sync.Pool{
+		New: func() interface{} { return new(gzipDecoder) },
+	}
+	frameBufferPool = sync.Pool{
+		New: func() interface{} { return new(bytes.Buffer) },
+	}
+)
+
+// readFrame 读取一个数据帧到buf，返回的数据在下次读取前有效
+func readFrame(conn *websocket.Conn, buf *bytes.Buffer) ([]byte, error) {
+	_, reader, err := conn.NextReader()
+	if err != nil {
+		return nil, err
+	}
+
+	buf.Reset()
+	if _, err := buf.ReadFrom(reader); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// isGzipFrame 判断是否为gzip压缩数据
+func isGzipFrame(data []byte) bool {
+	return len(data) > 2 && data[0] == 0x1f && data[1] == 0x8b
+}
+
+// decodeFrame 解码WebSocket数据帧，gzip帧使用池化的解压器和缓冲
+// Tick和Data保留为原始JSON，按需解析
+func decodeFrame(data []byte, message *WebSocketMessage) error {
+	if isGzipFrame(data) {
+		buf := frameBufferPool.Get().(*bytes.Buffer)
+		buf.Reset()
+		defer func() {
+			if buf.Cap() <= maxPooledBufferSize {
+				frameBufferPool.Put(buf)
+			}
+		}()
+
+		if err := gunzipFrame(buf, data); err != nil {
+			return err
+		}
+		data = buf.Bytes()
+	}
+
+	// json.RawMessage会复制数据，解析完成后缓冲可以安全复用
+	if err := json.Unmarshal(data, message); err != nil {
+		return fmt.Errorf("unmarshal message failed: %w", err)
+	}
+	return nil
+}
+
+// gunzipFrame 将gzip数据解压到dst
+func gunzipFrame(dst *bytes.Buffer, data []byte) error {
+	decoder := gzipDecoderPool.Get().(*gzipDecoder)
+	defer gzipDecoderPool.Put(decoder)
+
+	decoder.source.Reset(data)
+	if decoder.reader == nil {
+		reader, err := gzip.NewReader(&decoder.source)
+		if err != nil {
+			return fmt.Errorf("gzip decompress failed: %w", err)
+		}
+		decoder.reader = reader
+	} else if err := decoder.reader.Reset(&decoder.source); err != nil {
+		return fmt.Errorf("gzip decompress failed: %w", err)
+	}
+
+	if _, err := dst.ReadFrom(decoder.reader); err != nil {
+		return fmt.Errorf("read decompressed data failed: %w", err)
+	}
+	return decoder.reader.Close()
+}
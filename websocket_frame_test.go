@@ -0,0 +1,130 @@
+package hotcoin
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+)
+
+// recordedFrames 录制的行情推送样本
+var recordedFrames = []string{
+	`{"ch":"market.btcusdt.depth.step0","ts":1700000000123,"tick":{"bids":[["37000.1","12"],["37000.0","5"],["36999.5","40"],["36999.0","7"],["36998.2","15"]],"asks":[["37000.5","3"],["37001.0","9"],["37001.8","22"],["37002.0","1"],["37003.3","60"]],"version":1024,"ts":1700000000120}}`,
+	`{"ch":"market.btcusdt.trade.detail","ts":1700000000456,"tick":{"id":889911,"ts":1700000000450,"data":[{"amount":"2","ts":1700000000450,"id":5550001,"price":"37000.5","direction":"buy"},{"amount":"1","ts":1700000000451,"id":5550002,"price":"37000.4","direction":"sell"}]}}`,
+	`{"ch":"market.btcusdt.kline.1min","ts":1700000000789,"tick":{"id":1699999980,"open":"36990.0","close":"37000.5","high":"37005.0","low":"36985.5","amount":"1520","vol":"56231450.5","count":311}}`,
+	`{"ping":1700000001000}`,
+}
+
+// gzipFrames 将样本压缩为gzip帧
+func gzipFrames(t testing.TB) [][]byte {
+	frames := make([][]byte, 0, len(recordedFrames))
+	for _, frame := range recordedFrames {
+		var buf bytes.Buffer
+		writer := gzip.NewWriter(&buf)
+		if _, err := writer.Write([]byte(frame)); err != nil {
+			t.Fatal(err)
+		}
+		if err := writer.Close(); err != nil {
+			t.Fatal(err)
+		}
+		frames = append(frames, buf.Bytes())
+	}
+	return frames
+}
+
+func TestDecodeFrame(t *testing.T) {
+	frames := gzipFrames(t)
+
+	// 解码两遍，确保复用的解压器和缓冲不会污染已解码的消息
+	var messages []*WebSocketMessage
+	for i := 0; i < 2; i++ {
+		for _, frame := range frames {
+			var message WebSocketMessage
+			if err := decodeFrame(frame, &message); err != nil {
+				t.Fatalf("decode frame: %v", err)
+			}
+			messages = append(messages, &message)
+		}
+	}
+
+	depth := messages[0]
+	if depth.Ch != "market.btcusdt.depth.step0" || depth.Ts != 1700000000123 {
+		t.Errorf("unexpected depth message: %s %d", depth.Ch, depth.Ts)
+	}
+	var depthData WSDepthData
+	if err := depth.DecodeTick(&depthData); err != nil {
+		t.Fatalf("decode depth tick: %v", err)
+	}
+	if depthData.Version != 1024 || len(depthData.Bids) != 5 || depthData.Asks[0][0] != "37000.5" {
+		t.Errorf("unexpected depth data: %+v", depthData)
+	}
+
+	var trade WSTradeData
+	if err := messages[1].DecodeTick(&trade); err != nil {
+		t.Fatalf("decode trade tick: %v", err)
+	}
+	if len(trade.Data) != 2 || trade.Data[1].ID != 5550002 {
+		t.Errorf("unexpected trade data: %+v", trade)
+	}
+
+	if messages[3].Ping != 1700000001000 {
+		t.Errorf("expected ping, got %+v", messages[3])
+	}
+	if err := messages[3].DecodeTick(&depthData); err == nil {
+		t.Error("decode empty tick should return error")
+	}
+
+	// 未压缩的文本帧
+	var plain WebSocketMessage
+	if err := decodeFrame([]byte(recordedFrames[2]), &plain); err != nil {
+		t.Fatalf("decode plain frame: %v", err)
+	}
+	if plain.Ch != "market.btcusdt.kline.1min" {
+		t.Errorf("unexpected plain message: %s", plain.Ch)
+	}
+}
+
+func BenchmarkDecodeFrame(b *testing.B) {
+	frames := gzipFrames(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		var message WebSocketMessage
+		if err := decodeFrame(frames[i%len(frames)], &message); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkDecodeFrameLegacy 原实现：字符串转换、每帧新建gzip.Reader、解析为interface{}
+func BenchmarkDecodeFrameLegacy(b *testing.B) {
+	frames := gzipFrames(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		data := frames[i%len(frames)]
+		reader, err := gzip.NewReader(strings.NewReader(string(data)))
+		if err != nil {
+			b.Fatal(err)
+		}
+		decompressed, err := io.ReadAll(reader)
+		reader.Close()
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		var message struct {
+			Ch   string      `json:"ch"`
+			Ts   int64       `json:"ts"`
+			Ping int64       `json:"ping"`
+			Tick interface{} `json:"tick"`
+		}
+		if err := json.Unmarshal(decompressed, &message); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
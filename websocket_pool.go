@@ -0,0 +1,466 @@
+package hotcoin
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// errTopicReleased 等待重新分配的主题已被取消订阅
+var errTopicReleased = errors.New("topic unsubscribed")
+
+// WebSocketPool WebSocket连接池，将大量订阅主题分散到多个连接
+// 每个主题只分配到一个连接，所有连接的消息合并到同一个事件通道，Close后事件通道关闭；
+// 同一主题中时间戳倒退的消息会被计数，启用DropOutOfOrder时丢弃
+type WebSocketPool struct {
+	client *Client
+	config *WSPoolConfig
+
+	mutex   sync.Mutex
+	shards  []*poolShard
+	topics  map[string]*poolShard
+	orphans map[string]bool // 连接断开后等待重新分配的主题
+	closed  bool
+
+	// 新建连接串行执行，拨号期间不持有mutex
+	dialMutex sync.Mutex
+
+	// 按主题记录最后投递的消息时间戳
+	lastTs     map[string]int64
+	tsMutex    sync.Mutex
+	outOfOrder uint64
+
+	events   chan *WebSocketMessage
+	queue    *EventQueue
+	stopChan chan struct{}
+
+	// 保护事件通道的关闭，写入时持有读锁
+	sendMutex    sync.RWMutex
+	eventsClosed bool
+
+	onError ErrorHandler
+}
+
+// poolShard 连接池中的单个连接
+type poolShard struct {
+	ws     *WebSocketService
+	topics map[string]bool
+}
+
+// NewWebSocketPool 创建WebSocket连接池
+func NewWebSocketPool(client *Client, config *WSPoolConfig) *WebSocketPool {
+	if config == nil {
+		config = DefaultWSPoolConfig()
+	}
+	if config.WSConfig == nil {
+		config.WSConfig = DefaultWSConfig()
+	}
+	if config.TopicsPerConnection <= 0 {
+		config.TopicsPerConnection = DefaultWSPoolConfig().TopicsPerConnection
+	}
+	if config.EventBufferSize <= 0 {
+		config.EventBufferSize = DefaultWSPoolConfig().EventBufferSize
+	}
+
+	p := &WebSocketPool{
+		client:   client,
+		config:   config,
+		topics:   make(map[string]*poolShard),
+		orphans:  make(map[string]bool),
+		lastTs:   make(map[string]int64),
+		events:   make(chan *WebSocketMessage, config.EventBufferSize),
+		stopChan: make(chan struct{}),
+	}
+	if config.QueueSize > 0 {
+		p.queue = NewEventQueue(p.forward, config.QueueSize, config.OverflowPolicy)
+	}
+
+	return p
+}
+
+// OnError 设置错误回调
+func (p *WebSocketPool) OnError(handler ErrorHandler) {
+	p.onError = handler
+}
+
+// Events 获取合并后的事件通道，Close后通道关闭
+func (p *WebSocketPool) Events() <-chan *WebSocketMessage {
+	return p.events
+}
+
+// Subscribe 订阅主题，自动选择负载最小的连接，连接已满时新建连接
+func (p *WebSocketPool) Subscribe(topic string) error {
+	return p.subscribe(topic, false)
+}
+
+// subscribe 订阅主题，orphan为true时重新分配等待中的主题，主题已被取消订阅时返回errTopicReleased
+func (p *WebSocketPool) subscribe(topic string, orphan bool) error {
+	p.mutex.Lock()
+	if err := p.checkLocked(topic, orphan); err != nil {
+		p.mutex.Unlock()
+		return err
+	}
+	if target := p.pick(); target != nil {
+		defer p.mutex.Unlock()
+		return p.attach(target, topic)
+	}
+	p.mutex.Unlock()
+
+	return p.subscribeNew(topic, orphan)
+}
+
+// SubscribeAll 批量订阅主题
+func (p *WebSocketPool) SubscribeAll(topics []string) error {
+	for _, topic := range topics {
+		if err := p.Subscribe(topic); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Unsubscribe 取消订阅主题，连接上没有其他主题时关闭该连接，等待重新分配的主题不再重新订阅
+func (p *WebSocketPool) Unsubscribe(topic string) error {
+	p.mutex.Lock()
+	if p.orphans[topic] {
+		delete(p.orphans, topic)
+		p.mutex.Unlock()
+		p.forgetTs(topic)
+		return nil
+	}
+	shard, ok := p.topics[topic]
+	if !ok {
+		p.mutex.Unlock()
+		return nil
+	}
+	delete(p.topics, topic)
+	delete(shard.topics, topic)
+	empty := len(shard.topics) == 0
+	if empty {
+		p.removeShard(shard)
+	}
+	p.mutex.Unlock()
+
+	p.forgetTs(topic)
+	if empty {
+		return shard.ws.Disconnect()
+	}
+	return shard.ws.Unsubscribe(topic)
+}
+
+// Topics 获取所有已订阅的主题，包括等待重新分配的主题
+func (p *WebSocketPool) Topics() []string {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	topics := make([]string, 0, len(p.topics)+len(p.orphans))
+	for topic := range p.topics {
+		topics = append(topics, topic)
+	}
+	for topic := range p.orphans {
+		topics = append(topics, topic)
+	}
+	sort.Strings(topics)
+	return topics
+}
+
+// OutOfOrder 获取同一主题中时间戳倒退的消息数量
+func (p *WebSocketPool) OutOfOrder() uint64 {
+	return atomic.LoadUint64(&p.outOfOrder)
+}
+
+// QueueStats 获取合并事件队列统计，未启用队列时返回零值
+func (p *WebSocketPool) QueueStats() QueueStats {
+	if p.queue == nil {
+		return QueueStats{}
+	}
+	return p.queue.Stats()
+}
+
+// ConnectionCount 获取当前连接数
+func (p *WebSocketPool) ConnectionCount() int {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return len(p.shards)
+}
+
+// Distribution 获取每个连接上的主题数量
+func (p *WebSocketPool) Distribution() []int {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	counts := make([]int, len(p.shards))
+	for i, shard := range p.shards {
+		counts[i] = len(shard.topics)
+	}
+	return counts
+}
+
+// Close 关闭所有连接和事件通道
+func (p *WebSocketPool) Close() error {
+	p.mutex.Lock()
+	if p.closed {
+		p.mutex.Unlock()
+		return nil
+	}
+	p.closed = true
+	shards := p.shards
+	p.shards = nil
+	p.topics = make(map[string]*poolShard)
+	p.orphans = make(map[string]bool)
+	close(p.stopChan)
+	p.mutex.Unlock()
+
+	var firstErr error
+	for _, shard := range shards {
+		if err := shard.ws.Disconnect(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	if p.queue != nil {
+		p.queue.Close()
+	}
+
+	// stopChan已关闭，阻塞中的写入会立即返回
+	p.sendMutex.Lock()
+	p.eventsClosed = true
+	close(p.events)
+	p.sendMutex.Unlock()
+	return firstErr
+}
+
+// checkLocked 校验主题能否订阅，调用方需持有mutex
+func (p *WebSocketPool) checkLocked(topic string, orphan bool) error {
+	if p.closed {
+		return fmt.Errorf("pool closed")
+	}
+	if _, ok := p.topics[topic]; ok {
+		return fmt.Errorf("already subscribed to %s", topic)
+	}
+	if p.orphans[topic] != orphan {
+		if orphan {
+			return errTopicReleased
+		}
+		return fmt.Errorf("already subscribed to %s", topic)
+	}
+	return nil
+}
+
+// pick 选择未满且负载最小的连接，没有可用连接时返回nil，调用方需持有mutex
+func (p *WebSocketPool) pick() *poolShard {
+	var target *poolShard
+	for _, shard := range p.shards {
+		if len(shard.topics) >= p.config.TopicsPerConnection {
+			continue
+		}
+		if target == nil || len(shard.topics) < len(target.topics) {
+			target = shard
+		}
+	}
+	return target
+}
+
+// attach 在连接上订阅主题，调用方需持有mutex
+func (p *WebSocketPool) attach(shard *poolShard, topic string) error {
+	if err := shard.ws.Subscribe(topic); err != nil {
+		return err
+	}
+	shard.topics[topic] = true
+	p.topics[topic] = shard
+	delete(p.orphans, topic)
+	return nil
+}
+
+// subscribeNew 新建连接并订阅主题，拨号时不持有mutex，不阻塞连接池的其他调用
+func (p *WebSocketPool) subscribeNew(topic string, orphan bool) error {
+	p.dialMutex.Lock()
+	defer p.dialMutex.Unlock()
+
+	// 等待拨号期间其他调用可能已经新建了连接
+	p.mutex.Lock()
+	if err := p.checkLocked(topic, orphan); err != nil {
+		p.mutex.Unlock()
+		return err
+	}
+	if target := p.pick(); target != nil {
+		defer p.mutex.Unlock()
+		return p.attach(target, topic)
+	}
+	if p.config.MaxConnections > 0 && len(p.shards) >= p.config.MaxConnections {
+		p.mutex.Unlock()
+		return fmt.Errorf("connection limit %d reached", p.config.MaxConnections)
+	}
+	p.mutex.Unlock()
+
+	shard, err := p.newShard()
+	if err != nil {
+		return err
+	}
+
+	p.mutex.Lock()
+	if p.closed {
+		p.mutex.Unlock()
+		shard.ws.Disconnect()
+		return fmt.Errorf("pool closed")
+	}
+	p.shards = append(p.shards, shard)
+	defer p.mutex.Unlock()
+	if err := p.checkLocked(topic, orphan); err != nil {
+		return err
+	}
+	return p.attach(shard, topic)
+}
+
+// removeShard 从连接池移除连接，调用方需持有mutex
+func (p *WebSocketPool) removeShard(target *poolShard) bool {
+	for i, shard := range p.shards {
+		if shard == target {
+			p.shards = append(p.shards[:i], p.shards[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// newShard 新建并连接一个连接，不加入连接池
+func (p *WebSocketPool) newShard() (*poolShard, error) {
+	wsConfig := *p.config.WSConfig
+	// 重连由连接池负责，以便重新分配主题
+	wsConfig.AutoReconnect = false
+	// 消息直接进入连接池的合并队列
+	wsConfig.QueueSize = 0
+
+	shard := &poolShard{
+		ws:     NewWebSocketService(p.client),
+		topics: make(map[string]bool),
+	}
+	shard.ws.SetConfig(&wsConfig)
+	shard.ws.OnMessage(p.dispatch)
+	shard.ws.OnError(func(err error) {
+		if p.onError != nil {
+			p.onError(err)
+		}
+	})
+	shard.ws.OnDisconnected(func() {
+		p.shardLost(shard)
+	})
+
+	if err := shard.ws.Connect(); err != nil {
+		return nil, err
+	}
+	return shard, nil
+}
+
+// dispatch 将连接上的消息合并到事件通道，统计同一主题中时间戳倒退的消息，启用DropOutOfOrder时丢弃
+func (p *WebSocketPool) dispatch(message *WebSocketMessage) {
+	if message.Ch != "" && message.Ts > 0 {
+		p.tsMutex.Lock()
+		if message.Ts < p.lastTs[message.Ch] {
+			p.tsMutex.Unlock()
+			atomic.AddUint64(&p.outOfOrder, 1)
+			if p.config.DropOutOfOrder {
+				return
+			}
+		} else {
+			p.lastTs[message.Ch] = message.Ts
+			p.tsMutex.Unlock()
+		}
+	}
+
+	if p.queue != nil {
+		p.queue.Push(message)
+		return
+	}
+	p.forward(message)
+}
+
+// forward 将消息写入事件通道
+func (p *WebSocketPool) forward(message *WebSocketMessage) {
+	p.sendMutex.RLock()
+	defer p.sendMutex.RUnlock()
+	if p.eventsClosed {
+		return
+	}
+
+	select {
+	case p.events <- message:
+	case <-p.stopChan:
+	}
+}
+
+// forgetTs 清除主题的时间戳记录
+func (p *WebSocketPool) forgetTs(topic string) {
+	p.tsMutex.Lock()
+	delete(p.lastTs, topic)
+	p.tsMutex.Unlock()
+}
+
+// shardLost 连接断开后将其主题标记为等待重新分配，并在后台重新分配到其他连接
+func (p *WebSocketPool) shardLost(lost *poolShard) {
+	p.mutex.Lock()
+	if p.closed {
+		p.mutex.Unlock()
+		return
+	}
+
+	p.removeShard(lost)
+
+	orphans := make([]string, 0, len(lost.topics))
+	for topic := range lost.topics {
+		if p.topics[topic] == lost {
+			delete(p.topics, topic)
+			p.orphans[topic] = true
+			orphans = append(orphans, topic)
+		}
+	}
+	lost.topics = make(map[string]bool)
+	p.mutex.Unlock()
+
+	if len(orphans) > 0 {
+		go p.rebalance(orphans)
+	}
+}
+
+// rebalance 重连并重新分配主题，失败时按重连间隔重试，期间取消订阅的主题不再重新订阅
+func (p *WebSocketPool) rebalance(topics []string) {
+	interval := time.Duration(p.config.WSConfig.ReconnectInterval) * time.Second
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	sort.Strings(topics)
+
+	for len(topics) > 0 {
+		select {
+		case <-p.stopChan:
+			return
+		case <-time.After(interval):
+		}
+
+		var failed []string
+		for _, topic := range topics {
+			err := p.subscribe(topic, true)
+			switch {
+			case err == nil, errors.Is(err, errTopicReleased):
+			case p.isClosed():
+				return
+			default:
+				failed = append(failed, topic)
+			}
+		}
+
+		if len(failed) > 0 && p.onError != nil {
+			p.onError(fmt.Errorf("rebalance failed for %d topics, retrying", len(failed)))
+		}
+		topics = failed
+	}
+}
+
+// isClosed 连接池是否已关闭
+func (p *WebSocketPool) isClosed() bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.closed
+}
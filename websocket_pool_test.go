@@ -0,0 +1,198 @@
+package hotcoin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestPool 创建连接测试服务端的连接池，测试结束时关闭
+func newTestPool(t *testing.T, server *testWSServer, topicsPerConnection int) *WebSocketPool {
+	config := DefaultWSPoolConfig()
+	config.WSConfig = testWSConfig(server)
+	config.TopicsPerConnection = topicsPerConnection
+	config.QueueSize = 0
+
+	pool := NewWebSocketPool(NewClient("", ""), config)
+	t.Cleanup(func() { pool.Close() })
+	return pool
+}
+
+func TestWebSocketPoolSharding(t *testing.T) {
+	server := newTestWSServer(t, func(conn *testWSConn, message map[string]interface{}) {
+		if sub, ok := message["sub"].(string); ok {
+			conn.send(map[string]interface{}{"ch": sub, "ts": 1})
+		}
+	})
+	pool := newTestPool(t, server, 2)
+
+	topics := []string{"a", "b", "c", "d", "e"}
+	if err := pool.SubscribeAll(topics); err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	if err := pool.Subscribe("a"); err == nil {
+		t.Error("expected duplicate subscribe error")
+	}
+	if got := pool.ConnectionCount(); got != 3 {
+		t.Fatalf("connections = %d, distribution %v", got, pool.Distribution())
+	}
+
+	received := make(map[string]bool)
+	timeout := time.After(2 * time.Second)
+	for len(received) < len(topics) {
+		select {
+		case message := <-pool.Events():
+			received[message.Ch] = true
+		case <-timeout:
+			t.Fatalf("timeout, received %v", received)
+		}
+	}
+
+	// 连接上的主题全部取消后关闭该连接
+	pool.Unsubscribe("e")
+	if got := pool.ConnectionCount(); got != 2 {
+		t.Errorf("empty connection not removed, distribution %v", pool.Distribution())
+	}
+	if err := pool.Subscribe("f"); err != nil || pool.ConnectionCount() != 3 {
+		t.Errorf("subscribe after removal: %v, distribution %v", err, pool.Distribution())
+	}
+}
+
+func TestWebSocketPoolRebalance(t *testing.T) {
+	server := newTestWSServer(t, nil)
+	pool := newTestPool(t, server, 2)
+
+	if err := pool.SubscribeAll([]string{"a", "b", "c"}); err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	server.connections()[0].close()
+
+	// 断开的连接上的主题重新分配到新连接，等待重新分配期间Topics仍包含这些主题
+	waitFor(t, time.Second, func() bool { return pool.ConnectionCount() == 1 })
+	if got := pool.Topics(); len(got) != 3 {
+		t.Errorf("topics = %v", got)
+	}
+	waitFor(t, 3*time.Second, func() bool {
+		distribution := pool.Distribution()
+		return len(distribution) == 2 && distribution[0]+distribution[1] == 3
+	})
+	if got := len(server.connections()); got != 3 {
+		t.Errorf("server connections = %d", got)
+	}
+}
+
+func TestWebSocketPoolUnsubscribeDuringRebalance(t *testing.T) {
+	server := newTestWSServer(t, nil)
+	pool := newTestPool(t, server, 2)
+
+	if err := pool.SubscribeAll([]string{"a", "b"}); err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	server.connections()[0].close()
+	waitFor(t, time.Second, func() bool { return pool.ConnectionCount() == 0 })
+
+	// 等待重新分配的主题仍然计入订阅，取消订阅后不再重新订阅
+	if got := pool.Topics(); len(got) != 2 {
+		t.Errorf("topics during rebalance = %v", got)
+	}
+	if err := pool.Unsubscribe("a"); err != nil {
+		t.Fatalf("unsubscribe: %v", err)
+	}
+	if err := pool.Subscribe("b"); err == nil {
+		t.Error("expected duplicate subscribe error for pending topic")
+	}
+
+	waitFor(t, 3*time.Second, func() bool { return pool.ConnectionCount() == 1 })
+	time.Sleep(100 * time.Millisecond)
+	if got := pool.Topics(); len(got) != 1 || got[0] != "b" {
+		t.Errorf("topics after rebalance = %v", got)
+	}
+}
+
+func TestWebSocketPoolCloseClosesEvents(t *testing.T) {
+	server := newTestWSServer(t, func(conn *testWSConn, message map[string]interface{}) {
+		if sub, ok := message["sub"].(string); ok {
+			conn.send(map[string]interface{}{"ch": sub, "ts": 1})
+		}
+	})
+	pool := newTestPool(t, server, 2)
+	if err := pool.Subscribe("a"); err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+
+	done := make(chan int)
+	go func() {
+		count := 0
+		for range pool.Events() {
+			count++
+		}
+		done <- count
+	}()
+	waitFor(t, time.Second, func() bool { return len(server.connections()) == 1 })
+	if err := pool.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("events channel not closed")
+	}
+	pool.dispatch(&WebSocketMessage{Ch: "a", Ts: 2})
+}
+
+func TestWebSocketPoolDialOutsideLock(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	config := DefaultWSPoolConfig()
+	config.WSConfig.URL = "ws" + strings.TrimPrefix(server.URL, "http")
+	pool := NewWebSocketPool(NewClient("", ""), config)
+	defer pool.Close()
+
+	go pool.Subscribe("a")
+	time.Sleep(50 * time.Millisecond)
+
+	done := make(chan struct{})
+	go func() {
+		pool.Topics()
+		pool.ConnectionCount()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("pool blocked while dialing")
+	}
+}
+
+func TestWebSocketPoolOutOfOrder(t *testing.T) {
+	for _, drop := range []bool{false, true} {
+		config := DefaultWSPoolConfig()
+		config.QueueSize = 0
+		config.DropOutOfOrder = drop
+		pool := NewWebSocketPool(nil, config)
+
+		pool.dispatch(&WebSocketMessage{Ch: "a", Ts: 2})
+		pool.dispatch(&WebSocketMessage{Ch: "a", Ts: 1})
+		pool.dispatch(&WebSocketMessage{Ch: "b", Ts: 1})
+
+		want := 3
+		if drop {
+			want = 2
+		}
+		if got := len(pool.Events()); got != want {
+			t.Errorf("drop=%v: delivered %d, want %d", drop, got, want)
+		}
+		if pool.OutOfOrder() != 1 {
+			t.Errorf("drop=%v: out of order = %d", drop, pool.OutOfOrder())
+		}
+		pool.Close()
+	}
+}
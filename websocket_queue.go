@@ -0,0 +1,216 @@
+package hotcoin
+
+import (
+	"runtime/debug"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// EventQueue 有界事件队列，将消息处理与读取协程解耦
+// 队列满时按照OverflowPolicy处理新消息，丢弃和合并只作用于market.*行情主题，
+// 订单、持仓、资产等私有主题和无主题消息在队列满时等待，不会被丢弃
+type EventQueue struct {
+	handler  EventHandler
+	capacity int
+	policy   OverflowPolicy
+
+	mutex  sync.Mutex
+	cond   *sync.Cond
+	items  []*queueItem
+	latest map[string]*queueItem // 合并策略下每个主题排队中的消息
+	closed bool
+	done   chan struct{}
+
+	onPanic ErrorHandler
+
+	enqueued  uint64
+	delivered uint64
+	dropped   uint64
+	coalesced uint64
+}
+
+// queueItem 队列元素
+type queueItem struct {
+	message *WebSocketMessage
+}
+
+// NewEventQueue 创建事件队列并启动消费协程
+func NewEventQueue(handler EventHandler, capacity int, policy OverflowPolicy) *EventQueue {
+	if capacity <= 0 {
+		capacity = 1
+	}
+
+	q := &EventQueue{
+		handler:  handler,
+		capacity: capacity,
+		policy:   policy,
+		items:    make([]*queueItem, 0, capacity),
+		latest:   make(map[string]*queueItem),
+		done:     make(chan struct{}),
+	}
+	q.cond = sync.NewCond(&q.mutex)
+
+	go q.run()
+	return q
+}
+
+// Push 将消息放入队列，返回消息是否被接受（合并也视为接受）
+func (q *EventQueue) Push(message *WebSocketMessage) bool {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if q.closed {
+		return false
+	}
+
+	droppable := isMarketTopic(message.Ch)
+
+	// 合并策略：同一主题只保留最新消息，保持其原有排队位置
+	if q.policy == OverflowCoalesce && droppable {
+		if item, ok := q.latest[message.Ch]; ok {
+			item.message = message
+			atomic.AddUint64(&q.coalesced, 1)
+			return true
+		}
+	}
+
+	for len(q.items) >= q.capacity {
+		switch {
+		case q.policy == OverflowDropNewest && droppable:
+			atomic.AddUint64(&q.dropped, 1)
+			return false
+		case q.policy == OverflowDropOldest || q.policy == OverflowCoalesce:
+			// 丢弃最早的行情消息，队列中只有私有消息时等待
+			if q.removeOldestMarket() {
+				atomic.AddUint64(&q.dropped, 1)
+				continue
+			}
+		}
+		q.cond.Wait()
+		if q.closed {
+			return false
+		}
+	}
+
+	item := &queueItem{message: message}
+	q.items = append(q.items, item)
+	if q.policy == OverflowCoalesce && droppable {
+		q.latest[message.Ch] = item
+	}
+	atomic.AddUint64(&q.enqueued, 1)
+	q.cond.Broadcast()
+	return true
+}
+
+// Stats 获取队列统计信息
+func (q *EventQueue) Stats() QueueStats {
+	q.mutex.Lock()
+	pending := len(q.items)
+	q.mutex.Unlock()
+
+	return QueueStats{
+		Enqueued:  atomic.LoadUint64(&q.enqueued),
+		Delivered: atomic.LoadUint64(&q.delivered),
+		Dropped:   atomic.LoadUint64(&q.dropped),
+		Coalesced: atomic.LoadUint64(&q.coalesced),
+		Pending:   pending,
+	}
+}
+
+// Close 关闭队列，已排队的消息处理完后消费协程退出
+func (q *EventQueue) Close() {
+	q.mutex.Lock()
+	if q.closed {
+		q.mutex.Unlock()
+		return
+	}
+	q.closed = true
+	q.cond.Broadcast()
+	q.mutex.Unlock()
+
+	<-q.done
+}
+
+// run 消费协程
+func (q *EventQueue) run() {
+	defer close(q.done)
+
+	for {
+		q.mutex.Lock()
+		for len(q.items) == 0 && !q.closed {
+			q.cond.Wait()
+		}
+		if len(q.items) == 0 && q.closed {
+			q.mutex.Unlock()
+			return
+		}
+		message := q.removeFront()
+		q.cond.Broadcast()
+		q.mutex.Unlock()
+
+		if q.handler != nil {
+			q.invoke(message)
+		}
+		atomic.AddUint64(&q.delivered, 1)
+	}
+}
+
+// OnPanic 设置处理器panic回调，panic的消息计为已投递，消费协程继续运行
+func (q *EventQueue) OnPanic(handler ErrorHandler) {
+	q.mutex.Lock()
+	q.onPanic = handler
+	q.mutex.Unlock()
+}
+
+// invoke 调用处理器并捕获panic
+func (q *EventQueue) invoke(message *WebSocketMessage) {
+	defer func() {
+		if r := recover(); r != nil {
+			q.mutex.Lock()
+			onPanic := q.onPanic
+			q.mutex.Unlock()
+			if onPanic != nil {
+				onPanic(&HandlerPanicError{Topic: message.Ch, Value: r, Stack: debug.Stack()})
+			}
+		}
+	}()
+
+	q.handler(message)
+}
+
+// removeFront 移除队首元素，调用方需持有mutex
+func (q *EventQueue) removeFront() *WebSocketMessage {
+	item := q.items[0]
+	q.items[0] = nil
+	q.items = q.items[1:]
+	return q.forget(item)
+}
+
+// removeOldestMarket 移除最早的行情消息，没有可丢弃的消息时返回false，调用方需持有mutex
+func (q *EventQueue) removeOldestMarket() bool {
+	for i, item := range q.items {
+		if isMarketTopic(item.message.Ch) {
+			copy(q.items[i:], q.items[i+1:])
+			q.items[len(q.items)-1] = nil
+			q.items = q.items[:len(q.items)-1]
+			q.forget(item)
+			return true
+		}
+	}
+	return false
+}
+
+// forget 清理已出队元素的合并索引，调用方需持有mutex
+func (q *EventQueue) forget(item *queueItem) *WebSocketMessage {
+	message := item.message
+	if message.Ch != "" && q.latest[message.Ch] == item {
+		delete(q.latest, message.Ch)
+	}
+	return message
+}
+
+// isMarketTopic 是否为可丢弃的公共行情主题
+func isMarketTopic(topic string) bool {
+	return strings.HasPrefix(topic, "market.")
+}
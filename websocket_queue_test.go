@@ -0,0 +1,179 @@
+package hotcoin
+
+import (
+	"errors"
+	"runtime"
+	"testing"
+)
+
+func TestEventQueueDropNewest(t *testing.T) {
+	block := make(chan struct{})
+	received := make(chan int64, 10)
+	q := NewEventQueue(func(message *WebSocketMessage) {
+		<-block
+		received <- message.Ts
+	}, 2, OverflowDropNewest)
+
+	// 第一条消息被消费协程取走并阻塞在处理器中
+	q.Push(&WebSocketMessage{Ch: "market.btcusdt.trade.detail", Ts: 1})
+	for q.Stats().Pending != 0 {
+		runtime.Gosched()
+	}
+
+	q.Push(&WebSocketMessage{Ch: "market.btcusdt.trade.detail", Ts: 2})
+	q.Push(&WebSocketMessage{Ch: "market.btcusdt.trade.detail", Ts: 3})
+	if q.Push(&WebSocketMessage{Ch: "market.btcusdt.trade.detail", Ts: 4}) {
+		t.Error("push to full queue should be rejected")
+	}
+
+	close(block)
+	q.Close()
+	close(received)
+
+	var got []int64
+	for ts := range received {
+		got = append(got, ts)
+	}
+	if len(got) != 3 || got[0] != 1 || got[1] != 2 || got[2] != 3 {
+		t.Errorf("expected [1 2 3], got %v", got)
+	}
+
+	stats := q.Stats()
+	if stats.Dropped != 1 {
+		t.Errorf("expected 1 dropped, got %d", stats.Dropped)
+	}
+	if stats.Delivered != 3 {
+		t.Errorf("expected 3 delivered, got %d", stats.Delivered)
+	}
+}
+
+func TestEventQueueDropOldest(t *testing.T) {
+	block := make(chan struct{})
+	received := make(chan int64, 10)
+	q := NewEventQueue(func(message *WebSocketMessage) {
+		<-block
+		received <- message.Ts
+	}, 2, OverflowDropOldest)
+
+	q.Push(&WebSocketMessage{Ch: "market.btcusdt.trade.detail", Ts: 1})
+	for q.Stats().Pending != 0 {
+		runtime.Gosched()
+	}
+
+	q.Push(&WebSocketMessage{Ch: "market.btcusdt.trade.detail", Ts: 2})
+	q.Push(&WebSocketMessage{Ch: "market.btcusdt.trade.detail", Ts: 3})
+	q.Push(&WebSocketMessage{Ch: "market.btcusdt.trade.detail", Ts: 4})
+
+	close(block)
+	q.Close()
+	close(received)
+
+	var got []int64
+	for ts := range received {
+		got = append(got, ts)
+	}
+	if len(got) != 3 || got[1] != 3 || got[2] != 4 {
+		t.Errorf("expected [1 3 4], got %v", got)
+	}
+}
+
+func TestEventQueueCoalesce(t *testing.T) {
+	block := make(chan struct{})
+	received := make(chan *WebSocketMessage, 10)
+	q := NewEventQueue(func(message *WebSocketMessage) {
+		<-block
+		received <- message
+	}, 10, OverflowCoalesce)
+
+	q.Push(&WebSocketMessage{Ch: "market.btcusdt.depth.step0", Ts: 1})
+	for q.Stats().Pending != 0 {
+		runtime.Gosched()
+	}
+
+	q.Push(&WebSocketMessage{Ch: "market.btcusdt.depth.step0", Ts: 2})
+	q.Push(&WebSocketMessage{Ch: "market.ethusdt.depth.step0", Ts: 3})
+	q.Push(&WebSocketMessage{Ch: "market.btcusdt.depth.step0", Ts: 4})
+
+	close(block)
+	q.Close()
+	close(received)
+
+	var got []int64
+	for message := range received {
+		got = append(got, message.Ts)
+	}
+	if len(got) != 3 || got[1] != 4 || got[2] != 3 {
+		t.Errorf("expected [1 4 3], got %v", got)
+	}
+	if q.Stats().Coalesced != 1 {
+		t.Errorf("expected 1 coalesced, got %d", q.Stats().Coalesced)
+	}
+}
+
+func TestEventQueueKeepsPrivateMessages(t *testing.T) {
+	for _, policy := range []OverflowPolicy{OverflowDropOldest, OverflowDropNewest, OverflowCoalesce} {
+		block := make(chan struct{})
+		received := make(chan string, 10)
+		q := NewEventQueue(func(message *WebSocketMessage) {
+			<-block
+			received <- message.Ch
+		}, 2, policy)
+
+		q.Push(&WebSocketMessage{Ch: "market.btcusdt.trade.detail"})
+		for q.Stats().Pending != 0 {
+			runtime.Gosched()
+		}
+		q.Push(&WebSocketMessage{Ch: "orders.btcusdt"})
+		q.Push(&WebSocketMessage{Ch: "market.btcusdt.trade.detail"})
+
+		// 行情消息让出位置，队列中只剩私有消息时等待而不是丢弃
+		pushed := make(chan bool)
+		go func() {
+			q.Push(&WebSocketMessage{Ch: "orders.btcusdt"})
+			pushed <- q.Push(&WebSocketMessage{Ch: "accounts.usdt"})
+		}()
+		close(block)
+		if !<-pushed {
+			t.Errorf("%s: private message rejected", policy)
+		}
+		q.Close()
+		close(received)
+
+		private := 0
+		for ch := range received {
+			if !isMarketTopic(ch) {
+				private++
+			}
+		}
+		if private != 3 {
+			t.Errorf("%s: delivered %d private messages, want 3", policy, private)
+		}
+	}
+}
+
+func TestEventQueueSurvivesPanic(t *testing.T) {
+	received := make(chan string, 2)
+	q := NewEventQueue(func(message *WebSocketMessage) {
+		if message.Ch == "orders.bad" {
+			panic("boom")
+		}
+		received <- message.Ch
+	}, 4, OverflowBlock)
+	panics := make(chan error, 1)
+	q.OnPanic(func(err error) { panics <- err })
+
+	q.Push(&WebSocketMessage{Ch: "orders.bad"})
+	q.Push(&WebSocketMessage{Ch: "orders.good"})
+	q.Close()
+
+	if ch := <-received; ch != "orders.good" {
+		t.Errorf("received %s after panic", ch)
+	}
+	var handlerPanic *HandlerPanicError
+	if err := <-panics; !errors.As(err, &handlerPanic) || handlerPanic.Topic != "orders.bad" {
+		t.Errorf("unexpected panic report: %v", err)
+	}
+	if stats := q.Stats(); stats.Delivered != 2 {
+		t.Errorf("stats = %+v", stats)
+	}
+}
@@ -0,0 +1,593 @@
+package hotcoin
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// recordingMagic 录制文件头
+const recordingMagic = "HCREC1\n"
+
+// recordingExt 录制文件扩展名
+const recordingExt = ".rec.gz"
+
+// maxRecordSize 单条记录名称或数据的最大长度，超出视为文件损坏
+const maxRecordSize = 64 << 20
+
+// ErrRecordingTruncated 录制文件在记录中间结束，通常是录制进程异常退出导致
+var ErrRecordingTruncated = errors.New("recording truncated")
+
+// ErrRecordingCorrupt 录制文件内容损坏，如记录类型未知或长度超出上限
+var ErrRecordingCorrupt = errors.New("recording corrupt")
+
+// ErrRecordingBufferFull 录制缓冲已满，数据帧被丢弃
+var ErrRecordingBufferFull = errors.New("recording buffer full")
+
+// Recorder 行情录制器，将WebSocket原始数据帧（含接收时间）和定时REST快照写入gzip压缩文件，
+// 文件按大小或时长轮转；压缩和文件写入在独立的写入协程中进行，不阻塞WebSocket读取协程
+//
+//	rec, _ := hotcoin.NewRecorder(hotcoin.DefaultRecorderConfig("./data"))
+//	rec.AddSnapshot(hotcoin.DepthSnapshotSource(client.Market, "btcusdt", "step0", time.Minute))
+//	rec.Start(ctx)
+//	client.WebSocket.SetRecorder(rec)
+//	defer rec.Close()
+type Recorder struct {
+	config *RecorderConfig
+
+	mutex    sync.Mutex
+	file     *os.File
+	gzip     *gzip.Writer
+	writer   *bufio.Writer
+	written  int64
+	opened   time.Time
+	sequence int
+	stats    RecorderStats
+	header   [binary.MaxVarintLen64]byte
+
+	// 待写入的记录，由写入协程按顺序写入，关闭时持有sendMutex写锁
+	items     chan recordItem
+	sendMutex sync.RWMutex
+	closed    bool
+	done      chan struct{}
+	dropped   int64
+
+	sources []SnapshotSource
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+
+	onError ErrorHandler
+}
+
+// NewRecorder 创建行情录制器，录制目录不存在时自动创建
+func NewRecorder(config *RecorderConfig) (*Recorder, error) {
+	if config == nil || config.Dir == "" {
+		return nil, fmt.Errorf("recording directory is required")
+	}
+	if config.Prefix == "" {
+		config.Prefix = "hotcoin"
+	}
+	if config.BufferSize <= 0 {
+		config.BufferSize = DefaultRecorderConfig("").BufferSize
+	}
+	if err := os.MkdirAll(config.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create recording directory: %w", err)
+	}
+
+	r := &Recorder{
+		config: config,
+		items:  make(chan recordItem, config.BufferSize),
+		done:   make(chan struct{}),
+	}
+	go r.writeLoop()
+	return r, nil
+}
+
+// recordItem 等待写入的记录
+type recordItem struct {
+	kind RecordKind
+	at   time.Time
+	name string
+	data []byte
+}
+
+// OnError 设置快照获取失败、文件写入失败等后台错误的回调
+func (r *Recorder) OnError(handler ErrorHandler) {
+	r.onError = handler
+}
+
+// AddSnapshot 添加定时REST快照，需在Start之前调用
+func (r *Recorder) AddSnapshot(source SnapshotSource) {
+	r.sources = append(r.sources, source)
+}
+
+// Start 开始定时获取REST快照
+func (r *Recorder) Start(ctx context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	r.mutex.Lock()
+	r.cancel = cancel
+	r.mutex.Unlock()
+
+	for _, source := range r.sources {
+		r.wg.Add(1)
+		go r.snapshotLoop(ctx, source)
+	}
+}
+
+// RecordFrame 记录一个原始数据帧，data会被复制，调用返回后可复用
+// 数据帧交给写入协程异步写入，缓冲已满时丢弃并返回ErrRecordingBufferFull，不阻塞调用方
+func (r *Recorder) RecordFrame(data []byte, at time.Time) error {
+	return r.enqueue(recordItem{kind: RecordKindFrame, at: at, data: append([]byte(nil), data...)}, false)
+}
+
+// RecordSnapshot 记录一个快照，v按JSON编码，缓冲已满时等待
+func (r *Recorder) RecordSnapshot(name string, v interface{}, at time.Time) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("marshal snapshot %s: %w", name, err)
+	}
+	return r.enqueue(recordItem{kind: RecordKindSnapshot, at: at, name: name, data: data}, true)
+}
+
+// Stats 获取录制统计
+func (r *Recorder) Stats() RecorderStats {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	stats := r.stats
+	stats.Dropped = atomic.LoadInt64(&r.dropped)
+	return stats
+}
+
+// Close 停止快照，等待缓冲中的记录写入后关闭当前文件
+func (r *Recorder) Close() error {
+	r.mutex.Lock()
+	cancel := r.cancel
+	r.mutex.Unlock()
+	if cancel != nil {
+		cancel()
+	}
+	r.wg.Wait()
+
+	r.sendMutex.Lock()
+	if !r.closed {
+		r.closed = true
+		close(r.items)
+	}
+	r.sendMutex.Unlock()
+	<-r.done
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.closeFileLocked()
+}
+
+// enqueue 将记录交给写入协程，block为false时缓冲已满直接丢弃
+func (r *Recorder) enqueue(item recordItem, block bool) error {
+	r.sendMutex.RLock()
+	defer r.sendMutex.RUnlock()
+
+	if r.closed {
+		return fmt.Errorf("recorder closed")
+	}
+	if block {
+		r.items <- item
+		return nil
+	}
+	select {
+	case r.items <- item:
+		return nil
+	default:
+		atomic.AddInt64(&r.dropped, 1)
+		return ErrRecordingBufferFull
+	}
+}
+
+// writeLoop 写入协程，按顺序写入记录直到缓冲关闭
+func (r *Recorder) writeLoop() {
+	defer close(r.done)
+	for item := range r.items {
+		if err := r.write(item); err != nil {
+			r.fail(err)
+		}
+	}
+}
+
+// snapshotLoop 按间隔获取并记录快照
+func (r *Recorder) snapshotLoop(ctx context.Context, source SnapshotSource) {
+	defer r.wg.Done()
+
+	ticker := time.NewTicker(source.Interval)
+	defer ticker.Stop()
+
+	for {
+		if v, err := source.Fetch(ctx); err != nil {
+			r.fail(fmt.Errorf("snapshot %s: %w", source.Name, err))
+		} else if err := r.RecordSnapshot(source.Name, v, time.Now()); err != nil {
+			r.fail(err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// write 写入一条记录，必要时轮转文件
+// 记录格式：类型(1字节) 接收时间纳秒(8字节) 名称长度(uvarint) 名称 数据长度(uvarint) 数据
+func (r *Recorder) write(item recordItem) error {
+	kind, at, name, data := item.kind, item.at, item.name, item.data
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if err := r.rotateLocked(at); err != nil {
+		r.stats.Errors++
+		return err
+	}
+
+	var fixed [9]byte
+	fixed[0] = byte(kind)
+	binary.BigEndian.PutUint64(fixed[1:], uint64(at.UnixNano()))
+	r.writer.Write(fixed[:])
+
+	n := binary.PutUvarint(r.header[:], uint64(len(name)))
+	r.writer.Write(r.header[:n])
+	r.writer.WriteString(name)
+
+	n = binary.PutUvarint(r.header[:], uint64(len(data)))
+	r.writer.Write(r.header[:n])
+	if _, err := r.writer.Write(data); err != nil {
+		r.stats.Errors++
+		return fmt.Errorf("write recording: %w", err)
+	}
+
+	r.written += int64(len(fixed) + len(name) + len(data) + 2)
+	if kind == RecordKindFrame {
+		r.stats.Frames++
+	} else {
+		r.stats.Snapshots++
+	}
+	r.stats.Bytes += int64(len(data))
+	return nil
+}
+
+// rotateLocked 按大小或时长轮转文件，调用方需持有锁
+func (r *Recorder) rotateLocked(at time.Time) error {
+	if r.file != nil {
+		bySize := r.config.MaxFileSize > 0 && r.written >= r.config.MaxFileSize
+		byTime := r.config.RotateInterval > 0 && at.Sub(r.opened) >= r.config.RotateInterval
+		if !bySize && !byTime {
+			return nil
+		}
+		if err := r.closeFileLocked(); err != nil {
+			return err
+		}
+	}
+
+	r.sequence++
+	name := fmt.Sprintf("%s-%s-%04d%s", r.config.Prefix, at.UTC().Format("20060102T150405"), r.sequence, recordingExt)
+	file, err := os.Create(filepath.Join(r.config.Dir, name))
+	if err != nil {
+		return fmt.Errorf("create recording file: %w", err)
+	}
+
+	r.file = file
+	r.gzip = gzip.NewWriter(file)
+	r.writer = bufio.NewWriterSize(r.gzip, 64*1024)
+	r.writer.WriteString(recordingMagic)
+	r.written = 0
+	r.opened = at
+	r.stats.Files++
+	return nil
+}
+
+// closeFileLocked 刷新并关闭当前文件，调用方需持有锁
+func (r *Recorder) closeFileLocked() error {
+	if r.file == nil {
+		return nil
+	}
+
+	err := r.writer.Flush()
+	if closeErr := r.gzip.Close(); err == nil {
+		err = closeErr
+	}
+	if closeErr := r.file.Close(); err == nil {
+		err = closeErr
+	}
+	r.file, r.gzip, r.writer = nil, nil, nil
+	if err != nil {
+		return fmt.Errorf("close recording file: %w", err)
+	}
+	return nil
+}
+
+// fail 触发错误回调
+func (r *Recorder) fail(err error) {
+	if r.onError != nil {
+		r.onError(err)
+	}
+}
+
+// DepthSnapshotSource 定时获取深度快照
+func DepthSnapshotSource(market *MarketService, symbol, depthType string, interval time.Duration) SnapshotSource {
+	return SnapshotSource{
+		Name:     fmt.Sprintf("depth.%s.%s", symbol, depthType),
+		Interval: interval,
+		Fetch: func(ctx context.Context) (interface{}, error) {
+			return market.getDepth(ctx, symbol, depthType)
+		},
+	}
+}
+
+// TickerSnapshotSource 定时获取24小时行情快照
+func TickerSnapshotSource(market *MarketService, symbol string, interval time.Duration) SnapshotSource {
+	return SnapshotSource{
+		Name:     fmt.Sprintf("ticker.%s", symbol),
+		Interval: interval,
+		Fetch: func(ctx context.Context) (interface{}, error) {
+			return market.getTicker(ctx, symbol)
+		},
+	}
+}
+
+// SetRecorder 设置行情录制器，收到的每个原始数据帧都会写入录制器，传入nil停止录制
+func (ws *WebSocketService) SetRecorder(recorder *Recorder) {
+	ws.recorder.Store(recorder)
+}
+
+// record 将原始数据帧写入录制器
+func (ws *WebSocketService) record(data []byte, now time.Time) {
+	recorder := ws.recorder.Load()
+	if recorder == nil {
+		return
+	}
+	if err := recorder.RecordFrame(data, now); err != nil {
+		ws.reportError(err)
+	}
+}
+
+// RecordingReader 录制文件读取器
+type RecordingReader struct {
+	file   *os.File
+	gzip   *gzip.Reader
+	reader *bufio.Reader
+}
+
+// OpenRecording 打开录制文件
+func OpenRecording(path string) (*RecordingReader, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("open recording %s: %w", path, err)
+	}
+
+	reader := bufio.NewReaderSize(gz, 64*1024)
+	magic := make([]byte, len(recordingMagic))
+	if _, err := io.ReadFull(reader, magic); err != nil || string(magic) != recordingMagic {
+		gz.Close()
+		file.Close()
+		return nil, fmt.Errorf("open recording %s: invalid header", path)
+	}
+	return &RecordingReader{file: file, gzip: gz, reader: reader}, nil
+}
+
+// Next 读取下一条记录，文件结束时返回io.EOF，记录不完整时返回ErrRecordingTruncated，
+// 内容损坏时返回ErrRecordingCorrupt
+func (rr *RecordingReader) Next() (*Record, error) {
+	var fixed [9]byte
+	if _, err := io.ReadFull(rr.reader, fixed[:]); err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, ErrRecordingTruncated
+		}
+		return nil, err
+	}
+
+	record := &Record{
+		Kind: RecordKind(fixed[0]),
+		Time: time.Unix(0, int64(binary.BigEndian.Uint64(fixed[1:]))),
+	}
+	if record.Kind != RecordKindFrame && record.Kind != RecordKindSnapshot {
+		return nil, fmt.Errorf("%w: unknown record kind %d", ErrRecordingCorrupt, fixed[0])
+	}
+	name, err := rr.readBytes()
+	if err != nil {
+		return nil, err
+	}
+	record.Name = string(name)
+	if record.Data, err = rr.readBytes(); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// Close 关闭文件
+func (rr *RecordingReader) Close() error {
+	rr.gzip.Close()
+	return rr.file.Close()
+}
+
+// readBytes 读取长度前缀的数据，长度超出maxRecordSize时不分配内存直接返回错误
+func (rr *RecordingReader) readBytes() ([]byte, error) {
+	size, err := binary.ReadUvarint(rr.reader)
+	if err != nil {
+		if err == io.EOF || errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, ErrRecordingTruncated
+		}
+		return nil, fmt.Errorf("%w: %v", ErrRecordingCorrupt, err)
+	}
+	if size > maxRecordSize {
+		return nil, fmt.Errorf("%w: record size %d exceeds limit", ErrRecordingCorrupt, size)
+	}
+	data := make([]byte, size)
+	if _, err := io.ReadFull(rr.reader, data); err != nil {
+		if err == io.EOF || errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, ErrRecordingTruncated
+		}
+		return nil, err
+	}
+	return data, nil
+}
+
+// RecordingFiles 列出目录下的录制文件，按录制顺序排序
+func RecordingFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), recordingExt) {
+			files = append(files, filepath.Join(dir, entry.Name()))
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// Replayer 行情回放器，按录制顺序将数据帧送入WebSocketService，
+// 经过与实盘相同的解码和分发流程（OnMessage、事件总线、队列）
+type Replayer struct {
+	files  []string
+	config *ReplayConfig
+
+	mutex sync.RWMutex
+	now   time.Time
+
+	onSnapshot SnapshotHandler
+}
+
+// NewReplayer 创建行情回放器，files按顺序回放
+func NewReplayer(config *ReplayConfig, files ...string) *Replayer {
+	if config == nil {
+		config = DefaultReplayConfig()
+	}
+	return &Replayer{files: files, config: config}
+}
+
+// OnSnapshot 设置快照回调，快照与数据帧按录制时间顺序交替触发
+func (rp *Replayer) OnSnapshot(handler SnapshotHandler) {
+	rp.onSnapshot = handler
+}
+
+// Now 获取回放时钟，即最近一条记录的录制时间
+func (rp *Replayer) Now() time.Time {
+	rp.mutex.RLock()
+	defer rp.mutex.RUnlock()
+	return rp.now
+}
+
+// Run 回放所有文件，ws无需连接，ctx取消时提前返回
+// 文件末尾的不完整记录（录制进程异常退出）不影响其他文件的回放，全部回放后返回包装ErrRecordingTruncated的错误
+func (rp *Replayer) Run(ctx context.Context, ws *WebSocketService) error {
+	started := time.Now()
+	var first time.Time
+	var truncated error
+
+	for _, path := range rp.files {
+		reader, err := OpenRecording(path)
+		if err != nil {
+			return err
+		}
+
+		for {
+			record, err := reader.Next()
+			if err == io.EOF {
+				break
+			}
+			if errors.Is(err, ErrRecordingTruncated) {
+				// 录制中断导致的不完整记录只可能出现在文件末尾，继续回放后续文件
+				if truncated == nil {
+					truncated = fmt.Errorf("replay %s: %w", path, err)
+				}
+				break
+			}
+			if err != nil {
+				reader.Close()
+				return err
+			}
+			if !rp.config.From.IsZero() && record.Time.Before(rp.config.From) {
+				continue
+			}
+			if !rp.config.To.IsZero() && !record.Time.Before(rp.config.To) {
+				reader.Close()
+				return truncated
+			}
+
+			if first.IsZero() {
+				first = record.Time
+			}
+			if err := rp.wait(ctx, started, first, record.Time); err != nil {
+				reader.Close()
+				return err
+			}
+			rp.mutex.Lock()
+			rp.now = record.Time
+			rp.mutex.Unlock()
+
+			switch record.Kind {
+			case RecordKindFrame:
+				ws.replayFrame(record.Data, record.Time)
+			case RecordKindSnapshot:
+				if rp.onSnapshot != nil {
+					rp.onSnapshot(RecordedSnapshot{Name: record.Name, Time: record.Time, Data: record.Data})
+				}
+			}
+		}
+		reader.Close()
+	}
+	return truncated
+}
+
+// wait 按回放速度等待到记录时间
+func (rp *Replayer) wait(ctx context.Context, started, first, at time.Time) error {
+	if rp.config.Speed <= 0 {
+		return ctx.Err()
+	}
+
+	offset := time.Duration(float64(at.Sub(first)) / rp.config.Speed)
+	delay := time.Until(started.Add(offset))
+	if delay <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// replayFrame 回放一个录制的数据帧，不响应服务端ping
+func (ws *WebSocketService) replayFrame(data []byte, at time.Time) {
+	var message WebSocketMessage
+	if err := decodeFrame(data, &message); err != nil {
+		ws.reportError(err)
+		return
+	}
+	if message.Ping > 0 {
+		return
+	}
+	ws.handleMessage(&message, at)
+}
@@ -0,0 +1,221 @@
+package hotcoin
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRecorderReplay(t *testing.T) {
+	dir := t.TempDir()
+	config := DefaultRecorderConfig(dir)
+	config.MaxFileSize = 512
+	recorder, err := NewRecorder(config)
+	if err != nil {
+		t.Fatalf("new recorder: %v", err)
+	}
+
+	base := time.Unix(1700000000, 0)
+	frames := gzipFrames(t)
+	buf := make([]byte, 0, 1024)
+	for i, frame := range frames {
+		if i == 2 {
+			depth := &DepthData{Bids: [][]string{{"100", "1"}}}
+			if err := recorder.RecordSnapshot("depth.btcusdt.step0", depth, base.Add(1500*time.Millisecond)); err != nil {
+				t.Fatalf("record snapshot: %v", err)
+			}
+		}
+		// 复用同一缓冲，录制器需复制数据
+		buf = append(buf[:0], frame...)
+		if err := recorder.RecordFrame(buf, base.Add(time.Duration(i)*time.Second)); err != nil {
+			t.Fatalf("record frame: %v", err)
+		}
+		for j := range buf {
+			buf[j] = 0
+		}
+	}
+	if err := recorder.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	files, err := RecordingFiles(dir)
+	if err != nil {
+		t.Fatalf("list files: %v", err)
+	}
+	if len(files) < 2 {
+		t.Fatalf("expected rotation, got %d files", len(files))
+	}
+	if stats := recorder.Stats(); stats.Frames != 4 || stats.Snapshots != 1 || stats.Files != int64(len(files)) {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+
+	ws := NewWebSocketService(nil)
+	var topics []string
+	ws.Bus().Subscribe("", func(message *WebSocketMessage) {
+		topics = append(topics, message.Ch)
+	})
+
+	replayer := NewReplayer(&ReplayConfig{Speed: 0}, files...)
+	var snapshots []RecordedSnapshot
+	replayer.OnSnapshot(func(snapshot RecordedSnapshot) {
+		snapshots = append(snapshots, snapshot)
+		if len(topics) != 2 {
+			t.Errorf("snapshot replayed out of order after %d frames", len(topics))
+		}
+	})
+	if err := replayer.Run(context.Background(), ws); err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+
+	// ping帧不分发
+	if len(topics) != 3 || topics[0] != "market.btcusdt.depth.step0" || topics[2] != "market.btcusdt.kline.1min" {
+		t.Errorf("unexpected topics: %v", topics)
+	}
+	var replayed DepthData
+	if len(snapshots) != 1 || snapshots[0].Decode(&replayed) != nil || replayed.Bids[0][0] != "100" {
+		t.Errorf("unexpected snapshots: %+v", snapshots)
+	}
+	if !replayer.Now().Equal(base.Add(3 * time.Second)) {
+		t.Errorf("replay clock = %s", replayer.Now())
+	}
+}
+
+// writeRecording 写入内容为body的录制文件
+func writeRecording(t *testing.T, body []byte) string {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	gz.Write([]byte(recordingMagic))
+	gz.Write(body)
+	gz.Close()
+
+	path := filepath.Join(t.TempDir(), "test"+recordingExt)
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatalf("write recording: %v", err)
+	}
+	return path
+}
+
+// rawRecord 按录制格式编码一条记录，size为写入的数据长度
+func rawRecord(kind RecordKind, size uint64, data []byte) []byte {
+	record := []byte{byte(kind), 0, 0, 0, 0, 0, 0, 0, 1, 0}
+	record = binary.AppendUvarint(record, size)
+	return append(record, data...)
+}
+
+func TestRecordingReaderErrors(t *testing.T) {
+	complete := rawRecord(RecordKindFrame, 2, []byte("{}"))
+	tests := []struct {
+		name string
+		body []byte
+		want error
+	}{
+		{"clean end", complete, io.EOF},
+		{"truncated header", bytes.Join([][]byte{complete, {byte(RecordKindFrame), 0, 0}}, nil), ErrRecordingTruncated},
+		{"truncated data", bytes.Join([][]byte{complete, rawRecord(RecordKindFrame, 10, []byte("{}"))}, nil), ErrRecordingTruncated},
+		{"oversized", bytes.Join([][]byte{complete, rawRecord(RecordKindFrame, 1<<40, nil)}, nil), ErrRecordingCorrupt},
+		{"unknown kind", bytes.Join([][]byte{complete, rawRecord(9, 2, []byte("{}"))}, nil), ErrRecordingCorrupt},
+	}
+
+	for _, tt := range tests {
+		reader, err := OpenRecording(writeRecording(t, tt.body))
+		if err != nil {
+			t.Fatalf("%s: open: %v", tt.name, err)
+		}
+		if record, err := reader.Next(); err != nil || string(record.Data) != "{}" {
+			t.Errorf("%s: first record %+v, %v", tt.name, record, err)
+		}
+		if _, err := reader.Next(); !errors.Is(err, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, err, tt.want)
+		}
+		reader.Close()
+	}
+}
+
+func TestReplayerStopsOnCorruptRecording(t *testing.T) {
+	ws := NewWebSocketService(nil)
+
+	// 文件末尾的不完整记录不影响后续文件的回放，回放结束后报告
+	var topics []string
+	ws.Bus().Subscribe("", func(message *WebSocketMessage) { topics = append(topics, message.Ch) })
+	truncated := writeRecording(t, rawRecord(RecordKindFrame, 10, nil))
+	frame := []byte(`{"ch":"market.a","ts":1}`)
+	next := writeRecording(t, rawRecord(RecordKindFrame, uint64(len(frame)), frame))
+	if err := NewReplayer(&ReplayConfig{}, truncated, next).Run(context.Background(), ws); !errors.Is(err, ErrRecordingTruncated) {
+		t.Errorf("truncated recording: expected ErrRecordingTruncated, got %v", err)
+	}
+	if len(topics) != 1 || topics[0] != "market.a" {
+		t.Errorf("frames after truncated file not replayed: %v", topics)
+	}
+
+	corrupt := writeRecording(t, rawRecord(RecordKindFrame, 1<<40, nil))
+	if err := NewReplayer(&ReplayConfig{}, corrupt).Run(context.Background(), ws); !errors.Is(err, ErrRecordingCorrupt) {
+		t.Errorf("corrupt recording: expected ErrRecordingCorrupt, got %v", err)
+	}
+}
+
+func TestRecorderFrameDoesNotBlock(t *testing.T) {
+	config := DefaultRecorderConfig(t.TempDir())
+	config.BufferSize = 2
+	recorder, err := NewRecorder(config)
+	if err != nil {
+		t.Fatalf("new recorder: %v", err)
+	}
+
+	// 写入协程阻塞时数据帧进入缓冲，缓冲满后丢弃而不阻塞调用方
+	recorder.mutex.Lock()
+	dropped := 0
+	for i := 0; i < 10; i++ {
+		if err := recorder.RecordFrame([]byte("{}"), time.Now()); errors.Is(err, ErrRecordingBufferFull) {
+			dropped++
+		}
+	}
+	recorder.mutex.Unlock()
+
+	if err := recorder.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	stats := recorder.Stats()
+	if dropped == 0 || stats.Dropped != int64(dropped) || stats.Frames+stats.Dropped != 10 {
+		t.Errorf("dropped %d, stats %+v", dropped, stats)
+	}
+	if err := recorder.RecordFrame([]byte("{}"), time.Now()); err == nil {
+		t.Error("expected error after close")
+	}
+}
+
+func TestSnapshotSourcesUseContext(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	config := DefaultConfig()
+	config.BaseURL = server.URL
+	market := NewClientWithConfig(config).Market
+
+	for _, source := range []SnapshotSource{
+		DepthSnapshotSource(market, "btcusdt", "step0", time.Minute),
+		TickerSnapshotSource(market, "btcusdt", time.Minute),
+	} {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		start := time.Now()
+		if _, err := source.Fetch(ctx); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("%s: expected deadline exceeded, got %v", source.Name, err)
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("%s ignored ctx, took %v", source.Name, elapsed)
+		}
+		cancel()
+	}
+}
@@ -0,0 +1,189 @@
+package hotcoin
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// reqSeq 请求ID序号
+var reqSeq int64
+
+// nextReqID 生成请求ID
+func nextReqID() string {
+	return fmt.Sprintf("req_%d_%d", time.Now().UnixNano(), atomic.AddInt64(&reqSeq, 1))
+}
+
+// Request 发送req请求并等待对应的rep响应
+// 通过请求ID关联响应，ctx超时或连接断开时返回错误
+func (ws *WebSocketService) Request(ctx context.Context, req *WSRequest) (*WebSocketMessage, error) {
+	if req == nil {
+		return nil, fmt.Errorf("request is required")
+	}
+	if req.Req == "" {
+		return nil, fmt.Errorf("req topic is required")
+	}
+	if !ws.IsConnected() {
+		return nil, fmt.Errorf("not connected")
+	}
+	if req.ID == "" {
+		req.ID = nextReqID()
+	}
+
+	ch := make(chan *WebSocketMessage, 1)
+	ws.pendingMutex.Lock()
+	if ws.pending == nil {
+		ws.pending = make(map[string]chan *WebSocketMessage)
+	}
+	if _, ok := ws.pending[req.ID]; ok {
+		ws.pendingMutex.Unlock()
+		return nil, fmt.Errorf("duplicate request id %s", req.ID)
+	}
+	ws.pending[req.ID] = ch
+	ws.pendingMutex.Unlock()
+
+	defer func() {
+		ws.pendingMutex.Lock()
+		delete(ws.pending, req.ID)
+		ws.pendingMutex.Unlock()
+	}()
+
+	if err := ws.sendMessage(req); err != nil {
+		return nil, err
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil, fmt.Errorf("request %s: %w", req.Req, ctx.Err())
+	case message, ok := <-ch:
+		if !ok || message == nil {
+			return nil, fmt.Errorf("request %s: connection closed", req.Req)
+		}
+		if message.Status != "" && message.Status != "ok" {
+			return nil, &ErrorResponse{
+				Code: message.ErrCode,
+				Msg:  fmt.Sprintf("request %s failed: %s", req.Req, message.ErrMsg),
+			}
+		}
+		return message, nil
+	}
+}
+
+// ReqKline 通过WebSocket请求K线数据
+// from/to: 开始/结束时间戳（秒），为0时不传
+func (ws *WebSocketService) ReqKline(ctx context.Context, symbol, period string, from, to int64) ([]WSKlineData, error) {
+	if symbol == "" {
+		return nil, fmt.Errorf("symbol is required")
+	}
+	if period == "" {
+		return nil, fmt.Errorf("period is required")
+	}
+
+	req := &WSRequest{
+		Req:  fmt.Sprintf("market.%s.kline.%s", symbol, period),
+		From: from,
+		To:   to,
+	}
+
+	message, err := ws.Request(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []WSKlineData
+	if err := decodeRepPayload(message, &result); err != nil {
+		return nil, fmt.Errorf("unmarshal kline data: %w", err)
+	}
+
+	return result, nil
+}
+
+// ReqDepth 通过WebSocket请求深度快照
+func (ws *WebSocketService) ReqDepth(ctx context.Context, symbol, depthType string) (*WSDepthData, error) {
+	if symbol == "" {
+		return nil, fmt.Errorf("symbol is required")
+	}
+	if depthType == "" {
+		depthType = "step0"
+	}
+
+	req := &WSRequest{
+		Req: fmt.Sprintf("market.%s.depth.%s", symbol, depthType),
+	}
+
+	message, err := ws.Request(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	var result WSDepthData
+	if err := decodeRepPayload(message, &result); err != nil {
+		return nil, fmt.Errorf("unmarshal depth data: %w", err)
+	}
+
+	return &result, nil
+}
+
+// ReqTrade 通过WebSocket请求最近成交
+func (ws *WebSocketService) ReqTrade(ctx context.Context, symbol string) (*WSTradeData, error) {
+	if symbol == "" {
+		return nil, fmt.Errorf("symbol is required")
+	}
+
+	req := &WSRequest{
+		Req: fmt.Sprintf("market.%s.trade.detail", symbol),
+	}
+
+	message, err := ws.Request(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	var result WSTradeData
+	if err := decodeRepPayload(message, &result); err != nil {
+		return nil, fmt.Errorf("unmarshal trade data: %w", err)
+	}
+
+	return &result, nil
+}
+
+// deliverResponse 将rep响应投递给等待中的请求，返回是否已处理
+func (ws *WebSocketService) deliverResponse(message *WebSocketMessage) bool {
+	if message.Rep == "" || message.ID == "" {
+		return false
+	}
+
+	ws.pendingMutex.Lock()
+	ch, ok := ws.pending[message.ID]
+	if ok {
+		delete(ws.pending, message.ID)
+	}
+	ws.pendingMutex.Unlock()
+
+	if !ok {
+		return false
+	}
+
+	ch <- message
+	return true
+}
+
+// failPending 连接断开时结束所有等待中的请求
+func (ws *WebSocketService) failPending() {
+	ws.pendingMutex.Lock()
+	defer ws.pendingMutex.Unlock()
+
+	for id, ch := range ws.pending {
+		close(ch)
+		delete(ws.pending, id)
+	}
+}
+
+// decodeRepPayload 解析rep响应中的数据，优先使用data字段，其次tick字段
+func decodeRepPayload(message *WebSocketMessage, v interface{}) error {
+	if !isEmptyJSON(message.Data) {
+		return message.DecodeData(v)
+	}
+	return message.DecodeTick(v)
+}
@@ -0,0 +1,176 @@
+package hotcoin
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWebSocketRequestCorrelation(t *testing.T) {
+	var mutex sync.Mutex
+	var reqs []map[string]interface{}
+	server := newTestWSServer(t, func(conn *testWSConn, message map[string]interface{}) {
+		if _, ok := message["req"]; !ok {
+			return
+		}
+		mutex.Lock()
+		reqs = append(reqs, message)
+		ready := len(reqs) == 2
+		mutex.Unlock()
+		if !ready {
+			return
+		}
+		// 倒序回复，响应按请求ID关联
+		for i := len(reqs) - 1; i >= 0; i-- {
+			conn.send(map[string]interface{}{
+				"rep":    reqs[i]["req"],
+				"id":     reqs[i]["id"],
+				"status": "ok",
+				"data":   []map[string]interface{}{{"id": i + 1, "open": reqs[i]["req"]}},
+			})
+		}
+	})
+	ws := newTestWebSocket(t, server, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	results := make([][]WSKlineData, 2)
+	errs := make([]error, 2)
+	for i, period := range []string{"1min", "5min"} {
+		wg.Add(1)
+		go func(i int, period string) {
+			defer wg.Done()
+			results[i], errs[i] = ws.ReqKline(ctx, "btcusdt", period, 1700000000, 1700003600)
+		}(i, period)
+	}
+	wg.Wait()
+
+	for i, period := range []string{"1min", "5min"} {
+		if errs[i] != nil {
+			t.Fatalf("request %s: %v", period, errs[i])
+		}
+		if len(results[i]) != 1 || results[i][0].Open != "market.btcusdt.kline."+period {
+			t.Errorf("request %s got %+v", period, results[i])
+		}
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	if reqs[0]["from"] != float64(1700000000) || reqs[0]["to"] != float64(1700003600) {
+		t.Errorf("unexpected request: %v", reqs[0])
+	}
+	if ws.pendingCount() != 0 {
+		t.Errorf("%d pending requests left", ws.pendingCount())
+	}
+}
+
+func TestWebSocketRequestTimeout(t *testing.T) {
+	server := newTestWSServer(t, nil)
+	ws := newTestWebSocket(t, server, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := ws.ReqDepth(ctx, "btcusdt", "")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+	if ws.pendingCount() != 0 {
+		t.Errorf("%d pending requests left", ws.pendingCount())
+	}
+}
+
+func TestWebSocketRequestConnectionClosed(t *testing.T) {
+	received := make(chan struct{}, 1)
+	server := newTestWSServer(t, func(conn *testWSConn, message map[string]interface{}) {
+		if _, ok := message["req"]; ok {
+			received <- struct{}{}
+		}
+	})
+	ws := newTestWebSocket(t, server, nil)
+
+	go func() {
+		<-received
+		ws.Disconnect()
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	_, err := ws.ReqTrade(ctx, "btcusdt")
+	if err == nil || !strings.Contains(err.Error(), "connection closed") {
+		t.Fatalf("expected connection closed, got %v", err)
+	}
+}
+
+func TestWebSocketRequestSnapshots(t *testing.T) {
+	server := newTestWSServer(t, func(conn *testWSConn, message map[string]interface{}) {
+		req, _ := message["req"].(string)
+		switch req {
+		case "market.btcusdt.depth.step0":
+			conn.send(map[string]interface{}{
+				"rep": req, "id": message["id"], "status": "ok",
+				"tick": map[string]interface{}{
+					"bids": [][]string{{"100", "1"}}, "asks": [][]string{{"101", "2"}}, "version": 7,
+				},
+			})
+		case "market.btcusdt.trade.detail":
+			conn.send(map[string]interface{}{
+				"rep": req, "id": message["id"], "status": "ok",
+				"data": map[string]interface{}{
+					"id": 1, "data": []map[string]interface{}{{"id": 11, "price": "100", "amount": "1", "direction": "buy"}},
+				},
+			})
+		case "market.ethusdt.depth.step0":
+			conn.send(map[string]interface{}{
+				"rep": req, "id": message["id"], "status": "error", "err-code": 2001, "err-msg": "invalid symbol",
+			})
+		}
+	})
+	ws := newTestWebSocket(t, server, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	depth, err := ws.ReqDepth(ctx, "btcusdt", "")
+	if err != nil {
+		t.Fatalf("depth: %v", err)
+	}
+	if depth.Version != 7 || len(depth.Bids) != 1 || depth.Asks[0][1] != "2" {
+		t.Errorf("unexpected depth: %+v", depth)
+	}
+
+	trades, err := ws.ReqTrade(ctx, "btcusdt")
+	if err != nil {
+		t.Fatalf("trade: %v", err)
+	}
+	if len(trades.Data) != 1 || trades.Data[0].ID != 11 || trades.Data[0].Direction != "buy" {
+		t.Errorf("unexpected trades: %+v", trades)
+	}
+
+	_, err = ws.ReqDepth(ctx, "ethusdt", "step0")
+	var apiErr *ErrorResponse
+	if !errors.As(err, &apiErr) || apiErr.Code != 2001 {
+		t.Errorf("expected API error, got %v", err)
+	}
+}
+
+func TestWebSocketRequestNotConnected(t *testing.T) {
+	ws := NewClient("", "").WebSocket
+	if _, err := ws.ReqKline(context.Background(), "btcusdt", "1min", 0, 0); err == nil {
+		t.Error("expected not connected error")
+	}
+	if _, err := ws.Request(context.Background(), &WSRequest{}); err == nil {
+		t.Error("expected missing topic error")
+	}
+}
+
+// pendingCount 等待响应的请求数
+func (ws *WebSocketService) pendingCount() int {
+	ws.pendingMutex.Lock()
+	defer ws.pendingMutex.Unlock()
+	return len(ws.pending)
+}
@@ -0,0 +1,243 @@
+package hotcoin
+
+import (
+	"expvar"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Telemetry WebSocket延迟与时钟偏差统计
+// RTT来自心跳ping/pong，消息延迟为本地接收时间减去消息时间戳（未扣除时钟偏差），
+// 时钟偏差由服务端ping时间戳和RTT估算，正值表示服务端时钟快于本地
+type Telemetry struct {
+	mutex      sync.Mutex
+	windowSize int
+
+	rtt    *sampleWindow
+	skew   *sampleWindow
+	topics map[string]*sampleWindow
+}
+
+// sampleWindow 固定大小的滑动样本窗口
+type sampleWindow struct {
+	samples []time.Duration
+	next    int
+	count   uint64
+	last    time.Duration
+}
+
+// NewTelemetry 创建延迟统计，windowSize为每项统计保留的样本数
+func NewTelemetry(windowSize int) *Telemetry {
+	if windowSize <= 0 {
+		windowSize = 1000
+	}
+	return &Telemetry{
+		windowSize: windowSize,
+		rtt:        newSampleWindow(windowSize),
+		skew:       newSampleWindow(windowSize),
+		topics:     make(map[string]*sampleWindow),
+	}
+}
+
+// Telemetry 获取WebSocket延迟统计，SetConfig后返回新的统计
+func (ws *WebSocketService) Telemetry() *Telemetry {
+	return ws.telemetry.Load()
+}
+
+// RTT 获取最近一次心跳往返时间
+func (t *Telemetry) RTT() time.Duration {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return t.rtt.last
+}
+
+// ClockSkew 获取估算的时钟偏差（服务端时间减本地时间），取样本中位数
+func (t *Telemetry) ClockSkew() time.Duration {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return t.skew.stats().P50
+}
+
+// TopicLatency 获取指定主题的消息延迟统计
+func (t *Telemetry) TopicLatency(topic string) (LatencyStats, bool) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	window, ok := t.topics[topic]
+	if !ok {
+		return LatencyStats{}, false
+	}
+	return window.stats(), true
+}
+
+// Snapshot 获取全部统计快照
+func (t *Telemetry) Snapshot() TelemetrySnapshot {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	snapshot := TelemetrySnapshot{
+		RTT:       t.rtt.stats(),
+		ClockSkew: t.skew.stats().P50,
+		Topics:    make(map[string]LatencyStats, len(t.topics)),
+	}
+	for topic, window := range t.topics {
+		snapshot.Topics[topic] = window.stats()
+	}
+	return snapshot
+}
+
+// Reset 清空所有统计
+func (t *Telemetry) Reset() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.rtt = newSampleWindow(t.windowSize)
+	t.skew = newSampleWindow(t.windowSize)
+	t.topics = make(map[string]*sampleWindow)
+}
+
+// Export 将当前统计写入指标输出
+// 指标名: hotcoin_ws_rtt_seconds, hotcoin_ws_clock_skew_seconds, hotcoin_ws_message_latency_seconds
+func (t *Telemetry) Export(sink MetricsSink) {
+	snapshot := t.Snapshot()
+
+	exportLatency(sink, "hotcoin_ws_rtt_seconds", snapshot.RTT, nil)
+	sink.SetGauge("hotcoin_ws_clock_skew_seconds", snapshot.ClockSkew.Seconds(), nil)
+	for topic, stats := range snapshot.Topics {
+		exportLatency(sink, "hotcoin_ws_message_latency_seconds", stats, map[string]string{"topic": topic})
+	}
+}
+
+// PublishExpvar 将统计快照以expvar形式发布，name不能重复
+func (t *Telemetry) PublishExpvar(name string) {
+	expvar.Publish(name, expvar.Func(func() interface{} {
+		return t.Snapshot()
+	}))
+}
+
+// StartExport 定期将统计写入指标输出，关闭stopChan后停止
+func (t *Telemetry) StartExport(sink MetricsSink, interval time.Duration, stopChan <-chan struct{}) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stopChan:
+				return
+			case <-ticker.C:
+				t.Export(sink)
+			}
+		}
+	}()
+}
+
+// recordPong 记录心跳pong响应，sent为ping发送时的本地毫秒时间戳
+func (t *Telemetry) recordPong(sent int64, now time.Time) {
+	rtt := now.Sub(time.UnixMilli(sent))
+	if rtt < 0 {
+		return
+	}
+
+	t.mutex.Lock()
+	t.rtt.add(rtt)
+	t.mutex.Unlock()
+}
+
+// recordServerTime 根据服务端ping时间戳估算时钟偏差
+func (t *Telemetry) recordServerTime(serverTs int64, now time.Time) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	// 服务端发送后经过约半个RTT到达本地
+	serverNow := time.UnixMilli(serverTs).Add(t.rtt.last / 2)
+	t.skew.add(serverNow.Sub(now))
+}
+
+// recordMessage 记录主题消息的接收延迟
+func (t *Telemetry) recordMessage(topic string, ts int64, now time.Time) {
+	if topic == "" || ts <= 0 {
+		return
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	window, ok := t.topics[topic]
+	if !ok {
+		window = newSampleWindow(t.windowSize)
+		t.topics[topic] = window
+	}
+	window.add(now.Sub(time.UnixMilli(ts)))
+}
+
+// exportLatency 输出延迟统计的各分位数
+func exportLatency(sink MetricsSink, name string, stats LatencyStats, labels map[string]string) {
+	values := map[string]time.Duration{
+		"last": stats.Last,
+		"min":  stats.Min,
+		"max":  stats.Max,
+		"mean": stats.Mean,
+		"p50":  stats.P50,
+		"p90":  stats.P90,
+		"p99":  stats.P99,
+	}
+	for stat, value := range values {
+		metricLabels := map[string]string{"stat": stat}
+		for k, v := range labels {
+			metricLabels[k] = v
+		}
+		sink.SetGauge(name, value.Seconds(), metricLabels)
+	}
+}
+
+// newSampleWindow 创建样本窗口
+func newSampleWindow(size int) *sampleWindow {
+	return &sampleWindow{samples: make([]time.Duration, 0, size)}
+}
+
+// add 添加样本，窗口满时覆盖最早的样本
+func (w *sampleWindow) add(value time.Duration) {
+	if len(w.samples) < cap(w.samples) {
+		w.samples = append(w.samples, value)
+	} else {
+		w.samples[w.next] = value
+		w.next = (w.next + 1) % len(w.samples)
+	}
+	w.count++
+	w.last = value
+}
+
+// stats 计算窗口内样本统计
+func (w *sampleWindow) stats() LatencyStats {
+	if len(w.samples) == 0 {
+		return LatencyStats{}
+	}
+
+	sorted := make([]time.Duration, len(w.samples))
+	copy(sorted, w.samples)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var sum time.Duration
+	for _, v := range sorted {
+		sum += v
+	}
+
+	return LatencyStats{
+		Count: w.count,
+		Last:  w.last,
+		Min:   sorted[0],
+		Max:   sorted[len(sorted)-1],
+		Mean:  sum / time.Duration(len(sorted)),
+		P50:   percentile(sorted, 0.50),
+		P90:   percentile(sorted, 0.90),
+		P99:   percentile(sorted, 0.99),
+	}
+}
+
+// percentile 计算有序样本的分位数
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	index := int(float64(len(sorted)-1) * p)
+	return sorted[index]
+}
@@ -0,0 +1,106 @@
+package hotcoin
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSampleWindowRing(t *testing.T) {
+	window := newSampleWindow(3)
+	if stats := window.stats(); stats != (LatencyStats{}) {
+		t.Errorf("empty window stats = %+v", stats)
+	}
+
+	for i := 1; i <= 5; i++ {
+		window.add(time.Duration(i) * time.Millisecond)
+	}
+	// 窗口满后覆盖最早的样本
+	if len(window.samples) != 3 || window.samples[0] != 4*time.Millisecond || window.samples[2] != 3*time.Millisecond {
+		t.Errorf("unexpected samples: %v", window.samples)
+	}
+
+	stats := window.stats()
+	want := LatencyStats{
+		Count: 5,
+		Last:  5 * time.Millisecond,
+		Min:   3 * time.Millisecond,
+		Max:   5 * time.Millisecond,
+		Mean:  4 * time.Millisecond,
+		P50:   4 * time.Millisecond,
+		P90:   4 * time.Millisecond,
+		P99:   4 * time.Millisecond,
+	}
+	if stats != want {
+		t.Errorf("stats = %+v, want %+v", stats, want)
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	sorted := make([]time.Duration, 100)
+	for i := range sorted {
+		sorted[i] = time.Duration(i+1) * time.Millisecond
+	}
+
+	tests := []struct {
+		p    float64
+		want time.Duration
+	}{
+		{0, time.Millisecond},
+		{0.50, 50 * time.Millisecond},
+		{0.90, 90 * time.Millisecond},
+		{0.99, 99 * time.Millisecond},
+		{1, 100 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		if got := percentile(sorted, tt.p); got != tt.want {
+			t.Errorf("percentile(%v) = %v, want %v", tt.p, got, tt.want)
+		}
+	}
+	if got := percentile([]time.Duration{time.Second}, 0.99); got != time.Second {
+		t.Errorf("single sample percentile = %v", got)
+	}
+}
+
+func TestTelemetryClockSkew(t *testing.T) {
+	telemetry := NewTelemetry(10)
+	now := time.UnixMilli(1700000000000)
+
+	telemetry.recordPong(now.Add(-100*time.Millisecond).UnixMilli(), now)
+	if telemetry.RTT() != 100*time.Millisecond {
+		t.Errorf("RTT = %v", telemetry.RTT())
+	}
+
+	// 服务端时间戳加半个RTT后比本地快1秒
+	telemetry.recordServerTime(now.Add(950*time.Millisecond).UnixMilli(), now)
+	if skew := telemetry.ClockSkew(); skew != time.Second {
+		t.Errorf("clock skew = %v", skew)
+	}
+
+	telemetry.recordMessage("market.btcusdt.trade.detail", now.Add(-20*time.Millisecond).UnixMilli(), now)
+	if stats, ok := telemetry.TopicLatency("market.btcusdt.trade.detail"); !ok || stats.Last != 20*time.Millisecond {
+		t.Errorf("topic latency = %+v, %v", stats, ok)
+	}
+}
+
+func TestWebSocketSetConfigSwapsTelemetry(t *testing.T) {
+	ws := NewWebSocketService(nil)
+	old := ws.Telemetry()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			ws.handleMessage(&WebSocketMessage{Ch: "a", Ts: 1}, time.Now())
+		}
+	}()
+	config := DefaultWSConfig()
+	config.LatencyWindow = 10
+	ws.SetConfig(config)
+	wg.Wait()
+
+	if ws.Telemetry() == old || ws.Telemetry().windowSize != 10 {
+		t.Error("SetConfig did not replace telemetry")
+	}
+}
@@ -0,0 +1,191 @@
+package hotcoin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// testWSServer 测试用WebSocket服务端，收到的每条客户端消息交给onMessage处理
+type testWSServer struct {
+	server    *httptest.Server
+	onMessage func(conn *testWSConn, message map[string]interface{})
+
+	mutex sync.Mutex
+	conns []*testWSConn
+}
+
+// testWSConn 测试服务端的一个连接
+type testWSConn struct {
+	conn  *websocket.Conn
+	mutex sync.Mutex
+}
+
+// send 发送JSON消息
+func (c *testWSConn) send(v interface{}) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.conn.WriteJSON(v)
+}
+
+// close 关闭连接
+func (c *testWSConn) close() {
+	c.conn.Close()
+}
+
+// newTestWSServer 创建测试服务端，onMessage为nil时只确认订阅
+func newTestWSServer(t *testing.T, onMessage func(conn *testWSConn, message map[string]interface{})) *testWSServer {
+	s := &testWSServer{onMessage: onMessage}
+	upgrader := websocket.Upgrader{}
+	s.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		raw, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		conn := &testWSConn{conn: raw}
+		s.mutex.Lock()
+		s.conns = append(s.conns, conn)
+		s.mutex.Unlock()
+
+		for {
+			_, data, err := raw.ReadMessage()
+			if err != nil {
+				return
+			}
+			var message map[string]interface{}
+			if err := json.Unmarshal(data, &message); err != nil {
+				continue
+			}
+			if sub, ok := message["sub"].(string); ok {
+				conn.send(map[string]interface{}{"subbed": sub, "id": message["id"], "status": "ok"})
+			}
+			if s.onMessage != nil {
+				s.onMessage(conn, message)
+			}
+		}
+	}))
+	t.Cleanup(s.server.Close)
+	return s
+}
+
+// url 获取WebSocket地址
+func (s *testWSServer) url() string {
+	return "ws" + strings.TrimPrefix(s.server.URL, "http")
+}
+
+// connections 获取已建立的连接
+func (s *testWSServer) connections() []*testWSConn {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]*testWSConn(nil), s.conns...)
+}
+
+// broadcast 向所有连接发送消息
+func (s *testWSServer) broadcast(v interface{}) {
+	for _, conn := range s.connections() {
+		conn.send(v)
+	}
+}
+
+// testWSConfig 指向测试服务端的WebSocket配置，关闭心跳
+func testWSConfig(server *testWSServer) *WSConfig {
+	config := DefaultWSConfig()
+	config.URL = server.url()
+	config.EnableHeartbeat = false
+	config.ReconnectInterval = 1
+	return config
+}
+
+// newTestWebSocket 创建已连接测试服务端的WebSocket服务，测试结束时断开
+func newTestWebSocket(t *testing.T, server *testWSServer, config *WSConfig) *WebSocketService {
+	if config == nil {
+		config = testWSConfig(server)
+	}
+	ws := NewClient("", "").WebSocket
+	ws.SetConfig(config)
+	if err := ws.Connect(); err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	t.Cleanup(func() { ws.Disconnect() })
+	return ws
+}
+
+// waitFor 等待条件成立
+func waitFor(t *testing.T, timeout time.Duration, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(timeout)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("timeout waiting for condition")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestWebSocketSlowHandlerDoesNotBlockPing(t *testing.T) {
+	pong := make(chan int64, 1)
+	server := newTestWSServer(t, func(conn *testWSConn, message map[string]interface{}) {
+		if value, ok := message["pong"].(float64); ok {
+			pong <- int64(value)
+		}
+	})
+
+	config := testWSConfig(server)
+	config.QueueSize = 2
+	config.OverflowPolicy = OverflowDropOldest
+	ws := newTestWebSocket(t, server, config)
+
+	release := make(chan struct{})
+	defer close(release)
+	ws.OnMessage(func(message *WebSocketMessage) { <-release })
+
+	waitFor(t, time.Second, func() bool { return len(server.connections()) == 1 })
+	for i := 1; i <= 10; i++ {
+		server.broadcast(map[string]interface{}{"ch": "market.btcusdt.detail", "ts": i})
+	}
+	server.broadcast(map[string]interface{}{"ping": 42})
+
+	select {
+	case value := <-pong:
+		if value != 42 {
+			t.Errorf("pong = %d", value)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("reader blocked by slow handler")
+	}
+	if stats := ws.QueueStats(); stats.Dropped == 0 {
+		t.Errorf("expected dropped messages: %+v", stats)
+	}
+}
+
+func TestWebSocketDisconnectClosesQueue(t *testing.T) {
+	server := newTestWSServer(t, nil)
+	config := testWSConfig(server)
+	config.QueueSize = 16
+	ws := newTestWebSocket(t, server, config)
+
+	queue := ws.queue.Load()
+	if queue == nil {
+		t.Fatal("queue not created")
+	}
+	if err := ws.Disconnect(); err != nil {
+		t.Fatalf("disconnect: %v", err)
+	}
+	if ws.queue.Load() != nil {
+		t.Error("queue not released on disconnect")
+	}
+	waitFor(t, time.Second, func() bool { return !queue.Push(&WebSocketMessage{}) })
+
+	if err := ws.Connect(); err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	if next := ws.queue.Load(); next == nil || next == queue {
+		t.Error("queue not recreated on connect")
+	}
+}
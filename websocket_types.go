@@ -1,21 +1,49 @@
 package hotcoin
 
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
 // WebSocketMessage WebSocket消息结构
 type WebSocketMessage struct {
-	ID       string      `json:"id"`       // 消息ID
-	Status   string      `json:"status"`   // 状态
-	Subbed   string      `json:"subbed"`   // 订阅主题
-	Unsubbed string      `json:"unsubbed"` // 取消订阅主题
-	Ping     int64       `json:"ping"`     // ping时间戳
-	Pong     int64       `json:"pong"`     // pong时间戳
-	Rep      string      `json:"rep"`      // 响应请求标识
-	Ch       string      `json:"ch"`       // 频道
-	Ts       int64       `json:"ts"`       // 时间戳
-	Tick     interface{} `json:"tick"`     // 数据
-	Data     interface{} `json:"data"`     // 数据
-	Op       string      `json:"op"`       // 操作类型
-	ErrCode  int         `json:"err-code"` // 错误码
-	ErrMsg   string      `json:"err-msg"`  // 错误信息
+	ID       string          `json:"id"`       // 消息ID
+	Status   string          `json:"status"`   // 状态
+	Subbed   string          `json:"subbed"`   // 订阅主题
+	Unsubbed string          `json:"unsubbed"` // 取消订阅主题
+	Ping     int64           `json:"ping"`     // ping时间戳
+	Pong     int64           `json:"pong"`     // pong时间戳
+	Rep      string          `json:"rep"`      // 响应请求标识
+	Ch       string          `json:"ch"`       // 频道
+	Ts       int64           `json:"ts"`       // 毫秒时间戳，每条消息都用于排序和延迟统计故保留int64，通过Time()转换
+	Tick     json.RawMessage `json:"tick"`     // 数据，按需解析
+	Data     json.RawMessage `json:"data"`     // 数据，按需解析
+	Op       string          `json:"op"`       // 操作类型
+	ErrCode  int             `json:"err-code"` // 错误码
+	ErrMsg   string          `json:"err-msg"`  // 错误信息
+}
+
+// DecodeTick 将tick字段解析到v
+func (m *WebSocketMessage) DecodeTick(v interface{}) error {
+	if isEmptyJSON(m.Tick) {
+		return fmt.Errorf("empty tick")
+	}
+	return json.Unmarshal(m.Tick, v)
+}
+
+// DecodeData 将data字段解析到v
+func (m *WebSocketMessage) DecodeData(v interface{}) error {
+	if isEmptyJSON(m.Data) {
+		return fmt.Errorf("empty data")
+	}
+	return json.Unmarshal(m.Data, v)
+}
+
+// isEmptyJSON 判断原始JSON是否为空
+func isEmptyJSON(raw json.RawMessage) bool {
+	return len(raw) == 0 || string(raw) == "null"
 }
 
 // SubscribeRequest 订阅请求
@@ -30,6 +58,14 @@ type UnsubscribeRequest struct {
 	ID    string `json:"id"`    // 请求ID
 }
 
+// WSRequest 请求-响应模式的req请求
+type WSRequest struct {
+	Req  string `json:"req"`            // 请求主题
+	ID   string `json:"id"`             // 请求ID，用于关联rep响应
+	From int64  `json:"from,omitempty"` // 开始时间戳（秒）
+	To   int64  `json:"to,omitempty"`   // 结束时间戳（秒）
+}
+
 // AuthRequest 认证请求
 type AuthRequest struct {
 	Op               string `json:"op"`   // 操作类型，固定为auth
@@ -90,28 +126,28 @@ type WSTickerData struct {
 
 // WSOrderData WebSocket订单数据
 type WSOrderData struct {
-	Symbol         string `json:"symbol"`           // 交易对
-	ContractCode   string `json:"contract_code"`    // 合约代码
-	ContractType   string `json:"contract_type"`    // 合约类型
-	Volume         string `json:"volume"`           // 委托数量
-	Price          string `json:"price"`            // 委托价格
-	OrderPriceType string `json:"order_price_type"` // 订单报价类型
-	Direction      string `json:"direction"`        // 买卖方向
-	Offset         string `json:"offset"`           // 开平方向
-	Status         int    `json:"status"`           // 订单状态
-	LeverRate      int    `json:"lever_rate"`       // 杠杆倍数
-	OrderID        int64  `json:"order_id"`         // 订单ID
-	OrderIDStr     string `json:"order_id_str"`     // 字符串格式的订单ID
-	ClientOrderID  int64  `json:"client_order_id"`  // 客户订单ID
-	OrderSource    string `json:"order_source"`     // 订单来源
-	OrderType      int    `json:"order_type"`       // 订单类型
-	CreatedAt      int64  `json:"created_at"`       // 订单创建时间
-	TradeVolume    string `json:"trade_volume"`     // 成交数量
-	TradeTurnover  string `json:"trade_turnover"`   // 成交总金额
-	Fee            string `json:"fee"`              // 手续费
-	TradeAvgPrice  string `json:"trade_avg_price"`  // 成交均价
-	MarginFrozen   string `json:"margin_frozen"`    // 冻结保证金
-	Profit         string `json:"profit"`           // 收益
+	Symbol         string         `json:"symbol"`           // 交易对
+	ContractCode   string         `json:"contract_code"`    // 合约代码
+	ContractType   string         `json:"contract_type"`    // 合约类型
+	Volume         string         `json:"volume"`           // 委托数量
+	Price          string         `json:"price"`            // 委托价格
+	OrderPriceType OrderPriceType `json:"order_price_type"` // 订单报价类型
+	Direction      OrderSide      `json:"direction"`        // 买卖方向
+	Offset         OrderOffset    `json:"offset"`           // 开平方向
+	Status         OrderStatus    `json:"status"`           // 订单状态
+	LeverRate      int            `json:"lever_rate"`       // 杠杆倍数
+	OrderID        int64          `json:"order_id"`         // 订单ID
+	OrderIDStr     string         `json:"order_id_str"`     // 字符串格式的订单ID
+	ClientOrderID  int64          `json:"client_order_id"`  // 客户订单ID
+	OrderSource    string         `json:"order_source"`     // 订单来源
+	OrderType      OrderType      `json:"order_type"`       // 订单类型
+	CreatedAt      Timestamp      `json:"created_at"`       // 订单创建时间
+	TradeVolume    string         `json:"trade_volume"`     // 成交数量
+	TradeTurnover  string         `json:"trade_turnover"`   // 成交总金额
+	Fee            string         `json:"fee"`              // 手续费
+	TradeAvgPrice  string         `json:"trade_avg_price"`  // 成交均价
+	MarginFrozen   string         `json:"margin_frozen"`    // 冻结保证金
+	Profit         string         `json:"profit"`           // 收益
 }
 
 // WSPositionData WebSocket持仓数据
@@ -159,11 +195,118 @@ type ErrorHandler func(err error)
 // ConnectionHandler 连接处理器
 type ConnectionHandler func()
 
+// OverflowPolicy 事件队列溢出策略
+type OverflowPolicy int
+
+const (
+	OverflowBlock      OverflowPolicy = 0 // 阻塞等待队列有空位
+	OverflowDropOldest OverflowPolicy = 1 // 丢弃最早的行情消息
+	OverflowDropNewest OverflowPolicy = 2 // 丢弃新到达的行情消息
+	OverflowCoalesce   OverflowPolicy = 3 // 同一行情主题只保留最新消息
+)
+
+// String 返回溢出策略名称
+func (p OverflowPolicy) String() string {
+	switch p {
+	case OverflowBlock:
+		return "block"
+	case OverflowDropOldest:
+		return "drop_oldest"
+	case OverflowDropNewest:
+		return "drop_newest"
+	case OverflowCoalesce:
+		return "coalesce"
+	default:
+		return "unknown"
+	}
+}
+
+// QueueStats 事件队列统计
+type QueueStats struct {
+	Enqueued  uint64 // 入队消息数
+	Delivered uint64 // 已处理消息数
+	Dropped   uint64 // 丢弃消息数
+	Coalesced uint64 // 被合并的消息数
+	Pending   int    // 排队中的消息数
+}
+
+// StaleAction 数据流停滞后的处理动作
+type StaleAction int
+
+const (
+	StaleActionNone        StaleAction = 0 // 仅触发回调
+	StaleActionResubscribe StaleAction = 1 // 重新订阅停滞的主题
+	StaleActionReconnect   StaleAction = 2 // 重新连接
+)
+
+// FeedStatus 订阅数据流存活状态
+type FeedStatus struct {
+	Topic        string        // 订阅主题
+	LastReceived time.Time     // 最后一次收到消息的本地时间
+	LastTs       int64         // 最后一条消息的时间戳（毫秒）
+	Messages     uint64        // 收到的消息数
+	Threshold    time.Duration // 停滞阈值
+	Stale        bool          // 是否停滞
+}
+
+// StaleHandler 数据流停滞处理器
+type StaleHandler func(status FeedStatus)
+
+// WatchdogConfig 数据流监控配置
+type WatchdogConfig struct {
+	CheckInterval    time.Duration            // 检查间隔
+	DefaultThreshold time.Duration            // 默认停滞阈值，0表示不监控
+	Thresholds       map[string]time.Duration // 按主题设置的停滞阈值
+	Action           StaleAction              // 停滞后的处理动作
+}
+
+// DefaultWatchdogConfig 默认数据流监控配置
+func DefaultWatchdogConfig() *WatchdogConfig {
+	return &WatchdogConfig{
+		CheckInterval:    time.Second,
+		DefaultThreshold: 30 * time.Second,
+		Thresholds:       make(map[string]time.Duration),
+		Action:           StaleActionNone,
+	}
+}
+
+// LatencyStats 延迟统计
+type LatencyStats struct {
+	Count uint64        // 累计样本数
+	Last  time.Duration // 最近一次
+	Min   time.Duration // 窗口内最小值
+	Max   time.Duration // 窗口内最大值
+	Mean  time.Duration // 窗口内平均值
+	P50   time.Duration // 50分位
+	P90   time.Duration // 90分位
+	P99   time.Duration // 99分位
+}
+
+// TelemetrySnapshot 延迟统计快照
+type TelemetrySnapshot struct {
+	RTT       LatencyStats            // 心跳往返时间
+	ClockSkew time.Duration           // 估算的时钟偏差（服务端时间减本地时间）
+	Topics    map[string]LatencyStats // 各主题消息延迟
+}
+
+// MetricsSink 指标输出接口，可对接Prometheus等监控系统
+type MetricsSink interface {
+	SetGauge(name string, value float64, labels map[string]string)
+}
+
 // WSConfig WebSocket配置
 type WSConfig struct {
-	URL               string // WebSocket URL
-	EnableHeartbeat   bool   // 是否启用心跳
-	HeartbeatInterval int    // 心跳间隔(秒)
+	URL                  string // WebSocket URL
+	EnableHeartbeat      bool   // 是否启用心跳
+	HeartbeatInterval    int    // 心跳间隔(秒)
+	AutoReconnect        bool   // 连接异常断开后是否自动重连
+	ReconnectInterval    int    // 重连间隔(秒)
+	MaxReconnectAttempts int    // 最大重连次数，0表示不限制
+
+	QueueSize      int            // 消息回调队列大小，0表示在读取协程中同步回调
+	OverflowPolicy OverflowPolicy // 消息回调队列溢出策略，丢弃和合并只作用于market.*行情主题，私有主题队列满时总是等待
+
+	LatencyWindow int // 延迟统计保留的样本数
 }
 
 // DefaultWSConfig 默认WebSocket配置
@@ -172,5 +315,115 @@ func DefaultWSConfig() *WSConfig {
 		URL:               "wss://api-ct.hotcoin.fit/linear-swap-ws",
 		EnableHeartbeat:   true,
 		HeartbeatInterval: 20,
+		AutoReconnect:     false,
+		ReconnectInterval: 5,
+		QueueSize:         0,
+		OverflowPolicy:    OverflowBlock,
+		LatencyWindow:     1000,
+	}
+}
+
+// WSPoolConfig WebSocket连接池配置
+type WSPoolConfig struct {
+	WSConfig            *WSConfig // 单个连接的配置
+	TopicsPerConnection int       // 每个连接的最大主题数
+	MaxConnections      int       // 最大连接数，0表示不限制
+	EventBufferSize     int       // 合并事件通道缓冲大小
+
+	QueueSize      int            // 合并事件队列大小，与读取协程解耦
+	OverflowPolicy OverflowPolicy // 合并事件队列溢出策略
+
+	DropOutOfOrder bool // 是否丢弃同一主题中时间戳倒退的消息，默认只计数
+}
+
+// DefaultWSPoolConfig 默认WebSocket连接池配置
+func DefaultWSPoolConfig() *WSPoolConfig {
+	return &WSPoolConfig{
+		WSConfig:            DefaultWSConfig(),
+		TopicsPerConnection: 50,
+		MaxConnections:      0,
+		EventBufferSize:     1024,
+		QueueSize:           4096,
+		OverflowPolicy:      OverflowBlock,
+	}
+}
+
+// RecordKind 录制记录类型
+type RecordKind byte
+
+const (
+	RecordKindFrame    RecordKind = 1 // WebSocket原始数据帧
+	RecordKindSnapshot RecordKind = 2 // REST快照
+)
+
+// Record 录制记录
+type Record struct {
+	Kind RecordKind // 记录类型
+	Time time.Time  // 接收时间
+	Name string     // 快照名称，数据帧为空
+	Data []byte     // 原始数据帧或快照JSON
+}
+
+// RecorderConfig 行情录制配置
+type RecorderConfig struct {
+	Dir            string        // 录制目录
+	Prefix         string        // 文件名前缀
+	MaxFileSize    int64         // 单个文件的最大未压缩字节数，0表示不按大小轮转
+	RotateInterval time.Duration // 文件轮转间隔，0表示不按时长轮转
+	BufferSize     int           // 等待写入的记录数上限，已满时丢弃新的数据帧
+}
+
+// DefaultRecorderConfig 默认行情录制配置
+func DefaultRecorderConfig(dir string) *RecorderConfig {
+	return &RecorderConfig{
+		Dir:            dir,
+		Prefix:         "hotcoin",
+		MaxFileSize:    256 << 20,
+		RotateInterval: time.Hour,
+		BufferSize:     4096,
 	}
 }
+
+// RecorderStats 录制统计
+type RecorderStats struct {
+	Frames    int64 // 已记录的数据帧数
+	Snapshots int64 // 已记录的快照数
+	Bytes     int64 // 已记录的数据字节数（未压缩）
+	Files     int64 // 已创建的文件数
+	Errors    int64 // 写入失败次数
+	Dropped   int64 // 缓冲已满时丢弃的数据帧数
+}
+
+// SnapshotSource 定时REST快照来源
+type SnapshotSource struct {
+	Name     string                                         // 快照名称
+	Interval time.Duration                                  // 获取间隔
+	Fetch    func(ctx context.Context) (interface{}, error) // 获取快照，结果按JSON编码
+}
+
+// RecordedSnapshot 回放的快照
+type RecordedSnapshot struct {
+	Name string          // 快照名称
+	Time time.Time       // 录制时间
+	Data json.RawMessage // 快照JSON
+}
+
+// Decode 解析快照
+func (s RecordedSnapshot) Decode(v interface{}) error {
+	return json.Unmarshal(s.Data, v)
+}
+
+// SnapshotHandler 回放快照处理函数
+type SnapshotHandler func(snapshot RecordedSnapshot)
+
+// ReplayConfig 行情回放配置
+type ReplayConfig struct {
+	Speed float64   // 回放速度倍数，1为原速，0表示不等待尽快回放
+	From  time.Time // 跳过此时间之前的记录，零值表示从头开始
+	To    time.Time // 在此时间停止，零值表示回放到结尾
+}
+
+// DefaultReplayConfig 默认行情回放配置，按原速回放
+func DefaultReplayConfig() *ReplayConfig {
+	return &ReplayConfig{Speed: 1}
+}
@@ -0,0 +1,272 @@
+package hotcoin
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// FeedWatchdog 订阅数据流存活监控
+// 按主题记录最后一次收到消息的本地时间和消息时间戳，
+// 超过阈值未收到推送时触发回调，并可按配置重新订阅或重连
+type FeedWatchdog struct {
+	ws     *WebSocketService
+	config *WatchdogConfig
+	now    func() time.Time
+
+	mutex sync.Mutex
+	feeds map[string]*feedState
+
+	onStale     StaleHandler
+	onRecovered StaleHandler
+
+	stopChan chan struct{}
+	stopOnce sync.Once
+}
+
+// feedState 单个主题的存活状态
+type feedState struct {
+	since        time.Time // 开始监控时间，未收到消息时作为基准
+	lastReceived time.Time
+	lastTs       int64
+	messages     uint64
+	stale        bool
+	actedAt      time.Time // 最近一次执行处理动作的时间
+}
+
+// NewFeedWatchdog 创建数据流监控
+func NewFeedWatchdog(ws *WebSocketService, config *WatchdogConfig) *FeedWatchdog {
+	if config == nil {
+		config = DefaultWatchdogConfig()
+	}
+	if config.CheckInterval <= 0 {
+		config.CheckInterval = DefaultWatchdogConfig().CheckInterval
+	}
+
+	return &FeedWatchdog{
+		ws:       ws,
+		config:   config,
+		now:      time.Now,
+		feeds:    make(map[string]*feedState),
+		stopChan: make(chan struct{}),
+	}
+}
+
+// EnableWatchdog 为WebSocket服务启用数据流监控，已启用的监控会被停止并替换
+func (ws *WebSocketService) EnableWatchdog(config *WatchdogConfig) *FeedWatchdog {
+	watchdog := NewFeedWatchdog(ws, config)
+	if previous := ws.watchdog.Swap(watchdog); previous != nil {
+		previous.Stop()
+	}
+	watchdog.Start()
+	return watchdog
+}
+
+// OnStale 设置数据流停滞回调
+func (w *FeedWatchdog) OnStale(handler StaleHandler) {
+	w.onStale = handler
+}
+
+// OnRecovered 设置数据流恢复回调
+func (w *FeedWatchdog) OnRecovered(handler StaleHandler) {
+	w.onRecovered = handler
+}
+
+// SetThreshold 设置指定主题的停滞阈值
+func (w *FeedWatchdog) SetThreshold(topic string, threshold time.Duration) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if w.config.Thresholds == nil {
+		w.config.Thresholds = make(map[string]time.Duration)
+	}
+	w.config.Thresholds[topic] = threshold
+}
+
+// Start 启动监控协程
+func (w *FeedWatchdog) Start() {
+	go w.run()
+}
+
+// Stop 停止监控
+func (w *FeedWatchdog) Stop() {
+	w.stopOnce.Do(func() {
+		close(w.stopChan)
+	})
+}
+
+// Status 获取指定主题的存活状态
+func (w *FeedWatchdog) Status(topic string) (FeedStatus, bool) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	feed, ok := w.feeds[topic]
+	if !ok {
+		return FeedStatus{}, false
+	}
+	return w.status(topic, feed), true
+}
+
+// Statuses 获取所有主题的存活状态
+func (w *FeedWatchdog) Statuses() []FeedStatus {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	statuses := make([]FeedStatus, 0, len(w.feeds))
+	for topic, feed := range w.feeds {
+		statuses = append(statuses, w.status(topic, feed))
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Topic < statuses[j].Topic
+	})
+	return statuses
+}
+
+// observe 记录收到的消息
+func (w *FeedWatchdog) observe(message *WebSocketMessage) {
+	if message.Ch == "" {
+		return
+	}
+
+	now := w.now()
+	w.mutex.Lock()
+	feed, ok := w.feeds[message.Ch]
+	if !ok {
+		feed = &feedState{since: now}
+		w.feeds[message.Ch] = feed
+	}
+	feed.lastReceived = now
+	if message.Ts > 0 {
+		feed.lastTs = message.Ts
+	}
+	feed.messages++
+	recovered := feed.stale
+	feed.stale = false
+	var status FeedStatus
+	if recovered {
+		status = w.status(message.Ch, feed)
+	}
+	w.mutex.Unlock()
+
+	if recovered && w.onRecovered != nil {
+		w.onRecovered(status)
+	}
+}
+
+// reset 连接建立后重置各主题的接收时间，以重连时间作为停滞判断的基准
+func (w *FeedWatchdog) reset() {
+	now := w.now()
+	w.mutex.Lock()
+	for _, feed := range w.feeds {
+		feed.since = now
+		feed.lastReceived = time.Time{}
+		feed.actedAt = now
+	}
+	w.mutex.Unlock()
+}
+
+// run 定期检查所有订阅主题
+func (w *FeedWatchdog) run() {
+	ticker := time.NewTicker(w.config.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.stopChan:
+			return
+		case <-ticker.C:
+			if w.ws.IsConnected() {
+				w.check(w.now())
+			}
+		}
+	}
+}
+
+// check 检查停滞的主题并执行处理动作
+// 主题首次停滞时触发回调，之后每经过一个阈值周期仍未恢复则再次执行处理动作
+func (w *FeedWatchdog) check(now time.Time) {
+	topics := w.ws.Topics()
+
+	var stale []FeedStatus
+	var retry []string
+	w.mutex.Lock()
+	active := make(map[string]bool, len(topics))
+	for _, topic := range topics {
+		active[topic] = true
+		feed, ok := w.feeds[topic]
+		if !ok {
+			w.feeds[topic] = &feedState{since: now}
+			continue
+		}
+		threshold := w.threshold(topic)
+		if threshold <= 0 {
+			continue
+		}
+
+		last := feed.lastReceived
+		if last.IsZero() {
+			last = feed.since
+		}
+		if !feed.stale {
+			if now.Sub(last) >= threshold {
+				feed.stale = true
+				feed.actedAt = now
+				stale = append(stale, w.status(topic, feed))
+				retry = append(retry, topic)
+			}
+		} else if now.Sub(feed.actedAt) >= threshold {
+			feed.actedAt = now
+			retry = append(retry, topic)
+		}
+	}
+	// 清理已取消订阅的主题
+	for topic := range w.feeds {
+		if !active[topic] {
+			delete(w.feeds, topic)
+		}
+	}
+	w.mutex.Unlock()
+
+	for _, status := range stale {
+		if w.onStale != nil {
+			w.onStale(status)
+		}
+	}
+
+	if len(retry) == 0 {
+		return
+	}
+
+	switch w.config.Action {
+	case StaleActionResubscribe:
+		for _, topic := range retry {
+			if err := w.ws.Resubscribe(topic); err != nil {
+				w.ws.reportError(err)
+			}
+		}
+	case StaleActionReconnect:
+		if err := w.ws.Reconnect(); err != nil {
+			w.ws.reportError(err)
+		}
+	}
+}
+
+// threshold 获取主题的停滞阈值，调用方需持有mutex
+func (w *FeedWatchdog) threshold(topic string) time.Duration {
+	if threshold, ok := w.config.Thresholds[topic]; ok {
+		return threshold
+	}
+	return w.config.DefaultThreshold
+}
+
+// status 构建主题状态，调用方需持有mutex
+func (w *FeedWatchdog) status(topic string, feed *feedState) FeedStatus {
+	return FeedStatus{
+		Topic:        topic,
+		LastReceived: feed.lastReceived,
+		LastTs:       feed.lastTs,
+		Messages:     feed.messages,
+		Threshold:    w.threshold(topic),
+		Stale:        feed.stale,
+	}
+}
@@ -0,0 +1,128 @@
+package hotcoin
+
+import (
+	"testing"
+	"time"
+)
+
+// fakeClock 测试用时钟
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) time.Time {
+	c.now = c.now.Add(d)
+	return c.now
+}
+
+// newTestWatchdog 创建使用假时钟的数据流监控，不启动监控协程
+func newTestWatchdog(topics ...string) (*FeedWatchdog, *fakeClock) {
+	ws := NewClient("", "").WebSocket
+	for _, topic := range topics {
+		ws.subscriptions[topic] = true
+	}
+
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	watchdog := NewFeedWatchdog(ws, &WatchdogConfig{
+		CheckInterval:    time.Second,
+		DefaultThreshold: 10 * time.Second,
+	})
+	watchdog.now = clock.Now
+	return watchdog, clock
+}
+
+func TestFeedWatchdogStaleAndRecovered(t *testing.T) {
+	const topic = "market.btcusdt.detail"
+	watchdog, clock := newTestWatchdog(topic)
+
+	var stale, recovered []FeedStatus
+	watchdog.OnStale(func(status FeedStatus) { stale = append(stale, status) })
+	watchdog.OnRecovered(func(status FeedStatus) { recovered = append(recovered, status) })
+
+	watchdog.check(clock.Now())
+	watchdog.observe(&WebSocketMessage{Ch: topic, Ts: 1})
+
+	watchdog.check(clock.Advance(9 * time.Second))
+	if len(stale) != 0 {
+		t.Fatalf("stale before threshold: %+v", stale)
+	}
+
+	watchdog.check(clock.Advance(time.Second))
+	if len(stale) != 1 || !stale[0].Stale || stale[0].Topic != topic || stale[0].LastTs != 1 {
+		t.Fatalf("unexpected stale: %+v", stale)
+	}
+
+	// 停滞期间不重复触发回调
+	watchdog.check(clock.Advance(15 * time.Second))
+	if len(stale) != 1 {
+		t.Errorf("stale fired %d times", len(stale))
+	}
+
+	watchdog.observe(&WebSocketMessage{Ch: topic, Ts: 2})
+	if len(recovered) != 1 || recovered[0].Stale || recovered[0].Messages != 2 {
+		t.Fatalf("unexpected recovered: %+v", recovered)
+	}
+	if status, _ := watchdog.Status(topic); status.Stale || !status.LastReceived.Equal(clock.Now()) {
+		t.Errorf("unexpected status: %+v", status)
+	}
+}
+
+func TestFeedWatchdogNeverReceived(t *testing.T) {
+	watchdog, clock := newTestWatchdog("market.btcusdt.detail", "market.ethusdt.detail")
+	watchdog.SetThreshold("market.ethusdt.detail", 0)
+
+	var stale []FeedStatus
+	watchdog.OnStale(func(status FeedStatus) { stale = append(stale, status) })
+
+	watchdog.check(clock.Now())
+	watchdog.check(clock.Advance(10 * time.Second))
+	if len(stale) != 1 || stale[0].Topic != "market.btcusdt.detail" || !stale[0].LastReceived.IsZero() {
+		t.Fatalf("unexpected stale: %+v", stale)
+	}
+}
+
+func TestFeedWatchdogReset(t *testing.T) {
+	const topic = "market.btcusdt.detail"
+	watchdog, clock := newTestWatchdog(topic)
+
+	var stale []FeedStatus
+	watchdog.OnStale(func(status FeedStatus) { stale = append(stale, status) })
+
+	watchdog.observe(&WebSocketMessage{Ch: topic})
+	clock.Advance(9 * time.Second)
+	watchdog.reset()
+
+	// 重连后以重连时间为基准
+	watchdog.check(clock.Advance(5 * time.Second))
+	if len(stale) != 0 {
+		t.Fatalf("stale right after reset: %+v", stale)
+	}
+	if status, _ := watchdog.Status(topic); !status.LastReceived.IsZero() {
+		t.Errorf("last received not reset: %+v", status)
+	}
+
+	watchdog.check(clock.Advance(5 * time.Second))
+	if len(stale) != 1 {
+		t.Fatalf("unexpected stale: %+v", stale)
+	}
+}
+
+func TestEnableWatchdogReplacesPrevious(t *testing.T) {
+	ws := NewClient("", "").WebSocket
+	first := ws.EnableWatchdog(nil)
+	second := ws.EnableWatchdog(nil)
+	defer second.Stop()
+
+	select {
+	case <-first.stopChan:
+	default:
+		t.Error("previous watchdog not stopped")
+	}
+	if ws.watchdog.Load() != second {
+		t.Error("watchdog not replaced")
+	}
+}